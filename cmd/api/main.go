@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
@@ -14,9 +16,13 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"go-api-structure/internal/auth"
 	"go-api-structure/internal/config"
 	"go-api-structure/internal/database"
+	"go-api-structure/internal/janitor"
 	"go-api-structure/internal/logger"
+	"go-api-structure/internal/mailer"
+	"go-api-structure/internal/passwordpolicy"
 	"go-api-structure/internal/server"
 	"go-api-structure/internal/store"
 )
@@ -65,6 +71,68 @@ func setupDatabase(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
 	return pool, nil
 }
 
+// setupSigningKeys loads the keys used to sign and verify JWTs.
+func setupSigningKeys(cfg *config.Config) (*auth.KeySet, error) {
+	files := make([]auth.KeyFile, 0, len(cfg.JWTSigningKeys))
+	for _, f := range cfg.JWTSigningKeys {
+		files = append(files, auth.KeyFile{ID: f.ID, Path: f.Path})
+	}
+
+	keys, err := auth.LoadKeySet(files, cfg.JWTSigningKeyID, cfg.JWTSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWT signing keys: %w", err)
+	}
+	slog.Info("JWT signing keys loaded", "count", len(files), "active_key_id", keys.ActiveKeyID())
+	return keys, nil
+}
+
+// setupPasswordPolicy builds the policy new passwords are checked against.
+func setupPasswordPolicy(cfg *config.Config) (*passwordpolicy.Standard, error) {
+	policy, err := passwordpolicy.New(passwordpolicy.Config{
+		MinEntropyBits:       cfg.PasswordMinEntropyBits,
+		BreachedHashesFile:   cfg.PasswordBreachedHashesFile,
+		RejectAccountSimilar: cfg.PasswordRejectAccountSimilar,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up password policy: %w", err)
+	}
+	slog.Info("Password policy loaded", "min_entropy_bits", cfg.PasswordMinEntropyBits,
+		"breached_list", cfg.PasswordBreachedHashesFile != "", "history_size", cfg.PasswordHistorySize)
+	return policy, nil
+}
+
+// setupTLS builds the configuration of the HTTPS listener. With a client CA bundle configured,
+// clients are asked for a certificate, and one they present must be issued by one of those CAs.
+func setupTLS(cfg *config.Config) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if cfg.TLSClientCAFile == "" {
+		slog.Info("TLS listener configured without client certificates")
+		return tlsConfig, nil
+	}
+
+	bundle, err := os.ReadFile(cfg.TLSClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TLS client CA bundle: %w", err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("no certificates found in TLS client CA bundle %s", cfg.TLSClientCAFile)
+	}
+	tlsConfig.ClientCAs = clientCAs
+	// Certificates are optional so that tokens and API keys keep working over TLS;
+	// routes that accept client certificates reject requests without one.
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	slog.Info("TLS listener configured with client certificates", "client_ca_file", cfg.TLSClientCAFile)
+	return tlsConfig, nil
+}
+
 func run(ctx context.Context, w io.Writer, args []string, getenv func(key string) string) error {
 	cfg, err := loadConfiguration(getenv)
 	if err != nil {
@@ -77,6 +145,16 @@ func run(ctx context.Context, w io.Writer, args []string, getenv func(key string
 	// Subsequent slog calls will use this configured logger.
 	setupLogger(cfg)
 
+	signingKeys, err := setupSigningKeys(cfg)
+	if err != nil {
+		return err
+	}
+
+	passwordPolicy, err := setupPasswordPolicy(cfg)
+	if err != nil {
+		return err
+	}
+
 	// Pass the main context to setupDatabase for pgxpool.New
 	db, err := setupDatabase(ctx, cfg.DatabaseDSN)
 	if err != nil {
@@ -95,7 +173,17 @@ func run(ctx context.Context, w io.Writer, args []string, getenv func(key string
 	// Get the configured logger. slog.Default() returns the logger set by setupLogger.
 	appLogger := slog.Default()
 
-	httpHandler := server.NewServer(cfg, appLogger, appStore)
+	// Start background cleanup of expired tokens; it stops when run() returns.
+	jobsCtx, cancelJobs := context.WithCancel(ctx)
+	defer cancelJobs()
+	go janitor.New(appStore, appLogger, cfg.PurgeInterval).Run(jobsCtx)
+
+	appMailer, err := mailer.New(cfg.MailerDriver, cfg.MailFrom, appLogger)
+	if err != nil {
+		return fmt.Errorf("failed to set up mailer: %w", err)
+	}
+
+	httpHandler := server.NewServer(cfg, appLogger, appStore, signingKeys, appMailer, passwordPolicy)
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.HTTPPort,
@@ -104,9 +192,27 @@ func run(ctx context.Context, w io.Writer, args []string, getenv func(key string
 		WriteTimeout: 10 * time.Second, // Example timeout
 		IdleTimeout:  15 * time.Second, // Example timeout
 	}
+	servers := []*http.Server{srv}
 
-	// Channel to listen for errors from the HTTP server goroutine
-	serverErrors := make(chan error, 1)
+	var tlsSrv *http.Server
+	if cfg.TLSPort != "" {
+		tlsConfig, err := setupTLS(cfg)
+		if err != nil {
+			return err
+		}
+		tlsSrv = &http.Server{
+			Addr:         ":" + cfg.TLSPort,
+			Handler:      httpHandler,
+			TLSConfig:    tlsConfig,
+			ReadTimeout:  srv.ReadTimeout,
+			WriteTimeout: srv.WriteTimeout,
+			IdleTimeout:  srv.IdleTimeout,
+		}
+		servers = append(servers, tlsSrv)
+	}
+
+	// Channel to listen for errors from the HTTP server goroutines
+	serverErrors := make(chan error, len(servers))
 
 	// Start HTTP server in a goroutine
 	go func() {
@@ -114,6 +220,14 @@ func run(ctx context.Context, w io.Writer, args []string, getenv func(key string
 		serverErrors <- srv.ListenAndServe()
 	}()
 
+	// The HTTPS listener serves the same API; the certificate and key are already in its TLSConfig.
+	if tlsSrv != nil {
+		go func() {
+			slog.Info("HTTPS server starting", "address", tlsSrv.Addr)
+			serverErrors <- tlsSrv.ListenAndServeTLS("", "")
+		}()
+	}
+
 	// Graceful shutdown
 	ctxShutdown, cancelShutdown := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancelShutdown()
@@ -130,9 +244,11 @@ func run(ctx context.Context, w io.Writer, args []string, getenv func(key string
 		shutdownCtx, cancelTimeout := context.WithTimeout(context.Background(), 10*time.Second) // Timeout for shutdown
 		defer cancelTimeout()
 
-		if err := srv.Shutdown(shutdownCtx); err != nil {
-			slog.Error("HTTP server graceful shutdown failed", "error", err)
-			return fmt.Errorf("http server graceful shutdown failed: %w", err)
+		for _, httpServer := range servers {
+			if err := httpServer.Shutdown(shutdownCtx); err != nil {
+				slog.Error("HTTP server graceful shutdown failed", "address", httpServer.Addr, "error", err)
+				return fmt.Errorf("http server graceful shutdown failed: %w", err)
+			}
 		}
 		slog.Info("HTTP server shutdown gracefully.")
 	}
@@ -0,0 +1,309 @@
+// Package admin lets administrators manage other users' accounts. Every change is
+// recorded in the audit trail with the administrator who made it.
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/apikey"
+	"go-api-structure/internal/audit"
+	"go-api-structure/internal/auth"
+	"go-api-structure/internal/rbac"
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+var (
+	ErrUserNotFound   = errors.New("user not found")
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	ErrSelfAction     = errors.New("administrators cannot suspend or delete their own account")
+	// ErrImpersonationNotAllowed is returned by ImpersonateUser for targets that cannot be impersonated.
+	ErrImpersonationNotAllowed = errors.New("administrators cannot impersonate themselves, suspended users or users with permissions they do not hold")
+)
+
+// ListUsersParams filters and pages a user listing.
+type ListUsersParams struct {
+	Search    string // Matched against the start of the username or email address; empty matches everyone
+	Suspended *bool  // Optional; nil lists suspended and active users alike
+	Limit     int
+	Offset    int
+}
+
+// UserDetails is a user together with their roles and API keys.
+type UserDetails struct {
+	User    db.User
+	Roles   []db.Role
+	APIKeys []db.ApiKey
+}
+
+// ServiceInterface defines the account management operations available to administrators.
+type ServiceInterface interface {
+	// ListUsers returns a page of users, newest first, and the number of users matching the filter.
+	ListUsers(ctx context.Context, params ListUsersParams) ([]db.User, int64, error)
+	GetUser(ctx context.Context, id uuid.UUID) (*UserDetails, error)
+	// SuspendUser blocks a user from logging in and using their tokens and API keys until unsuspended.
+	SuspendUser(ctx context.Context, actor audit.Actor, id uuid.UUID, reason string) (*db.User, error)
+	UnsuspendUser(ctx context.Context, actor audit.Actor, id uuid.UUID) (*db.User, error)
+	// RequirePasswordReset logs a user out everywhere and makes them choose a new password.
+	RequirePasswordReset(ctx context.Context, actor audit.Actor, id uuid.UUID) error
+	// UnlockUser lifts a lockout caused by repeated failed logins.
+	UnlockUser(ctx context.Context, actor audit.Actor, id uuid.UUID) error
+	// RegenerateAPIKey replaces one of a user's API keys and revokes the old key immediately.
+	// It returns the new key, its raw value and the revoked key.
+	RegenerateAPIKey(ctx context.Context, actor audit.Actor, userID, keyID uuid.UUID) (*db.ApiKey, string, *db.ApiKey, error)
+	DeleteUser(ctx context.Context, actor audit.Actor, id uuid.UUID) error
+	// ImpersonateUser issues a short-lived access token that lets the administrator act as the user.
+	// It returns the token and the user.
+	ImpersonateUser(ctx context.Context, actor audit.Actor, id uuid.UUID, reason string) (*auth.ImpersonationToken, *db.User, error)
+}
+
+// Service provides account management for administrators.
+type Service struct {
+	userStore     store.UserStore
+	loginAttempts store.LoginAttemptStore
+	authService   *auth.AuthService
+	apiKeyService apikey.ServiceInterface
+	rbacService   rbac.ServiceInterface
+	recorder      *audit.Recorder
+}
+
+// NewService creates a new admin Service.
+func NewService(store store.Store, authService *auth.AuthService, apiKeyService apikey.ServiceInterface, rbacService rbac.ServiceInterface, recorder *audit.Recorder) *Service {
+	return &Service{
+		userStore:     store,
+		loginAttempts: store,
+		authService:   authService,
+		apiKeyService: apiKeyService,
+		rbacService:   rbacService,
+		recorder:      recorder,
+	}
+}
+
+// likeEscaper escapes the LIKE wildcards in a search term so they match literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ListUsers returns the users matching params.
+func (s *Service) ListUsers(ctx context.Context, params ListUsersParams) ([]db.User, int64, error) {
+	var pattern string
+	if params.Search != "" {
+		pattern = likeEscaper.Replace(params.Search) + "%"
+	}
+	var suspended pgtype.Bool
+	if params.Suspended != nil {
+		suspended = pgtype.Bool{Bool: *params.Suspended, Valid: true}
+	}
+
+	users, err := s.userStore.ListUsers(ctx, db.ListUsersParams{
+		Pattern:   pattern,
+		Suspended: suspended,
+		Limit:     int32(params.Limit),
+		Offset:    int32(params.Offset),
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	total, err := s.userStore.CountUsers(ctx, db.CountUsersParams{Pattern: pattern, Suspended: suspended})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	return users, total, nil
+}
+
+// GetUser returns a user with their roles and API keys.
+func (s *Service) GetUser(ctx context.Context, id uuid.UUID) (*UserDetails, error) {
+	user, err := s.getUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.rbacService.ListUserRoles(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	apiKeys, err := s.apiKeyService.List(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return &UserDetails{User: *user, Roles: roles, APIKeys: apiKeys}, nil
+}
+
+// SuspendUser suspends a user and revokes their sessions. Suspending a suspended user
+// only updates the reason. The last administrator cannot be suspended.
+func (s *Service) SuspendUser(ctx context.Context, actor audit.Actor, id uuid.UUID, reason string) (*db.User, error) {
+	if id == actor.ID {
+		return nil, ErrSelfAction
+	}
+	if err := s.rbacService.CheckNotLastAdmin(ctx, id); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userStore.SuspendUser(ctx, db.SuspendUserParams{
+		ID:               id,
+		SuspensionReason: pgtype.Text{String: reason, Valid: reason != ""},
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to suspend user: %w", err)
+	}
+	if err := s.authService.RevokeAllSessions(ctx, id); err != nil {
+		return nil, err
+	}
+
+	s.recorder.Record(ctx, actor, audit.ActionUserSuspend, id, map[string]any{"reason": reason})
+	return &user, nil
+}
+
+// UnsuspendUser lifts a suspension. Sessions revoked by the suspension stay revoked.
+func (s *Service) UnsuspendUser(ctx context.Context, actor audit.Actor, id uuid.UUID) (*db.User, error) {
+	user, err := s.userStore.UnsuspendUser(ctx, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to unsuspend user: %w", err)
+	}
+
+	s.recorder.Record(ctx, actor, audit.ActionUserUnsuspend, id, nil)
+	return &user, nil
+}
+
+// RequirePasswordReset forces a user to reset their password before logging in again.
+func (s *Service) RequirePasswordReset(ctx context.Context, actor audit.Actor, id uuid.UUID) error {
+	user, err := s.getUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.authService.RequirePasswordReset(ctx, user); err != nil {
+		return err
+	}
+
+	s.recorder.Record(ctx, actor, audit.ActionUserRequireReset, id, nil)
+	return nil
+}
+
+// UnlockUser clears the failed login and second factor attempts counted against a user.
+func (s *Service) UnlockUser(ctx context.Context, actor audit.Actor, id uuid.UUID) error {
+	if err := s.loginAttempts.UnlockAccount(ctx, id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to unlock account: %w", err)
+	}
+
+	s.recorder.Record(ctx, actor, audit.ActionUserUnlock, id, nil)
+	return nil
+}
+
+// RegenerateAPIKey rotates one of a user's API keys without the usual grace period,
+// for keys that may have leaked. The new raw key has to be handed to the user out of band.
+func (s *Service) RegenerateAPIKey(ctx context.Context, actor audit.Actor, userID, keyID uuid.UUID) (*db.ApiKey, string, *db.ApiKey, error) {
+	if _, err := s.getUser(ctx, userID); err != nil {
+		return nil, "", nil, err
+	}
+
+	newKey, rawKey, _, err := s.apiKeyService.Rotate(ctx, userID, keyID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, "", nil, ErrAPIKeyNotFound
+		}
+		return nil, "", nil, err
+	}
+	oldKey, err := s.apiKeyService.Revoke(ctx, userID, keyID)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to revoke regenerated API key: %w", err)
+	}
+
+	s.recorder.Record(ctx, actor, audit.ActionAPIKeyRegenerate, userID, map[string]any{
+		"old_key_id": oldKey.ID,
+		"new_key_id": newKey.ID,
+	})
+	return newKey, rawKey, oldKey, nil
+}
+
+// DeleteUser permanently deletes a user along with their tokens, API keys and role assignments.
+// The last administrator cannot be deleted.
+func (s *Service) DeleteUser(ctx context.Context, actor audit.Actor, id uuid.UUID) error {
+	if id == actor.ID {
+		return ErrSelfAction
+	}
+	user, err := s.getUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.rbacService.CheckNotLastAdmin(ctx, id); err != nil {
+		return err
+	}
+
+	deleted, err := s.userStore.DeleteUser(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if deleted == 0 {
+		return ErrUserNotFound
+	}
+
+	// The account is gone, so keep enough in the trail to tell who it was.
+	s.recorder.Record(ctx, actor, audit.ActionUserDelete, id, map[string]any{
+		"username": user.Username,
+		"email":    user.Email,
+	})
+	return nil
+}
+
+// ImpersonateUser lets an administrator act as a user to see what they see. So that impersonation
+// cannot be used to gain permissions, the user must not hold any the administrator lacks.
+// Every request made with the token is recorded as well (see auth.JWTMiddleware).
+func (s *Service) ImpersonateUser(ctx context.Context, actor audit.Actor, id uuid.UUID, reason string) (*auth.ImpersonationToken, *db.User, error) {
+	if id == actor.ID {
+		return nil, nil, ErrImpersonationNotAllowed
+	}
+	user, err := s.getUser(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.SuspendedAt.Valid {
+		return nil, nil, ErrImpersonationNotAllowed
+	}
+	permissions, err := s.rbacService.UserPermissions(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	allowed, err := s.rbacService.HasPermissions(ctx, actor.ID, permissions...)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !allowed {
+		return nil, nil, ErrImpersonationNotAllowed
+	}
+
+	token, err := s.authService.IssueImpersonationToken(ctx, actor.ID, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.recorder.Record(ctx, actor, audit.ActionUserImpersonate, id, map[string]any{
+		"reason":     reason,
+		"jti":        token.Claims.ID,
+		"expires_at": token.Claims.ExpiresAt.Time,
+	})
+	return token, user, nil
+}
+
+// getUser returns a user, or ErrUserNotFound.
+func (s *Service) getUser(ctx context.Context, id uuid.UUID) (*db.User, error) {
+	user, err := s.userStore.GetUserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
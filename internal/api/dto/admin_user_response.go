@@ -0,0 +1,72 @@
+package dto
+
+import (
+	"time"
+
+	"go-api-structure/internal/store/db"
+)
+
+// AdminUserResponse defines the structure for user data returned to administrators.
+// It adds the account status to UserResponse.
+type AdminUserResponse struct {
+	UserResponse
+	SuspendedAt           *time.Time `json:"suspended_at"`
+	SuspensionReason      string     `json:"suspension_reason,omitempty"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+}
+
+// NewAdminUserResponse creates a new AdminUserResponse DTO from a db.User model.
+func NewAdminUserResponse(user *db.User) *AdminUserResponse {
+	if user == nil {
+		return nil
+	}
+	resp := &AdminUserResponse{
+		UserResponse:          *NewUserResponse(user),
+		SuspensionReason:      user.SuspensionReason.String,
+		PasswordResetRequired: user.PasswordResetRequiredAt.Valid,
+	}
+	if user.SuspendedAt.Valid {
+		resp.SuspendedAt = &user.SuspendedAt.Time
+	}
+	return resp
+}
+
+// AdminUserListResponse defines the structure for a page of users returned to administrators.
+// Total is the number of users matching the filter across all pages.
+type AdminUserListResponse struct {
+	Users  []*AdminUserResponse `json:"users"`
+	Total  int64                `json:"total"`
+	Limit  int                  `json:"limit"`
+	Offset int                  `json:"offset"`
+}
+
+// NewAdminUserListResponse converts a page of db.User models into a response DTO.
+func NewAdminUserListResponse(users []db.User, total int64, limit, offset int) *AdminUserListResponse {
+	resp := &AdminUserListResponse{
+		Users:  make([]*AdminUserResponse, 0, len(users)),
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+	for i := range users {
+		resp.Users = append(resp.Users, NewAdminUserResponse(&users[i]))
+	}
+	return resp
+}
+
+// AdminUserDetailsResponse defines the structure for a single user returned to administrators,
+// with their roles and the metadata of their API keys.
+type AdminUserDetailsResponse struct {
+	AdminUserResponse
+	Roles   []*RoleResponse   `json:"roles"`
+	APIKeys []*APIKeyResponse `json:"api_keys"`
+}
+
+// NewAdminUserDetailsResponse creates a new AdminUserDetailsResponse DTO.
+func NewAdminUserDetailsResponse(user *db.User, roles []db.Role, apiKeys []db.ApiKey) *AdminUserDetailsResponse {
+	return &AdminUserDetailsResponse{
+		AdminUserResponse: *NewAdminUserResponse(user),
+		Roles:             NewUserRoleListResponse(roles),
+		APIKeys:           NewAPIKeyListResponse(apiKeys),
+	}
+}
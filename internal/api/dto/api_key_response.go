@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"go-api-structure/internal/apikey"
+	"go-api-structure/internal/store/db"
+)
+
+// APIKeyResponse defines the structure for API key metadata returned by the API.
+// The key itself is never included; it is only shown once, on creation.
+// Prefix holds the public start of the key (gas_live_<key id>) so users can tell their keys apart.
+type APIKeyResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix,omitempty"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	Revoked    bool       `json:"revoked"`
+	ReplacedBy *uuid.UUID `json:"replaced_by,omitempty"` // Set once the key has been rotated
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// NewAPIKeyResponse creates a new APIKeyResponse DTO from a db.ApiKey model.
+func NewAPIKeyResponse(apiKey *db.ApiKey) *APIKeyResponse {
+	if apiKey == nil {
+		return nil
+	}
+	resp := &APIKeyResponse{
+		ID:        apiKey.ID,
+		Name:      apiKey.Name,
+		Scopes:    apiKey.Scopes,
+		Revoked:   apiKey.Revoked,
+		CreatedAt: apiKey.CreatedAt.Time,
+		UpdatedAt: apiKey.UpdatedAt.Time,
+	}
+	if apiKey.KeyID.Valid {
+		resp.Prefix = apikey.KeyPrefix + apiKey.KeyID.String
+	}
+	if apiKey.ExpiresAt.Valid {
+		resp.ExpiresAt = &apiKey.ExpiresAt.Time
+	}
+	if apiKey.LastUsedAt.Valid {
+		resp.LastUsedAt = &apiKey.LastUsedAt.Time
+	}
+	if apiKey.ReplacedBy.Valid {
+		replacedBy := uuid.UUID(apiKey.ReplacedBy.Bytes)
+		resp.ReplacedBy = &replacedBy
+	}
+	return resp
+}
+
+// NewAPIKeyListResponse converts a list of db.ApiKey models into response DTOs.
+func NewAPIKeyListResponse(apiKeys []db.ApiKey) []*APIKeyResponse {
+	resp := make([]*APIKeyResponse, 0, len(apiKeys))
+	for i := range apiKeys {
+		resp = append(resp, NewAPIKeyResponse(&apiKeys[i]))
+	}
+	return resp
+}
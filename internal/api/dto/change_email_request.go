@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// ChangeEmailRequest defines the structure for changing the current user's email address.
+type ChangeEmailRequest struct {
+	NewEmail        string `json:"new_email" validate:"required,email,max=255"`
+	CurrentPassword string `json:"current_password" validate:"required"`
+}
+
+// Valid checks if the ChangeEmailRequest fields are valid.
+func (r *ChangeEmailRequest) Valid() map[string]string {
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		switch err.Field() {
+		case "NewEmail":
+			switch err.Tag() {
+			case "required":
+				errors["new_email"] = "new_email must be provided"
+			case "max":
+				errors["new_email"] = "new_email must not be more than 255 characters long"
+			default:
+				errors["new_email"] = "new_email must be a valid email address"
+			}
+		case "CurrentPassword":
+			errors["current_password"] = "current_password must be provided"
+		}
+	}
+
+	return errors
+}
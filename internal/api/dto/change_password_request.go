@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// ChangePasswordRequest defines the structure for changing the current user's password.
+// The new password rules match CreateUserRequest.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,trimLenMin=8,trimLenMax=1024,min=8,max=1024"`
+}
+
+// Valid checks if the ChangePasswordRequest fields are valid.
+func (r *ChangePasswordRequest) Valid() map[string]string {
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		switch err.Field() {
+		case "CurrentPassword":
+			errors["current_password"] = "current_password must be provided"
+		case "NewPassword":
+			switch err.Tag() {
+			case "required":
+				errors["new_password"] = "new_password must be provided"
+			case "trimLenMin":
+				errors["new_password"] = "new_password must be at least 8 characters long"
+			case "trimLenMax":
+				errors["new_password"] = "new_password must not be more than 1024 characters long"
+			}
+		}
+	}
+
+	return errors
+}
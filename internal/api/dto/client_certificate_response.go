@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"go-api-structure/internal/store/db"
+)
+
+// ClientCertificateResponse defines the structure for client certificate registrations returned by the API.
+// Exactly one of UserID and ServiceAccountID is set, and exactly one of SPKISHA256 and Subject.
+type ClientCertificateResponse struct {
+	ID               uuid.UUID  `json:"id"`
+	Name             string     `json:"name"`
+	UserID           *uuid.UUID `json:"user_id,omitempty"`
+	ServiceAccountID *uuid.UUID `json:"service_account_id,omitempty"`
+	SPKISHA256       string     `json:"spki_sha256,omitempty"`
+	Subject          string     `json:"subject,omitempty"`
+	Scopes           []string   `json:"scopes"`
+	LastUsedAt       *time.Time `json:"last_used_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// NewClientCertificateResponse creates a new ClientCertificateResponse DTO from a db.ClientCertificate model.
+func NewClientCertificateResponse(certificate *db.ClientCertificate) *ClientCertificateResponse {
+	if certificate == nil {
+		return nil
+	}
+	resp := &ClientCertificateResponse{
+		ID:         certificate.ID,
+		Name:       certificate.Name,
+		SPKISHA256: certificate.SpkiSha256.String,
+		Subject:    certificate.Subject.String,
+		Scopes:     certificate.Scopes,
+		CreatedAt:  certificate.CreatedAt.Time,
+	}
+	if certificate.UserID.Valid {
+		userID := uuid.UUID(certificate.UserID.Bytes)
+		resp.UserID = &userID
+	}
+	if certificate.ServiceAccountID.Valid {
+		accountID := uuid.UUID(certificate.ServiceAccountID.Bytes)
+		resp.ServiceAccountID = &accountID
+	}
+	if certificate.LastUsedAt.Valid {
+		resp.LastUsedAt = &certificate.LastUsedAt.Time
+	}
+	return resp
+}
+
+// NewClientCertificateListResponse converts a list of db.ClientCertificate models into response DTOs.
+func NewClientCertificateListResponse(certificates []db.ClientCertificate) []*ClientCertificateResponse {
+	resp := make([]*ClientCertificateResponse, 0, len(certificates))
+	for i := range certificates {
+		resp = append(resp, NewClientCertificateResponse(&certificates[i]))
+	}
+	return resp
+}
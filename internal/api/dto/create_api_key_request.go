@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// CreateAPIKeyRequest defines the expected structure for creating a new API key.
+// Scopes must be known scopes; ExpiresAt is optional and must lie in the future.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,trimLenMin=1,trimLenMax=100,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,scope"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" validate:"omitempty,gt"`
+}
+
+// Valid checks the validity of the CreateAPIKeyRequest fields.
+// It returns a map of validation errors if any are found, otherwise nil.
+func (r *CreateAPIKeyRequest) Valid() map[string]string {
+	r.Name = strings.TrimSpace(r.Name)
+
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		switch err.Field() {
+		case "Name":
+			switch err.Tag() {
+			case "required", "trimLenMin":
+				errors["name"] = "name must be provided"
+			default:
+				errors["name"] = "name must not be more than 100 characters long"
+			}
+		case "Scopes":
+			errors["scopes"] = "at least one scope must be provided"
+		case "ExpiresAt":
+			errors["expires_at"] = "expires_at must be in the future"
+		default:
+			// Errors from "dive" are reported per element, e.g. Scopes[0].
+			if strings.HasPrefix(err.Field(), "Scopes[") {
+				errors["scopes"] = "scopes contains an unknown scope: " + err.Value().(string)
+			}
+		}
+	}
+
+	return errors
+}
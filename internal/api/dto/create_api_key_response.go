@@ -0,0 +1,9 @@
+package dto
+
+// CreateAPIKeyResponse defines the structure returned when a new API key is created.
+// Key holds the raw API key (gas_live_<key id>_<secret>). Only its SHA-256 digest is stored,
+// so it is returned here once and can never be retrieved again.
+type CreateAPIKeyResponse struct {
+	Key    string          `json:"key"`
+	APIKey *APIKeyResponse `json:"api_key"`
+}
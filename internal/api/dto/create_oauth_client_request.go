@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// CreateOAuthClientRequest defines the expected structure for registering an OAuth client.
+// Scopes are the most the client can request a token for and must be known API key scopes.
+type CreateOAuthClientRequest struct {
+	Name   string   `json:"name" validate:"required,trimLenMin=1,trimLenMax=100,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,scope"`
+}
+
+// Valid checks the validity of the CreateOAuthClientRequest fields.
+// It returns a map of validation errors if any are found, otherwise nil.
+func (r *CreateOAuthClientRequest) Valid() map[string]string {
+	r.Name = strings.TrimSpace(r.Name)
+
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		switch err.Field() {
+		case "Name":
+			switch err.Tag() {
+			case "required", "trimLenMin":
+				errors["name"] = "name must be provided"
+			default:
+				errors["name"] = "name must not be more than 100 characters long"
+			}
+		case "Scopes":
+			errors["scopes"] = "at least one scope must be provided"
+		default:
+			// Errors from "dive" are reported per element, e.g. Scopes[0].
+			if strings.HasPrefix(err.Field(), "Scopes[") {
+				errors["scopes"] = "scopes contains an unknown scope: " + err.Value().(string)
+			}
+		}
+	}
+
+	return errors
+}
@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// CreateRoleRequest defines the expected structure for creating a new role.
+// Permissions must be known permissions; a role may be created without any.
+type CreateRoleRequest struct {
+	Name        string   `json:"name" validate:"required,trimLenMin=1,trimLenMax=50,max=50"`
+	Description string   `json:"description" validate:"max=500"`
+	Permissions []string `json:"permissions" validate:"dive,permission"`
+}
+
+// Valid checks the validity of the CreateRoleRequest fields.
+// It returns a map of validation errors if any are found, otherwise nil.
+func (r *CreateRoleRequest) Valid() map[string]string {
+	r.Name = strings.ToLower(strings.TrimSpace(r.Name))
+	r.Description = strings.TrimSpace(r.Description)
+
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		switch err.Field() {
+		case "Name":
+			switch err.Tag() {
+			case "required", "trimLenMin":
+				errors["name"] = "name must be provided"
+			default:
+				errors["name"] = "name must not be more than 50 characters long"
+			}
+		case "Description":
+			errors["description"] = "description must not be more than 500 characters long"
+		default:
+			// Errors from "dive" are reported per element, e.g. Permissions[0].
+			if strings.HasPrefix(err.Field(), "Permissions[") {
+				errors["permissions"] = "permissions contains an unknown permission: " + err.Value().(string)
+			}
+		}
+	}
+
+	return errors
+}
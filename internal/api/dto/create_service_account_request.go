@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// CreateServiceAccountRequest defines the expected structure for creating a service account.
+type CreateServiceAccountRequest struct {
+	Name        string `json:"name" validate:"required,trimLenMin=1,trimLenMax=100,max=100"`
+	Description string `json:"description" validate:"max=500"`
+}
+
+// Valid checks the validity of the CreateServiceAccountRequest fields.
+// It returns a map of validation errors if any are found, otherwise nil.
+func (r *CreateServiceAccountRequest) Valid() map[string]string {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Description = strings.TrimSpace(r.Description)
+
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		switch err.Field() {
+		case "Name":
+			switch err.Tag() {
+			case "required", "trimLenMin":
+				errors["name"] = "name must be provided"
+			default:
+				errors["name"] = "name must not be more than 100 characters long"
+			}
+		case "Description":
+			errors["description"] = "description must not be more than 500 characters long"
+		}
+	}
+
+	return errors
+}
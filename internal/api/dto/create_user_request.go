@@ -9,7 +9,7 @@ import (
 type CreateUserRequest struct {
 	Username string `json:"username" validate:"required,trimLenMin=3,trimLenMax=50,min=3,max=50"`
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,trimLenMin=8,trimLenMax=72,min=8,max=72"`
+	Password string `json:"password" validate:"required,trimLenMin=8,trimLenMax=1024,min=8,max=1024"`
 }
 
 // Valid checks the validity of the CreateUserRequest fields.
@@ -45,7 +45,7 @@ func (r *CreateUserRequest) Valid() map[string]string {
 			case "trimLenMin":
 				errors["password"] = "password must be at least 8 characters long"
 			case "trimLenMax":
-				errors["password"] = "password must not be more than 72 characters long"
+				errors["password"] = "password must not be more than 1024 characters long"
 			}
 		}
 	}
@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"time"
+
+	"go-api-structure/internal/store/db"
+)
+
+// EmailChangeResponse describes a pending email change. The current address stays
+// in use until the pending one is confirmed.
+type EmailChangeResponse struct {
+	PendingEmail string    `json:"pending_email"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// NewEmailChangeResponse creates an EmailChangeResponse from a db.EmailChangeRequest.
+func NewEmailChangeResponse(request *db.EmailChangeRequest) *EmailChangeResponse {
+	return &EmailChangeResponse{
+		PendingEmail: request.NewEmail,
+		ExpiresAt:    request.ExpiresAt.Time,
+	}
+}
@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// EmailChangeTokenRequest defines the structure for confirming or cancelling an email change.
+// Token is taken from the link in the confirmation or notification email.
+type EmailChangeTokenRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Valid checks if the EmailChangeTokenRequest fields are valid.
+func (r *EmailChangeTokenRequest) Valid() map[string]string {
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		if err.Field() == "Token" {
+			errors["token"] = "token must be provided"
+		}
+	}
+
+	return errors
+}
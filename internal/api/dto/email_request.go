@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// EmailRequest defines the structure for requests that only carry an email address,
+// such as resending a verification email.
+type EmailRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// Valid checks if the EmailRequest fields are valid.
+func (r *EmailRequest) Valid() map[string]string {
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		if err.Field() == "Email" {
+			if err.Tag() == "required" {
+				errors["email"] = "email must be provided"
+			} else {
+				errors["email"] = "email must be a valid email address"
+			}
+		}
+	}
+
+	return errors
+}
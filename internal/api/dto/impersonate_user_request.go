@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ImpersonateUserRequest defines the structure for impersonating a user. The reason is kept in
+// the audit trail; surrounding whitespace is removed.
+type ImpersonateUserRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// Valid checks the validity of the ImpersonateUserRequest fields.
+// It returns a map of validation errors if any are found, otherwise nil.
+func (r *ImpersonateUserRequest) Valid() map[string]string {
+	r.Reason = strings.TrimSpace(r.Reason)
+
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		if err.Field() == "Reason" {
+			switch err.Tag() {
+			case "required":
+				errors["reason"] = "reason is required"
+			case "max":
+				errors["reason"] = "reason must not be more than 500 characters long"
+			}
+		}
+	}
+
+	return errors
+}
@@ -0,0 +1,11 @@
+package dto
+
+import "time"
+
+// ImpersonationResponse defines the structure for a successful impersonation. Token is an access
+// token for the impersonated user; it cannot be refreshed and stops working at ExpiresAt.
+type ImpersonationResponse struct {
+	Token     string             `json:"token"`
+	ExpiresAt time.Time          `json:"expires_at"`
+	User      *AdminUserResponse `json:"user"`
+}
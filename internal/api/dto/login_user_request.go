@@ -5,9 +5,12 @@ import (
 )
 
 // LoginUserRequest defines the structure for a user login request.
+// UseCookies asks for the tokens in HttpOnly cookies instead of the response body;
+// it requires cookie sessions to be enabled.
 type LoginUserRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,trimLenMin=8,trimLenMax=72,min=8,max=72"`
+	Email      string `json:"email" validate:"required,email"`
+	Password   string `json:"password" validate:"required,trimLenMin=8,trimLenMax=1024,min=8,max=1024"`
+	UseCookies bool   `json:"use_cookies"`
 }
 
 // Valid checks if the LoginUserRequest fields are valid.
@@ -33,7 +36,7 @@ func (r *LoginUserRequest) Valid() map[string]string {
 			case "trimLenMin":
 				errors["password"] = "password must be at least 8 characters long"
 			case "trimLenMax":
-				errors["password"] = "password must not be more than 72 characters long"
+				errors["password"] = "password must not be more than 1024 characters long"
 			}
 		}
 	}
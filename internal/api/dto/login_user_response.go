@@ -1,7 +1,11 @@
 package dto
 
 // LoginUserResponse defines the structure for a successful login response.
+// When the tokens were set as cookies, Token and RefreshToken are left out and CSRFToken
+// holds the value to send in the X-CSRF-Token header.
 type LoginUserResponse struct {
-	Token string        `json:"token"`
-	User  *UserResponse `json:"user"`
+	Token        string        `json:"token,omitempty"`
+	RefreshToken string        `json:"refresh_token,omitempty"`
+	CSRFToken    string        `json:"csrf_token,omitempty"`
+	User         *UserResponse `json:"user"`
 }
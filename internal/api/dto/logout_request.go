@@ -0,0 +1,13 @@
+package dto
+
+// LogoutRequest defines the optional body of a logout request.
+// When a refresh token is provided, the refresh token family it belongs to is revoked too.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Valid checks if the LogoutRequest fields are valid.
+// All fields are optional, so it never reports errors.
+func (r *LogoutRequest) Valid() map[string]string {
+	return nil
+}
@@ -0,0 +1,6 @@
+package dto
+
+// MessageResponse defines a response that only carries a human readable message.
+type MessageResponse struct {
+	Message string `json:"message"`
+}
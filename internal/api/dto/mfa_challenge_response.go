@@ -0,0 +1,11 @@
+package dto
+
+import "time"
+
+// MFAChallengeResponse is returned by login instead of tokens when the user has two-factor
+// authentication enabled. The MFA token is exchanged for real tokens at /auth/mfa/verify.
+type MFAChallengeResponse struct {
+	MFARequired bool      `json:"mfa_required"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// MFACodeRequest defines the structure for requests that prove possession of a second factor.
+// Code is a 6 digit TOTP code or, where accepted, a recovery code.
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required,max=20"`
+}
+
+// Valid checks if the MFACodeRequest fields are valid.
+func (r *MFACodeRequest) Valid() map[string]string {
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		if err.Field() == "Code" {
+			if err.Tag() == "required" {
+				errors["code"] = "code must be provided"
+			} else {
+				errors["code"] = "code must not be more than 20 characters long"
+			}
+		}
+	}
+
+	return errors
+}
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// MFAVerifyRequest defines the structure for completing a two-step login.
+// MFAToken is the token returned by the login endpoint; Code is a TOTP or recovery code.
+// UseCookies works as in LoginUserRequest.
+type MFAVerifyRequest struct {
+	MFAToken   string `json:"mfa_token" validate:"required"`
+	Code       string `json:"code" validate:"required,max=20"`
+	UseCookies bool   `json:"use_cookies"`
+}
+
+// Valid checks if the MFAVerifyRequest fields are valid.
+func (r *MFAVerifyRequest) Valid() map[string]string {
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		switch err.Field() {
+		case "MFAToken":
+			errors["mfa_token"] = "mfa_token must be provided"
+		case "Code":
+			if err.Tag() == "required" {
+				errors["code"] = "code must be provided"
+			} else {
+				errors["code"] = "code must not be more than 20 characters long"
+			}
+		}
+	}
+
+	return errors
+}
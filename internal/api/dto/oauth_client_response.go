@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"go-api-structure/internal/store/db"
+)
+
+// OAuthClientResponse defines the structure for OAuth client metadata returned by the API.
+// The secret is never included; it is only shown when it is issued.
+type OAuthClientResponse struct {
+	ID         uuid.UUID  `json:"id"` // Also the client_id used at the token endpoint
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	Revoked    bool       `json:"revoked"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// NewOAuthClientResponse creates a new OAuthClientResponse DTO from a db.OauthClient model.
+func NewOAuthClientResponse(client *db.OauthClient) *OAuthClientResponse {
+	if client == nil {
+		return nil
+	}
+	resp := &OAuthClientResponse{
+		ID:        client.ID,
+		Name:      client.Name,
+		Scopes:    client.Scopes,
+		Revoked:   client.RevokedAt.Valid,
+		CreatedAt: client.CreatedAt.Time,
+		UpdatedAt: client.UpdatedAt.Time,
+	}
+	if client.LastUsedAt.Valid {
+		resp.LastUsedAt = &client.LastUsedAt.Time
+	}
+	if client.RevokedAt.Valid {
+		resp.RevokedAt = &client.RevokedAt.Time
+	}
+	return resp
+}
+
+// NewOAuthClientListResponse converts a list of db.OauthClient models into response DTOs.
+func NewOAuthClientListResponse(clients []db.OauthClient) []*OAuthClientResponse {
+	resp := make([]*OAuthClientResponse, 0, len(clients))
+	for i := range clients {
+		resp = append(resp, NewOAuthClientResponse(&clients[i]))
+	}
+	return resp
+}
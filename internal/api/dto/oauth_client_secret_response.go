@@ -0,0 +1,10 @@
+package dto
+
+// OAuthClientSecretResponse defines the structure returned when an OAuth client is registered
+// or its secret is rotated. ClientSecret holds the raw secret (gas_client_<secret>). Only its
+// SHA-256 digest is stored, so it is returned here once and can never be retrieved again.
+type OAuthClientSecretResponse struct {
+	ClientID     string               `json:"client_id"`
+	ClientSecret string               `json:"client_secret"`
+	Client       *OAuthClientResponse `json:"client"`
+}
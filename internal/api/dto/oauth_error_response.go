@@ -0,0 +1,8 @@
+package dto
+
+// OAuthErrorResponse defines the error response of the OAuth endpoints (RFC 6749 section 5.2).
+// Error is one of the codes the RFC defines, e.g. invalid_client.
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+// OAuthTokenResponse defines the successful response of the OAuth token endpoint (RFC 6749 section 5.1).
+type OAuthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"` // Seconds
+	Scope       string `json:"scope"`      // Space separated
+}
@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+// OIDCAuthorizationResponse is returned when an OpenID Connect login is started.
+// The front end keeps State, sends the user to AuthorizationURL and, once the provider
+// redirects back, checks that the state it gets is the one it kept before posting it with the code.
+type OIDCAuthorizationResponse struct {
+	AuthorizationURL string    `json:"authorization_url"`
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
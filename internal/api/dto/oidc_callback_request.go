@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// OIDCCallbackRequest completes an OpenID Connect login with what the provider appended to the
+// redirect URL. UseCookies works as in LoginUserRequest.
+type OIDCCallbackRequest struct {
+	Code       string `json:"code" validate:"required,max=2048"`
+	State      string `json:"state" validate:"required,max=256"`
+	UseCookies bool   `json:"use_cookies"`
+}
+
+// Valid checks if the OIDCCallbackRequest fields are valid.
+func (r *OIDCCallbackRequest) Valid() map[string]string {
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		switch err.Field() {
+		case "Code":
+			if err.Tag() == "required" {
+				errors["code"] = "code must be provided"
+			} else {
+				errors["code"] = "code must not be more than 2048 characters long"
+			}
+		case "State":
+			if err.Tag() == "required" {
+				errors["state"] = "state must be provided"
+			} else {
+				errors["state"] = "state must not be more than 256 characters long"
+			}
+		}
+	}
+
+	return errors
+}
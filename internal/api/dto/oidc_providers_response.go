@@ -0,0 +1,6 @@
+package dto
+
+// OIDCProvidersResponse lists the OpenID Connect providers users can sign in with.
+type OIDCProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
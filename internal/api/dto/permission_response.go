@@ -0,0 +1,18 @@
+package dto
+
+import "go-api-structure/internal/store/db"
+
+// PermissionResponse defines the structure for a permission that can be granted to roles.
+type PermissionResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// NewPermissionListResponse converts a list of db.Permission models into response DTOs.
+func NewPermissionListResponse(permissions []db.Permission) []*PermissionResponse {
+	resp := make([]*PermissionResponse, 0, len(permissions))
+	for _, p := range permissions {
+		resp = append(resp, &PermissionResponse{Name: p.Name, Description: p.Description})
+	}
+	return resp
+}
@@ -0,0 +1,7 @@
+package dto
+
+// RecoveryCodesResponse defines the structure returned when TOTP is confirmed.
+// Each code can replace a TOTP code once. They are only shown here.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// RefreshTokenRequest defines the structure for a token refresh request.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// Valid checks if the RefreshTokenRequest fields are valid.
+func (r *RefreshTokenRequest) Valid() map[string]string {
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		if err.Field() == "RefreshToken" {
+			errors["refresh_token"] = "refresh_token must be provided"
+		}
+	}
+
+	return errors
+}
@@ -0,0 +1,11 @@
+package dto
+
+// RefreshTokenResponse defines the structure for a successful token refresh response.
+// The returned refresh token replaces the one that was sent; the old one can no longer be used.
+// When refreshing from the session cookies, the new tokens are set as cookies instead and
+// only the new CSRFToken is returned.
+type RefreshTokenResponse struct {
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	CSRFToken    string `json:"csrf_token,omitempty"`
+}
@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+)
+
+// RegisterClientCertificateRequest defines the expected structure for registering a client certificate.
+// Exactly one of UserID and ServiceAccountID names whom the certificate authenticates as, and exactly
+// one of SPKISHA256 and Subject which certificates match. Scopes must be known API key scopes.
+type RegisterClientCertificateRequest struct {
+	Name             string     `json:"name" validate:"required,trimLenMin=1,trimLenMax=100,max=100"`
+	UserID           *uuid.UUID `json:"user_id,omitempty" validate:"required_without=ServiceAccountID,excluded_with=ServiceAccountID"`
+	ServiceAccountID *uuid.UUID `json:"service_account_id,omitempty"`
+	// SPKISHA256 is the hex SHA-256 digest of the certificate's public key; colons and upper case are accepted.
+	SPKISHA256 string `json:"spki_sha256,omitempty" validate:"required_without=Subject,excluded_with=Subject,omitempty,len=64,hexadecimal"`
+	// Subject is the certificate's distinguished name in RFC 2253 form, e.g. "CN=billing,O=Acme Corp".
+	Subject string   `json:"subject,omitempty" validate:"max=500"`
+	Scopes  []string `json:"scopes" validate:"required,min=1,dive,scope"`
+}
+
+// Valid checks the validity of the RegisterClientCertificateRequest fields.
+// It returns a map of validation errors if any are found, otherwise nil.
+func (r *RegisterClientCertificateRequest) Valid() map[string]string {
+	r.Name = strings.TrimSpace(r.Name)
+	r.SPKISHA256 = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(r.SPKISHA256), ":", ""))
+	r.Subject = strings.TrimSpace(r.Subject)
+
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		switch err.Field() {
+		case "Name":
+			switch err.Tag() {
+			case "required", "trimLenMin":
+				errors["name"] = "name must be provided"
+			default:
+				errors["name"] = "name must not be more than 100 characters long"
+			}
+		case "UserID":
+			errors["user_id"] = "exactly one of user_id and service_account_id must be provided"
+		case "SPKISHA256":
+			switch err.Tag() {
+			case "required_without", "excluded_with":
+				errors["spki_sha256"] = "exactly one of spki_sha256 and subject must be provided"
+			default:
+				errors["spki_sha256"] = "spki_sha256 must be a hex encoded SHA-256 digest"
+			}
+		case "Subject":
+			errors["subject"] = "subject must not be more than 500 characters long"
+		case "Scopes":
+			errors["scopes"] = "at least one scope must be provided"
+		default:
+			// Errors from "dive" are reported per element, e.g. Scopes[0].
+			if strings.HasPrefix(err.Field(), "Scopes[") {
+				errors["scopes"] = "scopes contains an unknown scope: " + err.Value().(string)
+			}
+		}
+	}
+
+	return errors
+}
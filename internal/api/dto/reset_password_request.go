@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// ResetPasswordRequest defines the structure for setting a new password with a reset token.
+// The password rules match CreateUserRequest.
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,trimLenMin=8,trimLenMax=1024,min=8,max=1024"`
+}
+
+// Valid checks if the ResetPasswordRequest fields are valid.
+func (r *ResetPasswordRequest) Valid() map[string]string {
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		switch err.Field() {
+		case "Token":
+			errors["token"] = "token must be provided"
+		case "Password":
+			switch err.Tag() {
+			case "required":
+				errors["password"] = "password must be provided"
+			case "trimLenMin":
+				errors["password"] = "password must be at least 8 characters long"
+			case "trimLenMax":
+				errors["password"] = "password must not be more than 1024 characters long"
+			}
+		}
+	}
+
+	return errors
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"go-api-structure/internal/store/db"
+)
+
+// RoleResponse defines the structure for role data returned by the API.
+// Permissions is left out where a role is listed as one of a user's roles.
+// Built-in roles cannot be renamed, deleted or have their permissions changed.
+type RoleResponse struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Builtin     bool      `json:"builtin"`
+	Permissions []string  `json:"permissions,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NewRoleResponse creates a new RoleResponse DTO from a db.Role model and the permissions it grants.
+func NewRoleResponse(role *db.Role, permissions []string) *RoleResponse {
+	if role == nil {
+		return nil
+	}
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return &RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Builtin:     role.Builtin,
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt.Time,
+		UpdatedAt:   role.UpdatedAt.Time,
+	}
+}
+
+// NewUserRoleListResponse converts the roles assigned to a user into response DTOs, without permissions.
+func NewUserRoleListResponse(roles []db.Role) []*RoleResponse {
+	resp := make([]*RoleResponse, 0, len(roles))
+	for i := range roles {
+		r := NewRoleResponse(&roles[i], nil)
+		r.Permissions = nil
+		resp = append(resp, r)
+	}
+	return resp
+}
@@ -0,0 +1,10 @@
+package dto
+
+// RotateAPIKeyResponse defines the structure returned when an API key is rotated.
+// Key holds the raw replacement key and is only returned here. PreviousAPIKey shows
+// the old key, whose expires_at marks the end of the grace period.
+type RotateAPIKeyResponse struct {
+	Key            string          `json:"key"`
+	APIKey         *APIKeyResponse `json:"api_key"`
+	PreviousAPIKey *APIKeyResponse `json:"previous_api_key"`
+}
@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"go-api-structure/internal/store/db"
+)
+
+// ServiceAccountResponse defines the structure for service account data returned by the API.
+// CreatedBy is the administrator who created the account, if their account still exists.
+type ServiceAccountResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	CreatedBy   *uuid.UUID `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// NewServiceAccountResponse creates a new ServiceAccountResponse DTO from a db.ServiceAccount model.
+func NewServiceAccountResponse(account *db.ServiceAccount) *ServiceAccountResponse {
+	if account == nil {
+		return nil
+	}
+	resp := &ServiceAccountResponse{
+		ID:          account.ID,
+		Name:        account.Name,
+		Description: account.Description,
+		CreatedAt:   account.CreatedAt.Time,
+		UpdatedAt:   account.UpdatedAt.Time,
+	}
+	if account.CreatedBy.Valid {
+		createdBy := uuid.UUID(account.CreatedBy.Bytes)
+		resp.CreatedBy = &createdBy
+	}
+	return resp
+}
+
+// NewServiceAccountListResponse converts a list of db.ServiceAccount models into response DTOs.
+func NewServiceAccountListResponse(accounts []db.ServiceAccount) []*ServiceAccountResponse {
+	resp := make([]*ServiceAccountResponse, 0, len(accounts))
+	for i := range accounts {
+		resp = append(resp, NewServiceAccountResponse(&accounts[i]))
+	}
+	return resp
+}
+
+// ServiceAccountDetailsResponse defines the structure for a single service account
+// with the metadata of its API keys.
+type ServiceAccountDetailsResponse struct {
+	ServiceAccountResponse
+	APIKeys []*APIKeyResponse `json:"api_keys"`
+}
+
+// NewServiceAccountDetailsResponse creates a new ServiceAccountDetailsResponse DTO.
+func NewServiceAccountDetailsResponse(account *db.ServiceAccount, apiKeys []db.ApiKey) *ServiceAccountDetailsResponse {
+	return &ServiceAccountDetailsResponse{
+		ServiceAccountResponse: *NewServiceAccountResponse(account),
+		APIKeys:                NewAPIKeyListResponse(apiKeys),
+	}
+}
@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"go-api-structure/internal/store/db"
+)
+
+// SessionResponse defines the structure for a session returned by the API.
+// Current marks the session the request was made with.
+type SessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	Current    bool      `json:"current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// NewSessionListResponse converts a list of db.Session models into response DTOs,
+// marking the one with the ID currentID.
+func NewSessionListResponse(sessions []db.Session, currentID uuid.UUID) []*SessionResponse {
+	resp := make([]*SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		resp = append(resp, &SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IpAddress,
+			Current:    session.ID == currentID,
+			CreatedAt:  session.CreatedAt.Time,
+			LastSeenAt: session.LastSeenAt.Time,
+			ExpiresAt:  session.ExpiresAt.Time,
+		})
+	}
+	return resp
+}
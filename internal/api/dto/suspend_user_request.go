@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// SuspendUserRequest defines the structure for suspending a user. The reason is optional
+// and only shown to administrators; surrounding whitespace is removed.
+type SuspendUserRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// Valid checks the validity of the SuspendUserRequest fields.
+// It returns a map of validation errors if any are found, otherwise nil.
+func (r *SuspendUserRequest) Valid() map[string]string {
+	r.Reason = strings.TrimSpace(r.Reason)
+
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		if err.Field() == "Reason" {
+			errors["reason"] = "reason must not be more than 500 characters long"
+		}
+	}
+
+	return errors
+}
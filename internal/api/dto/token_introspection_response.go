@@ -0,0 +1,25 @@
+package dto
+
+// TokenIntrospectionResponse defines the response of the token introspection endpoint (RFC 7662).
+// Inactive tokens are described by active alone. Username is set for tokens issued to users,
+// ClientID for tokens issued to OAuth clients. Act names the administrator behind an impersonation token.
+type TokenIntrospectionResponse struct {
+	Active    bool     `json:"active"`
+	Scope     string   `json:"scope,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  []string `json:"aud,omitempty"`
+	Issuer    string   `json:"iss,omitempty"`
+	JTI       string   `json:"jti,omitempty"`
+	Act       *Act     `json:"act,omitempty"`
+}
+
+// Act identifies the party acting on behalf of a token's subject (RFC 8693 section 4.1).
+type Act struct {
+	Subject string `json:"sub"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+// TOTPEnrollmentResponse defines the structure returned when a user starts TOTP enrollment.
+// The URI can be rendered as a QR code; Secret is for manual entry.
+type TOTPEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// UpdateAPIKeyRequest defines the expected structure for updating an API key.
+// Fields that are omitted are left unchanged, but at least one must be provided.
+type UpdateAPIKeyRequest struct {
+	Name   *string  `json:"name,omitempty" validate:"omitempty,trimLenMin=1,trimLenMax=100,max=100"`
+	Scopes []string `json:"scopes,omitempty" validate:"omitempty,min=1,dive,scope"`
+}
+
+// Valid checks the validity of the UpdateAPIKeyRequest fields.
+// It returns a map of validation errors if any are found, otherwise nil.
+func (r *UpdateAPIKeyRequest) Valid() map[string]string {
+	if r.Name == nil && r.Scopes == nil {
+		return map[string]string{"body": "at least one of name or scopes must be provided"}
+	}
+	if r.Name != nil {
+		name := strings.TrimSpace(*r.Name)
+		r.Name = &name
+	}
+
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		switch err.Field() {
+		case "Name":
+			if err.Tag() == "trimLenMin" {
+				errors["name"] = "name must not be empty"
+			} else {
+				errors["name"] = "name must not be more than 100 characters long"
+			}
+		case "Scopes":
+			errors["scopes"] = "at least one scope must be provided"
+		default:
+			if strings.HasPrefix(err.Field(), "Scopes[") {
+				errors["scopes"] = "scopes contains an unknown scope: " + err.Value().(string)
+			}
+		}
+	}
+
+	return errors
+}
@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// UpdateRoleRequest defines the expected structure for updating a role.
+// Fields that are omitted are left unchanged, but at least one must be provided.
+// Permissions replaces the role's permissions; an empty list removes all of them.
+type UpdateRoleRequest struct {
+	Name        *string  `json:"name,omitempty" validate:"omitempty,trimLenMin=1,trimLenMax=50,max=50"`
+	Description *string  `json:"description,omitempty" validate:"omitempty,max=500"`
+	Permissions []string `json:"permissions,omitempty" validate:"dive,permission"`
+}
+
+// Valid checks the validity of the UpdateRoleRequest fields.
+// It returns a map of validation errors if any are found, otherwise nil.
+func (r *UpdateRoleRequest) Valid() map[string]string {
+	if r.Name == nil && r.Description == nil && r.Permissions == nil {
+		return map[string]string{"body": "at least one of name, description or permissions must be provided"}
+	}
+	if r.Name != nil {
+		name := strings.ToLower(strings.TrimSpace(*r.Name))
+		r.Name = &name
+	}
+	if r.Description != nil {
+		description := strings.TrimSpace(*r.Description)
+		r.Description = &description
+	}
+
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		switch err.Field() {
+		case "Name":
+			if err.Tag() == "trimLenMin" {
+				errors["name"] = "name must not be empty"
+			} else {
+				errors["name"] = "name must not be more than 50 characters long"
+			}
+		case "Description":
+			errors["description"] = "description must not be more than 500 characters long"
+		default:
+			if strings.HasPrefix(err.Field(), "Permissions[") {
+				errors["permissions"] = "permissions contains an unknown permission: " + err.Value().(string)
+			}
+		}
+	}
+
+	return errors
+}
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// UpdateServiceAccountRequest defines the expected structure for updating a service account.
+// Fields that are omitted are left unchanged, but at least one must be provided.
+type UpdateServiceAccountRequest struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,trimLenMin=1,trimLenMax=100,max=100"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=500"`
+}
+
+// Valid checks the validity of the UpdateServiceAccountRequest fields.
+// It returns a map of validation errors if any are found, otherwise nil.
+func (r *UpdateServiceAccountRequest) Valid() map[string]string {
+	if r.Name == nil && r.Description == nil {
+		return map[string]string{"body": "at least one of name or description must be provided"}
+	}
+	if r.Name != nil {
+		name := strings.TrimSpace(*r.Name)
+		r.Name = &name
+	}
+	if r.Description != nil {
+		description := strings.TrimSpace(*r.Description)
+		r.Description = &description
+	}
+
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		switch err.Field() {
+		case "Name":
+			if err.Tag() == "trimLenMin" {
+				errors["name"] = "name must not be empty"
+			} else {
+				errors["name"] = "name must not be more than 100 characters long"
+			}
+		case "Description":
+			errors["description"] = "description must not be more than 500 characters long"
+		}
+	}
+
+	return errors
+}
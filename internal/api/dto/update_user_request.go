@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// UpdateUserRequest defines the structure for updating the current user's profile.
+// The username rules match CreateUserRequest; surrounding whitespace is removed.
+type UpdateUserRequest struct {
+	Username string `json:"username" validate:"required,trimLenMin=3,trimLenMax=50,min=3,max=50"`
+}
+
+// Valid checks the validity of the UpdateUserRequest fields.
+// It returns a map of validation errors if any are found, otherwise nil.
+func (r *UpdateUserRequest) Valid() map[string]string {
+	r.Username = strings.TrimSpace(r.Username)
+
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		if err.Field() == "Username" {
+			switch err.Tag() {
+			case "required":
+				errors["username"] = "username must be provided"
+			case "trimLenMin", "min":
+				errors["username"] = "username must be at least 3 characters long"
+			case "trimLenMax", "max":
+				errors["username"] = "username must not be more than 50 characters long"
+			}
+		}
+	}
+
+	return errors
+}
@@ -13,11 +13,13 @@ import (
 // sqlc's default JSON tags for pgtype.Timestamptz handle this correctly.
 // If we needed custom formatting, we'd handle it here or in a custom MarshalJSON.
 type UserResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID            uuid.UUID `json:"id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	MFAEnabled    bool      `json:"mfa_enabled"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 // NewUserResponse creates a new UserResponse DTO from a db.User model.
@@ -26,10 +28,12 @@ func NewUserResponse(user *db.User) *UserResponse {
 		return nil
 	}
 	return &UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt.Time, // Convert pgtype.Timestamptz to time.Time
-		UpdatedAt: user.UpdatedAt.Time, // Convert pgtype.Timestamptz to time.Time
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		MFAEnabled:    user.TotpEnabledAt.Valid,
+		CreatedAt:     user.CreatedAt.Time, // Convert pgtype.Timestamptz to time.Time
+		UpdatedAt:     user.UpdatedAt.Time, // Convert pgtype.Timestamptz to time.Time
 	}
 }
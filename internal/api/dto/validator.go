@@ -6,6 +6,9 @@ import (
 	"sync"
 
 	"github.com/go-playground/validator/v10"
+
+	"go-api-structure/internal/permission"
+	"go-api-structure/internal/scope"
 )
 
 var (
@@ -39,6 +42,16 @@ func Validator() *validator.Validate {
 			trimmed := strings.TrimSpace(fl.Field().String())
 			return len(trimmed) <= param
 		})
+
+		// Register custom validation for API key scopes
+		_ = validatorInstance.RegisterValidation("scope", func(fl validator.FieldLevel) bool {
+			return scope.IsValid(fl.Field().String())
+		})
+
+		// Register custom validation for role permissions
+		_ = validatorInstance.RegisterValidation("permission", func(fl validator.FieldLevel) bool {
+			return permission.IsValid(fl.Field().String())
+		})
 	})
 	
 	return validatorInstance
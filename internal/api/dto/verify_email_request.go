@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"github.com/go-playground/validator/v10"
+)
+
+// VerifyEmailRequest defines the structure for an email verification request.
+// Token is taken from the link in the verification email.
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Valid checks if the VerifyEmailRequest fields are valid.
+func (r *VerifyEmailRequest) Valid() map[string]string {
+	err := Validator().Struct(r)
+	if err == nil {
+		return nil
+	}
+
+	errors := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		if err.Field() == "Token" {
+			errors["token"] = "token must be provided"
+		}
+	}
+
+	return errors
+}
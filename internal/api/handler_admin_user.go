@@ -0,0 +1,402 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"go-api-structure/internal/admin"
+	"go-api-structure/internal/api/dto"
+	"go-api-structure/internal/apikey"
+	"go-api-structure/internal/audit"
+	"go-api-structure/internal/auth"
+	"go-api-structure/internal/rbac"
+)
+
+const (
+	defaultUserPageSize = 50
+	maxUserPageSize     = 100
+)
+
+// AdminUserHandler holds dependencies for the account management HTTP handlers used by administrators.
+type AdminUserHandler struct {
+	adminService admin.ServiceInterface
+}
+
+// NewAdminUserHandler creates a new AdminUserHandler with the given admin service.
+func NewAdminUserHandler(adminService admin.ServiceInterface) *AdminUserHandler {
+	return &AdminUserHandler{adminService: adminService}
+}
+
+// @Summary      List users
+// @Description  Lists users, newest first. q matches the start of the username or email address. Requires the users:read permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Param        q       query     string  false  "Username or email prefix"
+// @Param        status  query     string  false  "Only list active or suspended users" Enums(active, suspended)
+// @Param        limit   query     int     false  "Page size (1-100, default 50)"
+// @Param        offset  query     int     false  "Number of users to skip"
+// @Success      200  {object}  dto.AdminUserListResponse "Successfully retrieved users"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the users:read permission)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (invalid query parameter)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/users [get]
+// ListUsers handles requests to list and search users.
+func (h *AdminUserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params := admin.ListUsersParams{
+		Search: query.Get("q"),
+		Limit:  defaultUserPageSize,
+	}
+	errs := make(map[string]string)
+
+	switch query.Get("status") {
+	case "":
+	case "active":
+		suspended := false
+		params.Suspended = &suspended
+	case "suspended":
+		suspended := true
+		params.Suspended = &suspended
+	default:
+		errs["status"] = "status must be active or suspended"
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxUserPageSize {
+			errs["limit"] = "limit must be a number between 1 and 100"
+		}
+		params.Limit = limit
+	}
+	if v := query.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			errs["offset"] = "offset must be a number of at least 0"
+		}
+		params.Offset = offset
+	}
+	if len(errs) > 0 {
+		FailedValidationResponse(w, r, errs)
+		return
+	}
+
+	users, total, err := h.adminService.ListUsers(r.Context(), params)
+	if err != nil {
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewAdminUserListResponse(users, total, params.Limit, params.Offset))
+}
+
+// @Summary      Get a user
+// @Description  Retrieves a user with their account status, roles and API key metadata. Requires the users:read permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "User ID (UUID format)"
+// @Success      200  {object}  dto.AdminUserDetailsResponse "Successfully retrieved user"
+// @Failure      400  {object}  map[string]string "Invalid user ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the users:read permission)"
+// @Failure      404  {object}  map[string]string "User not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/users/{id} [get]
+// GetUser handles requests for the details of a single user.
+func (h *AdminUserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseIDParam(w, r, "id", "Invalid user ID format")
+	if !ok {
+		return
+	}
+
+	details, err := h.adminService.GetUser(r.Context(), userID)
+	if err != nil {
+		adminErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewAdminUserDetailsResponse(&details.User, details.Roles, details.APIKeys))
+}
+
+// @Summary      Suspend a user
+// @Description  Suspends a user: they are logged out everywhere and can no longer log in or use their API keys until unsuspended. Suspending a suspended user updates the reason. Administrators cannot suspend themselves or the last user with the admin role. Requires the users:write permission.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "User ID (UUID format)"
+// @Param        request body dto.SuspendUserRequest true "Suspension reason (may be empty)"
+// @Success      200  {object}  dto.AdminUserResponse "Successfully suspended user"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON or ID)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the users:write permission)"
+// @Failure      404  {object}  map[string]string "User not found"
+// @Failure      409  {object}  map[string]string "Conflict (own account or last user with the admin role)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/users/{id}/suspend [post]
+// SuspendUser handles requests to suspend a user.
+func (h *AdminUserHandler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := actorAndUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.SuspendUserRequest
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	user, err := h.adminService.SuspendUser(r.Context(), actor, userID, input.Reason)
+	if err != nil {
+		adminErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewAdminUserResponse(user))
+}
+
+// @Summary      Unsuspend a user
+// @Description  Lifts a user's suspension so they can log in again. Requires the users:write permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "User ID (UUID format)"
+// @Success      200  {object}  dto.AdminUserResponse "Successfully unsuspended user"
+// @Failure      400  {object}  map[string]string "Invalid user ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the users:write permission)"
+// @Failure      404  {object}  map[string]string "User not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/users/{id}/unsuspend [post]
+// UnsuspendUser handles requests to lift a user's suspension.
+func (h *AdminUserHandler) UnsuspendUser(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := actorAndUserID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.UnsuspendUser(r.Context(), actor, userID)
+	if err != nil {
+		adminErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewAdminUserResponse(user))
+}
+
+// @Summary      Force a password reset
+// @Description  Logs a user out everywhere and emails them a password reset link. They cannot log in until they have chosen a new password. Requires the users:write permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "User ID (UUID format)"
+// @Success      204  "Password reset required"
+// @Failure      400  {object}  map[string]string "Invalid user ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the users:write permission)"
+// @Failure      404  {object}  map[string]string "User not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/users/{id}/password-reset [post]
+// RequirePasswordReset handles requests to force a user to reset their password.
+func (h *AdminUserHandler) RequirePasswordReset(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := actorAndUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.RequirePasswordReset(r.Context(), actor, userID); err != nil {
+		adminErrorResponse(w, r, err)
+		return
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
+
+// @Summary      Unlock a user
+// @Description  Lifts a lockout caused by repeated failed logins or second factor attempts. Requires the users:write permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "User ID (UUID format)"
+// @Success      204  "Successfully unlocked user"
+// @Failure      400  {object}  map[string]string "Invalid user ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the users:write permission)"
+// @Failure      404  {object}  map[string]string "User not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/users/{id}/unlock [post]
+// UnlockUser handles requests to unlock a locked out user.
+func (h *AdminUserHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := actorAndUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.UnlockUser(r.Context(), actor, userID); err != nil {
+		adminErrorResponse(w, r, err)
+		return
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
+
+// @Summary      Regenerate a user's API key
+// @Description  Replaces one of a user's API keys with a new one carrying the same name, scopes and expiry, and revokes the old key immediately. The new key is only returned in this response and has to be passed on to the user. Requires the users:write permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Param        id     path      string  true  "User ID (UUID format)"
+// @Param        keyID  path      string  true  "API key ID (UUID format)"
+// @Success      200  {object}  dto.RotateAPIKeyResponse "Successfully regenerated API key"
+// @Failure      400  {object}  map[string]string "Invalid user or API key ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the users:write permission)"
+// @Failure      404  {object}  map[string]string "User or API key not found"
+// @Failure      409  {object}  map[string]string "Conflict (key already revoked, expired or rotated)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/users/{id}/api-keys/{keyID}/regenerate [post]
+// RegenerateAPIKey handles requests to replace a user's API key.
+func (h *AdminUserHandler) RegenerateAPIKey(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := actorAndUserID(w, r)
+	if !ok {
+		return
+	}
+	keyID, ok := parseIDParam(w, r, "keyID", "Invalid API key ID format")
+	if !ok {
+		return
+	}
+
+	newKey, rawKey, oldKey, err := h.adminService.RegenerateAPIKey(r.Context(), actor, userID, keyID)
+	if err != nil {
+		adminErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.RotateAPIKeyResponse{
+		Key:            rawKey,
+		APIKey:         dto.NewAPIKeyResponse(newKey),
+		PreviousAPIKey: dto.NewAPIKeyResponse(oldKey),
+	})
+}
+
+// @Summary      Delete a user
+// @Description  Permanently deletes a user with their tokens, API keys and role assignments. Administrators cannot delete themselves or the last user with the admin role. Requires the users:write permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "User ID (UUID format)"
+// @Success      204  "Successfully deleted user"
+// @Failure      400  {object}  map[string]string "Invalid user ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the users:write permission)"
+// @Failure      404  {object}  map[string]string "User not found"
+// @Failure      409  {object}  map[string]string "Conflict (own account or last user with the admin role)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/users/{id} [delete]
+// DeleteUser handles requests to delete a user.
+func (h *AdminUserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	actor, userID, ok := actorAndUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.DeleteUser(r.Context(), actor, userID); err != nil {
+		adminErrorResponse(w, r, err)
+		return
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
+
+// @Summary      Impersonate a user
+// @Description  Issues a short-lived access token that lets the administrator act as the user, e.g. to see what they see at /users/me. The token names the administrator in its act claim (RFC 8693) and cannot be refreshed. Responses to requests made with it carry the X-Impersonated-By header, and every such request is recorded in the audit trail. Administrators cannot impersonate themselves, suspended users or users with permissions they do not hold, and impersonation tokens cannot be used to impersonate anyone else. Requires the users:impersonate permission.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "User ID (UUID format)"
+// @Param        request body dto.ImpersonateUserRequest true "Why the user is impersonated"
+// @Success      200  {object}  dto.ImpersonationResponse "Successfully issued impersonation token"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON or ID)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the users:impersonate permission, or already impersonating)"
+// @Failure      404  {object}  map[string]string "User not found"
+// @Failure      409  {object}  map[string]string "Conflict (own account, suspended user or user with more permissions)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/users/{id}/impersonate [post]
+// ImpersonateUser handles requests to act as another user.
+func (h *AdminUserHandler) ImpersonateUser(w http.ResponseWriter, r *http.Request) {
+	if auth.GetImpersonatorFromContext(r.Context()) != nil {
+		ErrorResponse(w, r, http.StatusForbidden, "impersonation tokens cannot be used to impersonate other users")
+		return
+	}
+	actor, userID, ok := actorAndUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.ImpersonateUserRequest
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	token, user, err := h.adminService.ImpersonateUser(r.Context(), actor, userID, input.Reason)
+	if err != nil {
+		adminErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	encode(w, r, http.StatusOK, dto.ImpersonationResponse{
+		Token:     token.AccessToken,
+		ExpiresAt: token.Claims.ExpiresAt.Time,
+		User:      dto.NewAdminUserResponse(user),
+	})
+}
+
+// actorAndUserID identifies the administrator making the request and extracts the ID of the
+// user they act on from the URL. It writes an error response and returns false on failure.
+func actorAndUserID(w http.ResponseWriter, r *http.Request) (audit.Actor, uuid.UUID, bool) {
+	actor, ok := currentActor(w, r)
+	if !ok {
+		return audit.Actor{}, uuid.Nil, false
+	}
+	userID, ok := parseIDParam(w, r, "id", "Invalid user ID format")
+	if !ok {
+		return audit.Actor{}, uuid.Nil, false
+	}
+	return actor, userID, true
+}
+
+// currentActor identifies whoever makes the request for the audit trail, recording whether it is
+// a person or a machine. It writes an error response and returns false if nobody is authenticated.
+func currentActor(w http.ResponseWriter, r *http.Request) (audit.Actor, bool) {
+	principal := auth.GetPrincipalFromContext(r.Context())
+	if principal == nil {
+		ErrorResponse(w, r, http.StatusUnauthorized, "no authenticated user found in context")
+		return audit.Actor{}, false
+	}
+	return audit.Actor{ID: principal.ID, Kind: string(principal.Kind), ClientIP: clientIP(r)}, true
+}
+
+// adminErrorResponse maps admin service errors to HTTP responses.
+func adminErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, admin.ErrUserNotFound), errors.Is(err, rbac.ErrUserNotFound):
+		ErrorResponse(w, r, http.StatusNotFound, "User not found")
+	case errors.Is(err, admin.ErrAPIKeyNotFound):
+		ErrorResponse(w, r, http.StatusNotFound, "API key not found")
+	case errors.Is(err, admin.ErrSelfAction), errors.Is(err, rbac.ErrLastAdmin), errors.Is(err, admin.ErrImpersonationNotAllowed):
+		ErrorResponse(w, r, http.StatusConflict, err.Error())
+	case errors.Is(err, apikey.ErrAPIKeyRevoked), errors.Is(err, apikey.ErrAPIKeyExpired), errors.Is(err, apikey.ErrAPIKeyRotated):
+		ErrorResponse(w, r, http.StatusConflict, err.Error())
+	default:
+		ServerErrorResponse(w, r, err)
+	}
+}
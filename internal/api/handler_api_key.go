@@ -0,0 +1,281 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"go-api-structure/internal/api/dto"
+	"go-api-structure/internal/apikey"
+	"go-api-structure/internal/auth"
+	"go-api-structure/internal/store" // For store.ErrNotFound
+)
+
+// APIKeyHandler holds dependencies for API key management HTTP handlers.
+type APIKeyHandler struct {
+	apiKeyService apikey.ServiceInterface
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler with the given API key service.
+func NewAPIKeyHandler(apiKeyService apikey.ServiceInterface) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// @Summary      List API keys
+// @Description  Lists the current user's API keys, including revoked and expired ones. Keys themselves are never returned.
+// @Tags         API Keys
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {array}   dto.APIKeyResponse "Successfully retrieved API keys"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., no user in context, invalid token)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /users/me/api-keys [get]
+// ListAPIKeys handles requests to list the authenticated user's API keys.
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		ErrorResponse(w, r, http.StatusUnauthorized, "no authenticated user found in context")
+		return
+	}
+
+	apiKeys, err := h.apiKeyService.List(r.Context(), user.ID)
+	if err != nil {
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewAPIKeyListResponse(apiKeys))
+}
+
+// @Summary      Create an API key
+// @Description  Creates a new named API key with the given scopes and optional expiry. The key is only returned in this response.
+// @Tags         API Keys
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body dto.CreateAPIKeyRequest true "API key details"
+// @Success      201  {object}  dto.CreateAPIKeyResponse "Successfully created API key"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., no user in context, invalid token)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /users/me/api-keys [post]
+// CreateAPIKey handles requests to create a new API key for the authenticated user.
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		ErrorResponse(w, r, http.StatusUnauthorized, "no authenticated user found in context")
+		return
+	}
+
+	var input dto.CreateAPIKeyRequest
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	apiKey, rawKey, err := h.apiKeyService.Create(r.Context(), user.ID, apikey.CreateParams{
+		Name:      input.Name,
+		Scopes:    input.Scopes,
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusCreated, dto.CreateAPIKeyResponse{
+		Key:    rawKey,
+		APIKey: dto.NewAPIKeyResponse(apiKey),
+	})
+}
+
+// @Summary      Get an API key
+// @Description  Retrieves the metadata of one of the current user's API keys.
+// @Tags         API Keys
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "API key ID (UUID format)"
+// @Success      200  {object}  dto.APIKeyResponse "Successfully retrieved API key"
+// @Failure      400  {object}  map[string]string "Invalid API key ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., no user in context, invalid token)"
+// @Failure      404  {object}  map[string]string "API key not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /users/me/api-keys/{id} [get]
+// GetAPIKey handles requests for a single API key of the authenticated user.
+func (h *APIKeyHandler) GetAPIKey(w http.ResponseWriter, r *http.Request) {
+	user, keyID, ok := h.userAndKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	apiKey, err := h.apiKeyService.Get(r.Context(), user, keyID)
+	if err != nil {
+		apiKeyErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewAPIKeyResponse(apiKey))
+}
+
+// @Summary      Update an API key
+// @Description  Renames an API key and/or replaces its scopes.
+// @Tags         API Keys
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "API key ID (UUID format)"
+// @Param        request body dto.UpdateAPIKeyRequest true "Fields to update"
+// @Success      200  {object}  dto.APIKeyResponse "Successfully updated API key"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON or ID)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., no user in context, invalid token)"
+// @Failure      404  {object}  map[string]string "API key not found"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /users/me/api-keys/{id} [patch]
+// UpdateAPIKey handles requests to update an API key of the authenticated user.
+func (h *APIKeyHandler) UpdateAPIKey(w http.ResponseWriter, r *http.Request) {
+	user, keyID, ok := h.userAndKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.UpdateAPIKeyRequest
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	apiKey, err := h.apiKeyService.Update(r.Context(), user, keyID, apikey.UpdateParams{
+		Name:   input.Name,
+		Scopes: input.Scopes,
+	})
+	if err != nil {
+		apiKeyErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewAPIKeyResponse(apiKey))
+}
+
+// @Summary      Revoke an API key
+// @Description  Revokes one of the current user's API keys. Revoked keys are rejected immediately but remain listed.
+// @Tags         API Keys
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "API key ID (UUID format)"
+// @Success      204  "Successfully revoked API key"
+// @Failure      400  {object}  map[string]string "Invalid API key ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., no user in context, invalid token)"
+// @Failure      404  {object}  map[string]string "API key not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /users/me/api-keys/{id} [delete]
+// RevokeAPIKey handles requests to revoke an API key of the authenticated user.
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	user, keyID, ok := h.userAndKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := h.apiKeyService.Revoke(r.Context(), user, keyID); err != nil {
+		apiKeyErrorResponse(w, r, err)
+		return
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
+
+// @Summary      Rotate an API key
+// @Description  Issues a replacement for an API key with the same name, scopes and expiry. The old key keeps working until the end of the configured grace period. The new key is only returned in this response.
+// @Tags         API Keys
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "API key ID (UUID format)"
+// @Success      201  {object}  dto.RotateAPIKeyResponse "Successfully rotated API key"
+// @Failure      400  {object}  map[string]string "Invalid API key ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., no user in context, invalid token)"
+// @Failure      404  {object}  map[string]string "API key not found"
+// @Failure      409  {object}  map[string]string "Conflict (API key is revoked, expired or already rotated)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /users/me/api-keys/{id}/rotate [post]
+// RotateAPIKey handles requests to replace an API key of the authenticated user.
+func (h *APIKeyHandler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	user, keyID, ok := h.userAndKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	newKey, rawKey, oldKey, err := h.apiKeyService.Rotate(r.Context(), user, keyID)
+	if err != nil {
+		apiKeyErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusCreated, dto.RotateAPIKeyResponse{
+		Key:            rawKey,
+		APIKey:         dto.NewAPIKeyResponse(newKey),
+		PreviousAPIKey: dto.NewAPIKeyResponse(oldKey),
+	})
+}
+
+// @Summary      Rotate the default API key
+// @Description  Rotates the API key named "default", which every account had before users could manage several keys, like POST /users/me/api-keys/{id}/rotate does. The old key keeps working until the end of the configured grace period. The new key is only returned in this response.
+// @Tags         API Keys
+// @Produce      json
+// @Security     Bearer
+// @Success      201  {object}  dto.RotateAPIKeyResponse "Successfully rotated API key"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., no user in context, invalid token)"
+// @Failure      404  {object}  map[string]string "The user has no default API key left to rotate"
+// @Failure      409  {object}  map[string]string "Conflict (API key is expired or was rotated concurrently)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /users/me/api-key/rotate [post]
+// RotateDefaultAPIKey handles requests to replace the authenticated user's default API key.
+func (h *APIKeyHandler) RotateDefaultAPIKey(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		ErrorResponse(w, r, http.StatusUnauthorized, "no authenticated user found in context")
+		return
+	}
+
+	newKey, rawKey, oldKey, err := h.apiKeyService.RotateDefault(r.Context(), user.ID)
+	if err != nil {
+		apiKeyErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusCreated, dto.RotateAPIKeyResponse{
+		Key:            rawKey,
+		APIKey:         dto.NewAPIKeyResponse(newKey),
+		PreviousAPIKey: dto.NewAPIKeyResponse(oldKey),
+	})
+}
+
+// userAndKeyID extracts the authenticated user's ID and the API key ID from the URL.
+// It writes an error response and returns false if either is missing or malformed.
+func (h *APIKeyHandler) userAndKeyID(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		ErrorResponse(w, r, http.StatusUnauthorized, "no authenticated user found in context")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	keyID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		ErrorResponse(w, r, http.StatusBadRequest, "Invalid API key ID format")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return user.ID, keyID, true
+}
+
+// apiKeyErrorResponse maps API key service errors to HTTP responses.
+func apiKeyErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		ErrorResponse(w, r, http.StatusNotFound, "API key not found")
+	case errors.Is(err, apikey.ErrAPIKeyRevoked), errors.Is(err, apikey.ErrAPIKeyExpired), errors.Is(err, apikey.ErrAPIKeyRotated):
+		ErrorResponse(w, r, http.StatusConflict, err.Error())
+	default:
+		ServerErrorResponse(w, r, err)
+	}
+}
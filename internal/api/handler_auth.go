@@ -6,7 +6,9 @@ import (
 
 	"go-api-structure/internal/api/dto"
 	"go-api-structure/internal/auth"
+	"go-api-structure/internal/passwordpolicy"
 	"go-api-structure/internal/store" // For store.ErrNotFound
+	"go-api-structure/internal/store/db"
 )
 
 // AuthHandler holds dependencies for authentication-related HTTP handlers.
@@ -20,21 +22,23 @@ func NewAuthHandler(authService *auth.AuthService) *AuthHandler {
 }
 
 // @Summary      Register a new user
-// @Description  Creates a new user account with the provided details.
+// @Description  Creates a new user account with the provided details and sends an email to verify the address. When existing accounts are concealed, the response is 202 Accepted whether or not the email address is already registered; the owner of a registered address is notified by email instead.
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
 // @Param        user body dto.CreateUserRequest true "User registration details"
 // @Success      201  {object}  dto.UserResponse "Successfully registered user"
+// @Success      202  {object}  dto.MessageResponse "Registration accepted (existing accounts concealed)"
 // @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
-// @Failure      409  {object}  map[string]string "Conflict (user already exists)"
-// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      409  {object}  map[string]string "Conflict (user already exists; only the username when existing accounts are concealed)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error or password rejected by the password policy)"
 // @Failure      500  {object}  map[string]string "Internal server error"
 // @Router       /auth/register [post]
 // RegisterUser handles user registration requests.
 // It expects a JSON body conforming to dto.CreateUserRequest.
 // On success, it returns a 201 Created status with the new user's details (excluding password).
 // On failure, it returns appropriate error responses (e.g., 400 for bad request, 422 for validation errors, 409 for conflict).
+// When the service conceals existing accounts, a taken email address gets the same 202 Accepted as a new one.
 func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	var input dto.CreateUserRequest
 
@@ -42,60 +46,319 @@ func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 		return // Errors handled by decodeAndValidate
 	}
 
+	concealed := h.authService.ConcealsExistingAccounts()
+	accepted := dto.MessageResponse{
+		Message: "registration received, check your inbox to verify your email address",
+	}
+
 	// authService.Register expects *dto.CreateUserRequest (pointer type)
 	createdUser, err := h.authService.Register(r.Context(), &input)
 	if err != nil {
-		switch err {
-		case auth.ErrUserAlreadyExists:
+		var rejected *passwordpolicy.Error
+		switch {
+		case errors.As(err, &rejected):
+			FailedValidationResponse(w, r, map[string]string{"password": rejected.Reason})
+		case errors.Is(err, auth.ErrUserAlreadyExists) && concealed:
+			encode(w, r, http.StatusAccepted, accepted)
+		case errors.Is(err, auth.ErrUserAlreadyExists):
 			ErrorResponse(w, r, http.StatusConflict, "a user with this email or username already exists")
+		case errors.Is(err, auth.ErrUsernameTaken):
+			ErrorResponse(w, r, http.StatusConflict, "a user with this username already exists")
 		default:
 			ServerErrorResponse(w, r, err)
 		}
 		return
 	}
 
+	// The account exists at this point, so a failed email only gets logged;
+	// the user can ask for another one at /auth/verify-email/resend.
+	if err := h.authService.SendVerificationEmail(r.Context(), createdUser); err != nil {
+		logError(r, "failed to send verification email", err)
+	}
+
+	if concealed {
+		encode(w, r, http.StatusAccepted, accepted)
+		return
+	}
+
 	// Return a DTO that doesn't include sensitive info like password hash.
 	userResponse := dto.NewUserResponse(createdUser)
 	encode(w, r, http.StatusCreated, userResponse)
 }
 
 // @Summary      Log in a user
-// @Description  Authenticates a user with email and password, returning a JWT and user details upon success.
+// @Description  Authenticates a user with email and password, returning a JWT, a refresh token and user details upon success. Unknown addresses and wrong passwords get the same response after the same amount of work. With use_cookies, the tokens are set as HttpOnly cookies instead and a CSRF token is returned.
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
 // @Param        credentials body dto.LoginUserRequest true "User login credentials"
 // @Success      200  {object}  dto.LoginUserResponse "Successfully logged in"
+// @Success      202  {object}  dto.MFAChallengeResponse "Password accepted; complete the login at /auth/mfa/verify"
 // @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
 // @Failure      401  {object}  map[string]string "Unauthorized (invalid credentials)"
-// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      403  {object}  map[string]string "Forbidden (account suspended, password reset required, or email address not verified when verification is required)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error, or use_cookies while cookie sessions are disabled)"
+// @Failure      429  {object}  map[string]string "Too many failed attempts for this account or client; see the Retry-After header"
 // @Failure      500  {object}  map[string]string "Internal server error"
 // @Router       /auth/login [post]
 // LoginUser handles user login requests.
 // It expects an email and password in the request body.
-// On successful authentication, it returns a JWT and user information.
+// On successful authentication, it returns a JWT, a refresh token and user information.
+// Users with two-factor authentication enabled get 202 Accepted and an MFA token instead.
 func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	var input dto.LoginUserRequest
 
 	if !decodeAndValidate(w, r, &input) {
 		return // Errors handled by decodeAndValidate
 	}
+	if h.cookiesUnavailable(w, r, input.UseCookies) {
+		return
+	}
 
-	token, user, err := h.authService.Login(r.Context(), input.Email, input.Password)
+	tokens, user, err := h.authService.Login(r.Context(), input.Email, input.Password, clientInfo(r))
 	if err != nil {
+		var challenge *auth.MFAChallengeError
+		var throttled *auth.ThrottledError
 		switch {
+		case errors.As(err, &throttled):
+			TooManyRequestsResponse(w, r, throttled.RetryAfter)
+		case errors.As(err, &challenge):
+			encode(w, r, http.StatusAccepted, dto.MFAChallengeResponse{
+				MFARequired: true,
+				MFAToken:    challenge.Token,
+				ExpiresAt:   challenge.ExpiresAt,
+			})
 		case errors.Is(err, auth.ErrInvalidCredentials), errors.Is(err, store.ErrNotFound):
 			ErrorResponse(w, r, http.StatusUnauthorized, "invalid email or password")
+		case errors.Is(err, auth.ErrAccountSuspended):
+			ErrorResponse(w, r, http.StatusForbidden, "account is suspended")
+		case errors.Is(err, auth.ErrPasswordResetRequired):
+			ErrorResponse(w, r, http.StatusForbidden, "a new password must be set before logging in; use the link sent by email or request a new one")
+		case errors.Is(err, auth.ErrEmailNotVerified):
+			ErrorResponse(w, r, http.StatusForbidden, "email address has not been verified")
 		default:
 			ServerErrorResponse(w, r, err)
 		}
 		return
 	}
 
-	loginResponse := dto.LoginUserResponse{
-		Token: token,
-		User:  dto.NewUserResponse(user),
+	h.loggedIn(w, r, tokens, user, input.UseCookies)
+}
+
+// cookiesUnavailable writes a 422 response and returns true if the client asked for
+// cookies while cookie sessions are disabled.
+func (h *AuthHandler) cookiesUnavailable(w http.ResponseWriter, r *http.Request, useCookies bool) bool {
+	if useCookies && !h.authService.CookieSessionsEnabled() {
+		FailedValidationResponse(w, r, map[string]string{"use_cookies": "cookie sessions are not enabled"})
+		return true
+	}
+	return false
+}
+
+// loggedIn sends the response to a completed login, with the tokens either in the body
+// or, if the client asked for it, in the session cookies.
+func (h *AuthHandler) loggedIn(w http.ResponseWriter, r *http.Request, tokens *auth.TokenPair, user *db.User, useCookies bool) {
+	loginResponse := dto.LoginUserResponse{User: dto.NewUserResponse(user)}
+
+	if useCookies {
+		csrfToken, err := h.authService.SetSessionCookies(w, tokens)
+		if err != nil {
+			ServerErrorResponse(w, r, err)
+			return
+		}
+		loginResponse.CSRFToken = csrfToken
+	} else {
+		loginResponse.Token = tokens.AccessToken
+		loginResponse.RefreshToken = tokens.RefreshToken
 	}
 
 	encode(w, r, http.StatusOK, loginResponse)
 }
+
+// @Summary      Refresh an access token
+// @Description  Exchanges a refresh token for a new access token and a new refresh token. Each refresh token can only be used once; reusing one revokes every token issued from the same login. Browser sessions send no body; the refresh token cookie is used and replaced instead, which requires the X-CSRF-Token header.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.RefreshTokenRequest false "Refresh token (omitted for browser sessions)"
+// @Success      200  {object}  dto.RefreshTokenResponse "Successfully refreshed tokens"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
+// @Failure      401  {object}  map[string]string "Unauthorized (invalid, expired or reused refresh token)"
+// @Failure      403  {object}  map[string]string "Forbidden (account suspended, or missing CSRF token)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /auth/refresh [post]
+// RefreshToken handles refresh token rotation requests.
+// It expects a refresh token in the request body and returns a new token pair.
+// Without a body, it rotates the tokens held in the session cookies.
+func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var input dto.RefreshTokenRequest
+
+	fromCookie := r.ContentLength == 0 && h.authService.RefreshTokenFromCookie(r) != ""
+	if fromCookie {
+		if !h.authService.VerifyCSRF(r) {
+			ErrorResponse(w, r, http.StatusForbidden, "missing or invalid CSRF token")
+			return
+		}
+		input.RefreshToken = h.authService.RefreshTokenFromCookie(r)
+	} else if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	tokens, _, err := h.authService.Refresh(r.Context(), input.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidRefreshToken), errors.Is(err, auth.ErrRefreshTokenReused):
+			if fromCookie {
+				h.authService.ClearSessionCookies(w)
+			}
+			ErrorResponse(w, r, http.StatusUnauthorized, "invalid or expired refresh token")
+		case errors.Is(err, auth.ErrAccountSuspended):
+			ErrorResponse(w, r, http.StatusForbidden, "account is suspended")
+		default:
+			ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	var refreshResponse dto.RefreshTokenResponse
+	if fromCookie {
+		csrfToken, err := h.authService.SetSessionCookies(w, tokens)
+		if err != nil {
+			ServerErrorResponse(w, r, err)
+			return
+		}
+		refreshResponse.CSRFToken = csrfToken
+	} else {
+		refreshResponse.Token = tokens.AccessToken
+		refreshResponse.RefreshToken = tokens.RefreshToken
+	}
+
+	encode(w, r, http.StatusOK, refreshResponse)
+}
+
+// @Summary      Get the JSON Web Key Set
+// @Description  Returns the public keys access tokens are signed with (RFC 7517), so other services can verify tokens without sharing a secret. Keys are matched to tokens by their kid. Retired keys stay listed until they are removed from the configuration. HMAC keys are secret and never listed. Served at the server root as /.well-known/jwks.json, outside the /api/v1 base path; responses may be cached for five minutes.
+// @Tags         Auth
+// @Produce      json
+// @Success      200  {object}  auth.JWKSet "Public signing keys"
+// @Router       /.well-known/jwks.json [get]
+// GetJWKS serves the public signing keys as a JSON Web Key Set.
+// Other services use it to verify access tokens without sharing a secret.
+// Retired keys stay listed until they are removed from the configuration,
+// so tokens signed before a key rotation can still be verified.
+func (h *AuthHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	encode(w, r, http.StatusOK, h.authService.JWKS())
+}
+
+// @Summary      Log out
+// @Description  Revokes the access token used for this request. If a refresh token is supplied, the refresh tokens issued alongside it are revoked as well. Browser sessions are logged out from their cookies, which are cleared.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body dto.LogoutRequest false "Refresh token to revoke"
+// @Success      204  "Successfully logged out"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid or revoked token)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /auth/logout [post]
+// LogoutUser handles logout requests.
+// It expects the request to be authenticated by the JWT middleware.
+func (h *AuthHandler) LogoutUser(w http.ResponseWriter, r *http.Request) {
+	claims := auth.GetClaimsFromContext(r.Context())
+	if claims == nil {
+		ErrorResponse(w, r, http.StatusUnauthorized, "no token claims found in context")
+		return
+	}
+
+	var input dto.LogoutRequest
+	if r.ContentLength != 0 && !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	if input.RefreshToken == "" {
+		input.RefreshToken = h.authService.RefreshTokenFromCookie(r)
+	}
+
+	if err := h.authService.Logout(r.Context(), claims, input.RefreshToken); err != nil {
+		ServerErrorResponse(w, r, err)
+		return
+	}
+	if h.authService.CookieSessionsEnabled() {
+		h.authService.ClearSessionCookies(w)
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
+
+// @Summary      Revoke all sessions
+// @Description  Invalidates every access token and refresh token issued to the current user, logging them out everywhere.
+// @Tags         Users
+// @Produce      json
+// @Security     Bearer
+// @Success      204  "Successfully revoked all sessions"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., no user in context, invalid token)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /users/me/sessions/revoke-all [post]
+// RevokeAllSessions handles requests to log the current user out of every session.
+// It expects the user to be authenticated by the JWT middleware.
+func (h *AuthHandler) RevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		ErrorResponse(w, r, http.StatusUnauthorized, "no authenticated user found in context")
+		return
+	}
+
+	if err := h.authService.RevokeAllSessions(r.Context(), user.ID); err != nil {
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
+
+// @Summary      Change password
+// @Description  Changes the current user's password. The current password must be provided.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body dto.ChangePasswordRequest true "Current and new password"
+// @Success      204  "Password changed"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., no user in context, invalid token)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error, wrong current password, or new password rejected by the password policy or reused)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /users/me/password [put]
+// ChangePassword handles requests to change the authenticated user's password.
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		ErrorResponse(w, r, http.StatusUnauthorized, "no authenticated user found in context")
+		return
+	}
+
+	var input dto.ChangePasswordRequest
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	if err := h.authService.ChangePassword(r.Context(), user, input.CurrentPassword, input.NewPassword); err != nil {
+		var rejected *passwordpolicy.Error
+		switch {
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			FailedValidationResponse(w, r, map[string]string{"current_password": "current password is incorrect"})
+		case errors.As(err, &rejected):
+			FailedValidationResponse(w, r, map[string]string{"new_password": rejected.Reason})
+		case errors.Is(err, auth.ErrPasswordReused):
+			FailedValidationResponse(w, r, map[string]string{"new_password": "new_password must not be one of your recent passwords"})
+		default:
+			ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
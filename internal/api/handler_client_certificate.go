@@ -0,0 +1,159 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"go-api-structure/internal/api/dto"
+	"go-api-structure/internal/clientcert"
+)
+
+// ClientCertificateHandler holds dependencies for the HTTP handlers administrators use to manage
+// the client certificates accepted for mutual TLS.
+type ClientCertificateHandler struct {
+	certificateService clientcert.ServiceInterface
+}
+
+// NewClientCertificateHandler creates a new ClientCertificateHandler with the given client certificate service.
+func NewClientCertificateHandler(certificateService clientcert.ServiceInterface) *ClientCertificateHandler {
+	return &ClientCertificateHandler{certificateService: certificateService}
+}
+
+// @Summary      List client certificates
+// @Description  Lists the client certificates registered for mutual TLS, newest first. Requires the client_certificates:read permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {array}   dto.ClientCertificateResponse "Successfully retrieved client certificates"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the client_certificates:read permission)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/client-certificates [get]
+// ListClientCertificates handles requests to list client certificates.
+func (h *ClientCertificateHandler) ListClientCertificates(w http.ResponseWriter, r *http.Request) {
+	certificates, err := h.certificateService.List(r.Context())
+	if err != nil {
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewClientCertificateListResponse(certificates))
+}
+
+// @Summary      Register a client certificate
+// @Description  Lets a user or service account authenticate with a client certificate on the TLS listener, wherever API keys are accepted. Certificates must be issued by a CA in the configured bundle and are matched by the SHA-256 fingerprint of their public key or, to survive renewals, by their subject. Scopes limit what the certificate can be used for, as they do for API keys. Requires the client_certificates:write permission.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body dto.RegisterClientCertificateRequest true "Owner, fingerprint or subject, and scopes"
+// @Success      201  {object}  dto.ClientCertificateResponse "Successfully registered client certificate"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the client_certificates:write permission)"
+// @Failure      409  {object}  map[string]string "Conflict (fingerprint or subject already registered)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error, or unknown user or service account)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/client-certificates [post]
+// RegisterClientCertificate handles requests to register a client certificate.
+func (h *ClientCertificateHandler) RegisterClientCertificate(w http.ResponseWriter, r *http.Request) {
+	actor, ok := currentActor(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.RegisterClientCertificateRequest
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	certificate, err := h.certificateService.Register(r.Context(), actor, clientcert.RegisterParams{
+		Name:             input.Name,
+		UserID:           input.UserID,
+		ServiceAccountID: input.ServiceAccountID,
+		SPKISHA256:       input.SPKISHA256,
+		Subject:          input.Subject,
+		Scopes:           input.Scopes,
+	})
+	if err != nil {
+		clientCertificateErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusCreated, dto.NewClientCertificateResponse(certificate))
+}
+
+// @Summary      Get a client certificate
+// @Description  Retrieves a client certificate registration. Requires the client_certificates:read permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Client certificate ID (UUID format)"
+// @Success      200  {object}  dto.ClientCertificateResponse "Successfully retrieved client certificate"
+// @Failure      400  {object}  map[string]string "Invalid client certificate ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the client_certificates:read permission)"
+// @Failure      404  {object}  map[string]string "Client certificate not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/client-certificates/{id} [get]
+// GetClientCertificate handles requests for a single client certificate.
+func (h *ClientCertificateHandler) GetClientCertificate(w http.ResponseWriter, r *http.Request) {
+	certificateID, ok := parseIDParam(w, r, "id", "Invalid client certificate ID format")
+	if !ok {
+		return
+	}
+
+	certificate, err := h.certificateService.Get(r.Context(), certificateID)
+	if err != nil {
+		clientCertificateErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewClientCertificateResponse(certificate))
+}
+
+// @Summary      Delete a client certificate
+// @Description  Deletes a client certificate registration. Certificates matching it are rejected from the next request on. Requires the client_certificates:write permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Client certificate ID (UUID format)"
+// @Success      204  "Successfully deleted client certificate"
+// @Failure      400  {object}  map[string]string "Invalid client certificate ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the client_certificates:write permission)"
+// @Failure      404  {object}  map[string]string "Client certificate not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/client-certificates/{id} [delete]
+// DeleteClientCertificate handles requests to delete a client certificate.
+func (h *ClientCertificateHandler) DeleteClientCertificate(w http.ResponseWriter, r *http.Request) {
+	actor, ok := currentActor(w, r)
+	if !ok {
+		return
+	}
+	certificateID, ok := parseIDParam(w, r, "id", "Invalid client certificate ID format")
+	if !ok {
+		return
+	}
+
+	if err := h.certificateService.Delete(r.Context(), actor, certificateID); err != nil {
+		clientCertificateErrorResponse(w, r, err)
+		return
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
+
+// clientCertificateErrorResponse maps client certificate service errors to HTTP responses.
+func clientCertificateErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, clientcert.ErrCertificateNotFound):
+		ErrorResponse(w, r, http.StatusNotFound, "Client certificate not found")
+	case errors.Is(err, clientcert.ErrAlreadyRegistered):
+		ErrorResponse(w, r, http.StatusConflict, err.Error())
+	case errors.Is(err, clientcert.ErrOwnerNotFound):
+		ErrorResponse(w, r, http.StatusUnprocessableEntity, err.Error())
+	default:
+		ServerErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"go-api-structure/internal/api/dto"
+	"go-api-structure/internal/auth"
+)
+
+// @Summary      Request an email change
+// @Description  Starts changing the current user's email address. The new address is kept pending until it is confirmed with the token mailed to it; the current address is notified and can cancel the change. A new request replaces a pending one.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body dto.ChangeEmailRequest true "New email address and current password"
+// @Success      202  {object}  dto.EmailChangeResponse "Confirmation email sent"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., no user in context, invalid token)"
+// @Failure      409  {object}  map[string]string "Conflict (email address already in use)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error, wrong current password or unchanged address)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /users/me/email [post]
+// RequestEmailChange handles requests to change the authenticated user's email address.
+func (h *AuthHandler) RequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		ErrorResponse(w, r, http.StatusUnauthorized, "no authenticated user found in context")
+		return
+	}
+
+	var input dto.ChangeEmailRequest
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	request, err := h.authService.RequestEmailChange(r.Context(), user, input.CurrentPassword, input.NewEmail)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			FailedValidationResponse(w, r, map[string]string{"current_password": "current password is incorrect"})
+		case errors.Is(err, auth.ErrEmailUnchanged):
+			FailedValidationResponse(w, r, map[string]string{"new_email": "new_email must differ from the current address"})
+		case errors.Is(err, auth.ErrEmailTaken):
+			ErrorResponse(w, r, http.StatusConflict, "a user with this email already exists")
+		default:
+			ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	encode(w, r, http.StatusAccepted, dto.NewEmailChangeResponse(request))
+}
+
+// @Summary      Confirm an email change
+// @Description  Replaces the user's email address with the pending one using the token mailed to the new address. The new address is marked verified.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.EmailChangeTokenRequest true "Confirmation token"
+// @Success      200  {object}  dto.UserResponse "Email address changed"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON, invalid or expired token)"
+// @Failure      409  {object}  map[string]string "Conflict (email address taken in the meantime)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /auth/email-change/confirm [post]
+// ConfirmEmailChange handles requests to confirm a pending email change.
+func (h *AuthHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	var input dto.EmailChangeTokenRequest
+
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	user, err := h.authService.ConfirmEmailChange(r.Context(), input.Token)
+	if err != nil {
+		emailChangeErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewUserResponse(user))
+}
+
+// @Summary      Cancel an email change
+// @Description  Cancels an email change using the token mailed to the previous address. If the change was already confirmed, the previous address is restored and all sessions and API keys of the account are revoked.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.EmailChangeTokenRequest true "Cancellation token"
+// @Success      204  "Email change cancelled"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON, invalid or expired token)"
+// @Failure      409  {object}  map[string]string "Conflict (previous address taken in the meantime)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /auth/email-change/cancel [post]
+// CancelEmailChange handles requests to cancel an email change.
+func (h *AuthHandler) CancelEmailChange(w http.ResponseWriter, r *http.Request) {
+	var input dto.EmailChangeTokenRequest
+
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	if err := h.authService.CancelEmailChange(r.Context(), input.Token); err != nil {
+		emailChangeErrorResponse(w, r, err)
+		return
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
+
+// emailChangeErrorResponse maps errors of the email change token endpoints to responses.
+func emailChangeErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, auth.ErrInvalidEmailChangeToken):
+		ErrorResponse(w, r, http.StatusBadRequest, "invalid or expired email change token")
+	case errors.Is(err, auth.ErrEmailTaken):
+		ErrorResponse(w, r, http.StatusConflict, "a user with this email already exists")
+	default:
+		ServerErrorResponse(w, r, err)
+	}
+}
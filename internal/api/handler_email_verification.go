@@ -0,0 +1,71 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"go-api-structure/internal/api/dto"
+	"go-api-structure/internal/auth"
+)
+
+// @Summary      Verify an email address
+// @Description  Marks the user's email address as verified using the token from the verification email. Each token can only be used once.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.VerifyEmailRequest true "Verification token"
+// @Success      200  {object}  dto.UserResponse "Email address verified"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON, invalid or expired token)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /auth/verify-email [post]
+// VerifyEmail handles email verification requests.
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var input dto.VerifyEmailRequest
+
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	user, err := h.authService.VerifyEmail(r.Context(), input.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidEmailVerificationToken):
+			ErrorResponse(w, r, http.StatusBadRequest, "invalid or expired verification token")
+		default:
+			ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewUserResponse(user))
+}
+
+// @Summary      Resend the verification email
+// @Description  Sends a new verification email if the address belongs to an unverified account. The response is the same whether or not it does.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.EmailRequest true "Email address"
+// @Success      202  {object}  dto.MessageResponse "Request accepted"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /auth/verify-email/resend [post]
+// ResendVerificationEmail handles requests for a new verification email.
+func (h *AuthHandler) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	var input dto.EmailRequest
+
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	if err := h.authService.ResendVerificationEmail(r.Context(), input.Email); err != nil {
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusAccepted, dto.MessageResponse{
+		Message: "if the address belongs to an unverified account, a verification email has been sent",
+	})
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"go-api-structure/internal/api/dto"
+	"go-api-structure/internal/auth"
+)
+
+// @Summary      Complete a two-step login
+// @Description  Exchanges the MFA token returned by the login endpoint, together with a TOTP code or a recovery code, for an access token and a refresh token. Each MFA token, TOTP code and recovery code can only be used once. use_cookies works as for the login endpoint.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.MFAVerifyRequest true "MFA token and code"
+// @Success      200  {object}  dto.LoginUserResponse "Successfully logged in"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
+// @Failure      401  {object}  map[string]string "Unauthorized (invalid or expired MFA token, or invalid code)"
+// @Failure      403  {object}  map[string]string "Forbidden (account suspended)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error, or use_cookies while cookie sessions are disabled)"
+// @Failure      429  {object}  map[string]string "Too many wrong codes for this account or client; see the Retry-After header"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /auth/mfa/verify [post]
+// VerifyMFA handles the second step of a login for users with two-factor authentication enabled.
+func (h *AuthHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var input dto.MFAVerifyRequest
+
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+	if h.cookiesUnavailable(w, r, input.UseCookies) {
+		return
+	}
+
+	tokens, user, err := h.authService.VerifyMFA(r.Context(), input.MFAToken, input.Code, clientInfo(r))
+	if err != nil {
+		var throttled *auth.ThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			TooManyRequestsResponse(w, r, throttled.RetryAfter)
+		case errors.Is(err, auth.ErrInvalidMFAToken):
+			ErrorResponse(w, r, http.StatusUnauthorized, "invalid or expired MFA token")
+		case errors.Is(err, auth.ErrInvalidMFACode):
+			ErrorResponse(w, r, http.StatusUnauthorized, "invalid code")
+		case errors.Is(err, auth.ErrAccountSuspended):
+			ErrorResponse(w, r, http.StatusForbidden, "account is suspended")
+		default:
+			ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	h.loggedIn(w, r, tokens, user, input.UseCookies)
+}
+
+// @Summary      Start TOTP enrollment
+// @Description  Generates a new TOTP secret for the current user and returns it as an otpauth:// URI. Two-factor authentication is only enabled once a first code is confirmed.
+// @Tags         MFA
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {object}  dto.TOTPEnrollmentResponse "TOTP secret generated"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., no user in context, invalid token)"
+// @Failure      409  {object}  map[string]string "Conflict (two-factor authentication already enabled)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /users/me/mfa/totp [post]
+// EnrollTOTP handles requests to start setting up TOTP for the authenticated user.
+func (h *AuthHandler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		ErrorResponse(w, r, http.StatusUnauthorized, "no authenticated user found in context")
+		return
+	}
+
+	enrollment, err := h.authService.EnrollTOTP(r.Context(), user)
+	if err != nil {
+		mfaErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.TOTPEnrollmentResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.URI,
+	})
+}
+
+// @Summary      Confirm TOTP enrollment
+// @Description  Enables two-factor authentication after checking a first code from the authenticator app. Returns ten single use recovery codes, which are only shown once.
+// @Tags         MFA
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body dto.MFACodeRequest true "TOTP code"
+// @Success      200  {object}  dto.RecoveryCodesResponse "Two-factor authentication enabled"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., no user in context, invalid token)"
+// @Failure      409  {object}  map[string]string "Conflict (already enabled or enrollment not started)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error or invalid code)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /users/me/mfa/totp/confirm [post]
+// ConfirmTOTP handles requests to finish setting up TOTP for the authenticated user.
+func (h *AuthHandler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		ErrorResponse(w, r, http.StatusUnauthorized, "no authenticated user found in context")
+		return
+	}
+
+	var input dto.MFACodeRequest
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	codes, err := h.authService.ConfirmTOTP(r.Context(), user, input.Code)
+	if err != nil {
+		mfaErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// @Summary      Disable TOTP
+// @Description  Turns off two-factor authentication for the current user and discards their recovery codes. Requires a current TOTP code or an unused recovery code.
+// @Tags         MFA
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body dto.MFACodeRequest true "TOTP or recovery code"
+// @Success      204  "Two-factor authentication disabled"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., no user in context, invalid token)"
+// @Failure      409  {object}  map[string]string "Conflict (two-factor authentication not enabled)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error or invalid code)"
+// @Failure      429  {object}  map[string]string "Too many wrong codes for this account or client; see the Retry-After header"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /users/me/mfa/totp/disable [post]
+// DisableTOTP handles requests to turn off TOTP for the authenticated user.
+func (h *AuthHandler) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		ErrorResponse(w, r, http.StatusUnauthorized, "no authenticated user found in context")
+		return
+	}
+
+	var input dto.MFACodeRequest
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	if err := h.authService.DisableTOTP(r.Context(), user, input.Code, clientInfo(r)); err != nil {
+		mfaErrorResponse(w, r, err)
+		return
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
+
+// mfaErrorResponse maps MFA management errors to HTTP responses.
+func mfaErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	var throttled *auth.ThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		TooManyRequestsResponse(w, r, throttled.RetryAfter)
+	case errors.Is(err, auth.ErrInvalidMFACode):
+		ErrorResponse(w, r, http.StatusUnprocessableEntity, map[string]string{"code": "invalid code"})
+	case errors.Is(err, auth.ErrMFAAlreadyEnabled), errors.Is(err, auth.ErrMFANotEnrolled):
+		ErrorResponse(w, r, http.StatusConflict, err.Error())
+	default:
+		ServerErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-api-structure/internal/api/dto"
+	"go-api-structure/internal/auth"
+	"go-api-structure/internal/oauthclient"
+	"go-api-structure/internal/store/db"
+)
+
+// OAuth error codes (RFC 6749 section 5.2).
+const (
+	oauthInvalidRequest       = "invalid_request"
+	oauthInvalidClient        = "invalid_client"
+	oauthUnsupportedGrantType = "unsupported_grant_type"
+	oauthInvalidScope         = "invalid_scope"
+)
+
+// OAuthHandler holds dependencies for the OAuth 2.0 endpoints machine clients use.
+// Unlike the rest of the API, they take form encoded requests and answer errors in the
+// format of RFC 6749, so that standard OAuth libraries can talk to them.
+type OAuthHandler struct {
+	authService   *auth.AuthService
+	clientService oauthclient.ServiceInterface
+}
+
+// NewOAuthHandler creates a new OAuthHandler with the given services.
+func NewOAuthHandler(authService *auth.AuthService, clientService oauthclient.ServiceInterface) *OAuthHandler {
+	return &OAuthHandler{
+		authService:   authService,
+		clientService: clientService,
+	}
+}
+
+// Token handles requests to the token endpoint (RFC 6749 section 3.2).
+// Only the client credentials grant is supported: the client authenticates with its ID and
+// secret and gets an access token for the requested scopes, or all its scopes if scope is omitted.
+func (h *OAuthHandler) Token(w http.ResponseWriter, r *http.Request) {
+	if !parseOAuthForm(w, r) {
+		return
+	}
+	client, ok := h.authenticateClient(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.PostForm.Get("grant_type") {
+	case "client_credentials":
+	case "":
+		oauthErrorResponse(w, r, http.StatusBadRequest, oauthInvalidRequest, "grant_type is required")
+		return
+	default:
+		oauthErrorResponse(w, r, http.StatusBadRequest, oauthUnsupportedGrantType, "only the client_credentials grant is supported")
+		return
+	}
+
+	token, err := h.authService.IssueClientToken(client, strings.Fields(r.PostForm.Get("scope")))
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidScope) {
+			oauthErrorResponse(w, r, http.StatusBadRequest, oauthInvalidScope, err.Error())
+			return
+		}
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	encode(w, r, http.StatusOK, dto.OAuthTokenResponse{
+		AccessToken: token.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(time.Until(token.Claims.ExpiresAt.Time).Round(time.Second).Seconds()),
+		Scope:       token.Claims.Scope,
+	})
+}
+
+// Introspect handles token introspection requests (RFC 7662) from registered clients,
+// typically other services that were handed an access token and need to know whether it is
+// still valid. Access tokens of users and of clients can be introspected.
+func (h *OAuthHandler) Introspect(w http.ResponseWriter, r *http.Request) {
+	if !parseOAuthForm(w, r) {
+		return
+	}
+	if _, ok := h.authenticateClient(w, r); !ok {
+		return
+	}
+	token := r.PostForm.Get("token")
+	if token == "" {
+		oauthErrorResponse(w, r, http.StatusBadRequest, oauthInvalidRequest, "token is required")
+		return
+	}
+
+	result, err := h.authService.IntrospectToken(r.Context(), token)
+	if err != nil {
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	resp := dto.TokenIntrospectionResponse{Active: result.Active}
+	if result.Active {
+		claims := result.Claims
+		resp.Scope = claims.Scope
+		resp.ClientID = claims.ClientID
+		resp.Username = result.Username
+		resp.TokenType = "Bearer"
+		resp.ExpiresAt = claims.ExpiresAt.Unix()
+		resp.IssuedAt = claims.IssuedAt.Unix()
+		if claims.NotBefore != nil {
+			resp.NotBefore = claims.NotBefore.Unix()
+		}
+		resp.Subject = claims.Subject
+		resp.Audience = claims.Audience
+		resp.Issuer = claims.Issuer
+		resp.JTI = claims.ID
+		if claims.Act != nil {
+			resp.Act = &dto.Act{Subject: claims.Act.Subject}
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	encode(w, r, http.StatusOK, resp)
+}
+
+// Revoke handles token revocation requests (RFC 7009). A client can revoke the access tokens
+// it obtained; the answer is the same for tokens that are invalid or not its own.
+// token_type_hint is ignored, as only access tokens are issued to clients.
+func (h *OAuthHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if !parseOAuthForm(w, r) {
+		return
+	}
+	client, ok := h.authenticateClient(w, r)
+	if !ok {
+		return
+	}
+	token := r.PostForm.Get("token")
+	if token == "" {
+		oauthErrorResponse(w, r, http.StatusBadRequest, oauthInvalidRequest, "token is required")
+		return
+	}
+
+	if err := h.authService.RevokeClientToken(r.Context(), client, token); err != nil {
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// authenticateClient authenticates the calling client with HTTP Basic authentication
+// (client_secret_basic) or with client_id and client_secret form parameters (client_secret_post).
+// It writes an error response and returns false on failure.
+func (h *OAuthHandler) authenticateClient(w http.ResponseWriter, r *http.Request) (*db.OauthClient, bool) {
+	clientID, secret, basic := r.BasicAuth()
+	if basic {
+		if r.PostForm.Has("client_secret") {
+			oauthErrorResponse(w, r, http.StatusBadRequest, oauthInvalidRequest, "only one client authentication method may be used")
+			return nil, false
+		}
+		// RFC 6749 section 2.3.1 has both parts form encoded before they are joined.
+		var errID, errSecret error
+		clientID, errID = url.QueryUnescape(clientID)
+		secret, errSecret = url.QueryUnescape(secret)
+		if errID != nil || errSecret != nil {
+			clientID, secret = "", ""
+		}
+	} else {
+		clientID = r.PostForm.Get("client_id")
+		secret = r.PostForm.Get("client_secret")
+	}
+
+	var client *db.OauthClient
+	err := oauthclient.ErrInvalidClient
+	if clientID != "" && secret != "" {
+		client, err = h.clientService.Authenticate(r.Context(), clientID, secret)
+	}
+	if err != nil {
+		if errors.Is(err, oauthclient.ErrInvalidClient) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+			oauthErrorResponse(w, r, http.StatusUnauthorized, oauthInvalidClient, "client authentication failed")
+			return nil, false
+		}
+		ServerErrorResponse(w, r, err)
+		return nil, false
+	}
+	return client, true
+}
+
+// parseOAuthForm reads the form encoded request body the OAuth endpoints take.
+// It writes an error response and returns false if the body cannot be read
+// or repeats a parameter, which RFC 6749 section 3.2 forbids.
+func parseOAuthForm(w http.ResponseWriter, r *http.Request) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestSize)
+	if err := r.ParseForm(); err != nil {
+		oauthErrorResponse(w, r, http.StatusBadRequest, oauthInvalidRequest, "the body must be form encoded")
+		return false
+	}
+	for name, values := range r.PostForm {
+		if len(values) > 1 {
+			oauthErrorResponse(w, r, http.StatusBadRequest, oauthInvalidRequest, name+" must not be repeated")
+			return false
+		}
+	}
+	return true
+}
+
+// oauthErrorResponse writes an error in the format of RFC 6749 section 5.2.
+func oauthErrorResponse(w http.ResponseWriter, r *http.Request, status int, code, description string) {
+	w.Header().Set("Cache-Control", "no-store")
+	encode(w, r, status, dto.OAuthErrorResponse{Error: code, ErrorDescription: description})
+}
@@ -0,0 +1,243 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"go-api-structure/internal/api/dto"
+	"go-api-structure/internal/audit"
+	"go-api-structure/internal/oauthclient"
+)
+
+// OAuthClientHandler holds dependencies for the HTTP handlers administrators use to manage OAuth clients.
+type OAuthClientHandler struct {
+	clientService oauthclient.ServiceInterface
+}
+
+// NewOAuthClientHandler creates a new OAuthClientHandler with the given OAuth client service.
+func NewOAuthClientHandler(clientService oauthclient.ServiceInterface) *OAuthClientHandler {
+	return &OAuthClientHandler{clientService: clientService}
+}
+
+// @Summary      List OAuth clients
+// @Description  Lists the registered OAuth clients, newest first, including revoked ones. Secrets are never returned. Requires the clients:read permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {array}   dto.OAuthClientResponse "Successfully retrieved OAuth clients"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the clients:read permission)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/oauth-clients [get]
+// ListOAuthClients handles requests to list OAuth clients.
+func (h *OAuthClientHandler) ListOAuthClients(w http.ResponseWriter, r *http.Request) {
+	clients, err := h.clientService.List(r.Context())
+	if err != nil {
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewOAuthClientListResponse(clients))
+}
+
+// @Summary      Register an OAuth client
+// @Description  Registers a client that can obtain access tokens at /oauth/token with the client credentials grant. Scopes are the most its tokens can carry. The client secret is only returned in this response. Requires the clients:write permission.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body dto.CreateOAuthClientRequest true "Client name and allowed scopes"
+// @Success      201  {object}  dto.OAuthClientSecretResponse "Successfully registered OAuth client"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the clients:write permission)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/oauth-clients [post]
+// CreateOAuthClient handles requests to register an OAuth client.
+func (h *OAuthClientHandler) CreateOAuthClient(w http.ResponseWriter, r *http.Request) {
+	actor, ok := currentActor(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.CreateOAuthClientRequest
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	client, secret, err := h.clientService.Create(r.Context(), actor, oauthclient.CreateParams{
+		Name:   input.Name,
+		Scopes: input.Scopes,
+	})
+	if err != nil {
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusCreated, dto.OAuthClientSecretResponse{
+		ClientID:     client.ID.String(),
+		ClientSecret: secret,
+		Client:       dto.NewOAuthClientResponse(client),
+	})
+}
+
+// @Summary      Get an OAuth client
+// @Description  Retrieves a registered OAuth client. Requires the clients:read permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Client ID (UUID format)"
+// @Success      200  {object}  dto.OAuthClientResponse "Successfully retrieved OAuth client"
+// @Failure      400  {object}  map[string]string "Invalid client ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the clients:read permission)"
+// @Failure      404  {object}  map[string]string "OAuth client not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/oauth-clients/{id} [get]
+// GetOAuthClient handles requests for a single OAuth client.
+func (h *OAuthClientHandler) GetOAuthClient(w http.ResponseWriter, r *http.Request) {
+	clientID, ok := parseIDParam(w, r, "id", "Invalid client ID format")
+	if !ok {
+		return
+	}
+
+	client, err := h.clientService.Get(r.Context(), clientID)
+	if err != nil {
+		oauthClientErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewOAuthClientResponse(client))
+}
+
+// @Summary      Update an OAuth client
+// @Description  Renames an OAuth client or replaces its allowed scopes. Tokens already issued keep their scopes until they expire. Revoked clients cannot be changed. Requires the clients:write permission.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Client ID (UUID format)"
+// @Param        request body dto.UpdateOAuthClientRequest true "Fields to change"
+// @Success      200  {object}  dto.OAuthClientResponse "Successfully updated OAuth client"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON or ID)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the clients:write permission)"
+// @Failure      404  {object}  map[string]string "OAuth client not found"
+// @Failure      409  {object}  map[string]string "Conflict (client is revoked)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/oauth-clients/{id} [patch]
+// UpdateOAuthClient handles requests to change an OAuth client.
+func (h *OAuthClientHandler) UpdateOAuthClient(w http.ResponseWriter, r *http.Request) {
+	actor, clientID, ok := actorAndClientID(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.UpdateOAuthClientRequest
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	client, err := h.clientService.Update(r.Context(), actor, clientID, oauthclient.UpdateParams{
+		Name:   input.Name,
+		Scopes: input.Scopes,
+	})
+	if err != nil {
+		oauthClientErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewOAuthClientResponse(client))
+}
+
+// @Summary      Rotate an OAuth client secret
+// @Description  Issues a new secret for an OAuth client. The old secret stops working immediately; tokens already issued stay valid until they expire. The new secret is only returned in this response. Requires the clients:write permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Client ID (UUID format)"
+// @Success      200  {object}  dto.OAuthClientSecretResponse "Successfully rotated client secret"
+// @Failure      400  {object}  map[string]string "Invalid client ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the clients:write permission)"
+// @Failure      404  {object}  map[string]string "OAuth client not found"
+// @Failure      409  {object}  map[string]string "Conflict (client is revoked)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/oauth-clients/{id}/secret [post]
+// RotateOAuthClientSecret handles requests to replace an OAuth client's secret.
+func (h *OAuthClientHandler) RotateOAuthClientSecret(w http.ResponseWriter, r *http.Request) {
+	actor, clientID, ok := actorAndClientID(w, r)
+	if !ok {
+		return
+	}
+
+	client, secret, err := h.clientService.RotateSecret(r.Context(), actor, clientID)
+	if err != nil {
+		oauthClientErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.OAuthClientSecretResponse{
+		ClientID:     client.ID.String(),
+		ClientSecret: secret,
+		Client:       dto.NewOAuthClientResponse(client),
+	})
+}
+
+// @Summary      Revoke an OAuth client
+// @Description  Permanently disables an OAuth client: it can no longer obtain tokens and the tokens it holds are rejected immediately. The client remains listed. Requires the clients:write permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Client ID (UUID format)"
+// @Success      204  "Successfully revoked OAuth client"
+// @Failure      400  {object}  map[string]string "Invalid client ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the clients:write permission)"
+// @Failure      404  {object}  map[string]string "OAuth client not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/oauth-clients/{id} [delete]
+// RevokeOAuthClient handles requests to revoke an OAuth client.
+func (h *OAuthClientHandler) RevokeOAuthClient(w http.ResponseWriter, r *http.Request) {
+	actor, clientID, ok := actorAndClientID(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := h.clientService.Revoke(r.Context(), actor, clientID); err != nil {
+		oauthClientErrorResponse(w, r, err)
+		return
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
+
+// actorAndClientID identifies the administrator making the request and extracts the ID of the
+// OAuth client they act on from the URL. It writes an error response and returns false on failure.
+func actorAndClientID(w http.ResponseWriter, r *http.Request) (audit.Actor, uuid.UUID, bool) {
+	actor, ok := currentActor(w, r)
+	if !ok {
+		return audit.Actor{}, uuid.Nil, false
+	}
+	clientID, ok := parseIDParam(w, r, "id", "Invalid client ID format")
+	if !ok {
+		return audit.Actor{}, uuid.Nil, false
+	}
+	return actor, clientID, true
+}
+
+// oauthClientErrorResponse maps OAuth client service errors to HTTP responses.
+func oauthClientErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, oauthclient.ErrClientNotFound):
+		ErrorResponse(w, r, http.StatusNotFound, "OAuth client not found")
+	case errors.Is(err, oauthclient.ErrClientRevoked):
+		ErrorResponse(w, r, http.StatusConflict, err.Error())
+	default:
+		ServerErrorResponse(w, r, err)
+	}
+}
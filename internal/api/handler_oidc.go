@@ -0,0 +1,111 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"go-api-structure/internal/api/dto"
+	"go-api-structure/internal/auth"
+)
+
+// @Summary      List OpenID Connect providers
+// @Description  Lists the names of the OpenID Connect providers users can sign in with, e.g. to show a button for each.
+// @Tags         Auth
+// @Produce      json
+// @Success      200  {object}  dto.OIDCProvidersResponse "Configured providers"
+// @Router       /auth/oidc/providers [get]
+// ListOIDCProviders handles requests for the configured OpenID Connect providers.
+func (h *AuthHandler) ListOIDCProviders(w http.ResponseWriter, r *http.Request) {
+	encode(w, r, http.StatusOK, dto.OIDCProvidersResponse{Providers: h.authService.OIDCProviders()})
+}
+
+// @Summary      Start an OpenID Connect login
+// @Description  Starts signing in through an OpenID Connect provider with the authorization code flow and PKCE. The front end keeps the returned state and sends the user to authorization_url. The provider redirects back to the configured redirect URL with a code and the state, which are posted to the callback endpoint within ten minutes.
+// @Tags         Auth
+// @Produce      json
+// @Param        provider path string true "Provider name"
+// @Success      200  {object}  dto.OIDCAuthorizationResponse "Login started"
+// @Failure      404  {object}  map[string]string "Not found (unknown provider)"
+// @Failure      500  {object}  map[string]string "Internal server error (e.g., provider unreachable)"
+// @Router       /auth/oidc/{provider}/authorize [post]
+// StartOIDCLogin handles requests to begin a login at an OpenID Connect provider.
+func (h *AuthHandler) StartOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	authorization, err := h.authService.StartOIDCLogin(r.Context(), chi.URLParam(r, "provider"))
+	if err != nil {
+		if errors.Is(err, auth.ErrUnknownOIDCProvider) {
+			ErrorResponse(w, r, http.StatusNotFound, "unknown OpenID Connect provider")
+			return
+		}
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.OIDCAuthorizationResponse{
+		AuthorizationURL: authorization.URL,
+		State:            authorization.State,
+		ExpiresAt:        authorization.ExpiresAt,
+	})
+}
+
+// @Summary      Complete an OpenID Connect login
+// @Description  Exchanges the code and state the provider redirected back with for the same response as the login endpoint. The provider account is linked to the user it signed in before, or else to the user with the same verified email address; without one, a new user without a password is created. Each state can only be used once. use_cookies works as for the login endpoint.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        provider path string true "Provider name"
+// @Param        request body dto.OIDCCallbackRequest true "Authorization code and state"
+// @Success      200  {object}  dto.LoginUserResponse "Successfully logged in"
+// @Success      202  {object}  dto.MFAChallengeResponse "Signed in at the provider; complete the login at /auth/mfa/verify"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
+// @Failure      401  {object}  map[string]string "Unauthorized (invalid or expired state, code rejected by the provider, or invalid ID token)"
+// @Failure      403  {object}  map[string]string "Forbidden (account suspended, or email address not verified by the provider)"
+// @Failure      404  {object}  map[string]string "Not found (unknown provider)"
+// @Failure      409  {object}  map[string]string "Conflict (an account with an unverified email address has the same address)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error, or use_cookies while cookie sessions are disabled)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /auth/oidc/{provider}/callback [post]
+// CompleteOIDCLogin handles the return from an OpenID Connect provider.
+// It answers like LoginUser.
+func (h *AuthHandler) CompleteOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	var input dto.OIDCCallbackRequest
+
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+	if h.cookiesUnavailable(w, r, input.UseCookies) {
+		return
+	}
+
+	tokens, user, err := h.authService.CompleteOIDCLogin(r.Context(), chi.URLParam(r, "provider"), input.Code, input.State, clientInfo(r))
+	if err != nil {
+		var challenge *auth.MFAChallengeError
+		switch {
+		case errors.As(err, &challenge):
+			encode(w, r, http.StatusAccepted, dto.MFAChallengeResponse{
+				MFARequired: true,
+				MFAToken:    challenge.Token,
+				ExpiresAt:   challenge.ExpiresAt,
+			})
+		case errors.Is(err, auth.ErrUnknownOIDCProvider):
+			ErrorResponse(w, r, http.StatusNotFound, "unknown OpenID Connect provider")
+		case errors.Is(err, auth.ErrInvalidOIDCState):
+			ErrorResponse(w, r, http.StatusUnauthorized, "invalid or expired login state")
+		case errors.Is(err, auth.ErrOIDCLoginFailed):
+			logError(r, "OpenID Connect login failed", err)
+			ErrorResponse(w, r, http.StatusUnauthorized, "the identity provider login could not be verified")
+		case errors.Is(err, auth.ErrOIDCEmailNotVerified):
+			ErrorResponse(w, r, http.StatusForbidden, "the identity provider did not verify your email address")
+		case errors.Is(err, auth.ErrOIDCAccountNotLinkable):
+			ErrorResponse(w, r, http.StatusConflict, "an account with this email address exists; log in with its password and verify the address first")
+		case errors.Is(err, auth.ErrAccountSuspended):
+			ErrorResponse(w, r, http.StatusForbidden, "account is suspended")
+		default:
+			ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	h.loggedIn(w, r, tokens, user, input.UseCookies)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"go-api-structure/internal/api/dto"
+	"go-api-structure/internal/auth"
+	"go-api-structure/internal/passwordpolicy"
+)
+
+// @Summary      Request a password reset
+// @Description  Emails a password reset link if the address belongs to an account. The response is the same whether or not it does.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.EmailRequest true "Email address"
+// @Success      202  {object}  dto.MessageResponse "Request accepted"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /auth/password/forgot [post]
+// ForgotPassword handles requests for a password reset link.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var input dto.EmailRequest
+
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	if err := h.authService.ForgotPassword(r.Context(), input.Email); err != nil {
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusAccepted, dto.MessageResponse{
+		Message: "if the address belongs to an account, a password reset link has been sent",
+	})
+}
+
+// @Summary      Reset a password
+// @Description  Sets a new password using the token from a password reset email. Tokens are short-lived and single use. All sessions and API keys of the account are revoked.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        request body dto.ResetPasswordRequest true "Reset token and new password"
+// @Success      204  "Password reset"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON, invalid or expired token)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error, or password rejected by the password policy or reused); the token stays usable"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /auth/password/reset [post]
+// ResetPassword handles requests to set a new password with a reset token.
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var input dto.ResetPasswordRequest
+
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	if err := h.authService.ResetPassword(r.Context(), input.Token, input.Password); err != nil {
+		var rejected *passwordpolicy.Error
+		switch {
+		case errors.Is(err, auth.ErrInvalidPasswordResetToken):
+			ErrorResponse(w, r, http.StatusBadRequest, "invalid or expired password reset token")
+		case errors.As(err, &rejected):
+			FailedValidationResponse(w, r, map[string]string{"password": rejected.Reason})
+		case errors.Is(err, auth.ErrPasswordReused):
+			FailedValidationResponse(w, r, map[string]string{"password": "password must not be one of your recent passwords"})
+		default:
+			ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
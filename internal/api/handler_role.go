@@ -0,0 +1,330 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"go-api-structure/internal/api/dto"
+	"go-api-structure/internal/rbac"
+)
+
+// RoleHandler holds dependencies for role management HTTP handlers.
+type RoleHandler struct {
+	rbacService rbac.ServiceInterface
+}
+
+// NewRoleHandler creates a new RoleHandler with the given rbac service.
+func NewRoleHandler(rbacService rbac.ServiceInterface) *RoleHandler {
+	return &RoleHandler{rbacService: rbacService}
+}
+
+// @Summary      List permissions
+// @Description  Lists every permission that can be granted to a role. Requires the roles:read permission.
+// @Tags         Roles
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {array}   dto.PermissionResponse "Successfully retrieved permissions"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the roles:read permission)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/permissions [get]
+// ListPermissions handles requests to list the permissions roles can grant.
+func (h *RoleHandler) ListPermissions(w http.ResponseWriter, r *http.Request) {
+	permissions, err := h.rbacService.ListPermissions(r.Context())
+	if err != nil {
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewPermissionListResponse(permissions))
+}
+
+// @Summary      List roles
+// @Description  Lists all roles with the permissions they grant. Requires the roles:read permission.
+// @Tags         Roles
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {array}   dto.RoleResponse "Successfully retrieved roles"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the roles:read permission)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/roles [get]
+// ListRoles handles requests to list all roles.
+func (h *RoleHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.rbacService.ListRoles(r.Context())
+	if err != nil {
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	resp := make([]*dto.RoleResponse, 0, len(roles))
+	for i := range roles {
+		resp = append(resp, dto.NewRoleResponse(&roles[i].Role, roles[i].Permissions))
+	}
+	encode(w, r, http.StatusOK, resp)
+}
+
+// @Summary      Create a role
+// @Description  Creates a role granting the given permissions. Requires the roles:write permission.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body dto.CreateRoleRequest true "Role details"
+// @Success      201  {object}  dto.RoleResponse "Successfully created role"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the roles:write permission)"
+// @Failure      409  {object}  map[string]string "Conflict (a role with this name already exists)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/roles [post]
+// CreateRole handles requests to create a role.
+func (h *RoleHandler) CreateRole(w http.ResponseWriter, r *http.Request) {
+	var input dto.CreateRoleRequest
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	role, err := h.rbacService.CreateRole(r.Context(), rbac.CreateRoleParams{
+		Name:        input.Name,
+		Description: input.Description,
+		Permissions: input.Permissions,
+	})
+	if err != nil {
+		roleErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusCreated, dto.NewRoleResponse(&role.Role, role.Permissions))
+}
+
+// @Summary      Get a role
+// @Description  Retrieves a role with the permissions it grants. Requires the roles:read permission.
+// @Tags         Roles
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Role ID (UUID format)"
+// @Success      200  {object}  dto.RoleResponse "Successfully retrieved role"
+// @Failure      400  {object}  map[string]string "Invalid role ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the roles:read permission)"
+// @Failure      404  {object}  map[string]string "Role not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/roles/{id} [get]
+// GetRole handles requests for a single role.
+func (h *RoleHandler) GetRole(w http.ResponseWriter, r *http.Request) {
+	roleID, ok := parseIDParam(w, r, "id", "Invalid role ID format")
+	if !ok {
+		return
+	}
+
+	role, err := h.rbacService.GetRole(r.Context(), roleID)
+	if err != nil {
+		roleErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewRoleResponse(&role.Role, role.Permissions))
+}
+
+// @Summary      Update a role
+// @Description  Renames a role, changes its description and/or replaces its permissions. Built-in roles only allow changing the description. Requires the roles:write permission.
+// @Tags         Roles
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Role ID (UUID format)"
+// @Param        request body dto.UpdateRoleRequest true "Fields to update"
+// @Success      200  {object}  dto.RoleResponse "Successfully updated role"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON or ID)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the roles:write permission)"
+// @Failure      404  {object}  map[string]string "Role not found"
+// @Failure      409  {object}  map[string]string "Conflict (name taken or built-in role)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/roles/{id} [patch]
+// UpdateRole handles requests to update a role.
+func (h *RoleHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	roleID, ok := parseIDParam(w, r, "id", "Invalid role ID format")
+	if !ok {
+		return
+	}
+
+	var input dto.UpdateRoleRequest
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	role, err := h.rbacService.UpdateRole(r.Context(), roleID, rbac.UpdateRoleParams{
+		Name:        input.Name,
+		Description: input.Description,
+		Permissions: input.Permissions,
+	})
+	if err != nil {
+		roleErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewRoleResponse(&role.Role, role.Permissions))
+}
+
+// @Summary      Delete a role
+// @Description  Deletes a role and takes it away from every user that has it. Built-in roles cannot be deleted. Requires the roles:write permission.
+// @Tags         Roles
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Role ID (UUID format)"
+// @Success      204  "Successfully deleted role"
+// @Failure      400  {object}  map[string]string "Invalid role ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the roles:write permission)"
+// @Failure      404  {object}  map[string]string "Role not found"
+// @Failure      409  {object}  map[string]string "Conflict (built-in role)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/roles/{id} [delete]
+// DeleteRole handles requests to delete a role.
+func (h *RoleHandler) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	roleID, ok := parseIDParam(w, r, "id", "Invalid role ID format")
+	if !ok {
+		return
+	}
+
+	if err := h.rbacService.DeleteRole(r.Context(), roleID); err != nil {
+		roleErrorResponse(w, r, err)
+		return
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
+
+// @Summary      List a user's roles
+// @Description  Lists the roles assigned to a user. Requires the roles:read permission.
+// @Tags         Roles
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "User ID (UUID format)"
+// @Success      200  {array}   dto.RoleResponse "Successfully retrieved roles"
+// @Failure      400  {object}  map[string]string "Invalid user ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the roles:read permission)"
+// @Failure      404  {object}  map[string]string "User not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/users/{id}/roles [get]
+// ListUserRoles handles requests to list the roles of a user.
+func (h *RoleHandler) ListUserRoles(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseIDParam(w, r, "id", "Invalid user ID format")
+	if !ok {
+		return
+	}
+
+	roles, err := h.rbacService.ListUserRoles(r.Context(), userID)
+	if err != nil {
+		roleErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewUserRoleListResponse(roles))
+}
+
+// @Summary      Assign a role to a user
+// @Description  Gives a user a role. Assigning a role the user already has succeeds without changes. Requires the roles:write permission.
+// @Tags         Roles
+// @Produce      json
+// @Security     Bearer
+// @Param        id      path      string  true  "User ID (UUID format)"
+// @Param        roleID  path      string  true  "Role ID (UUID format)"
+// @Success      204  "Successfully assigned role"
+// @Failure      400  {object}  map[string]string "Invalid user or role ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the roles:write permission)"
+// @Failure      404  {object}  map[string]string "User or role not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/users/{id}/roles/{roleID} [put]
+// AssignRole handles requests to give a user a role.
+func (h *RoleHandler) AssignRole(w http.ResponseWriter, r *http.Request) {
+	userID, roleID, ok := h.userAndRoleID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.rbacService.AssignRole(r.Context(), userID, roleID); err != nil {
+		roleErrorResponse(w, r, err)
+		return
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
+
+// @Summary      Take a role away from a user
+// @Description  Removes a role from a user. The admin role cannot be taken away from its last holder. Requires the roles:write permission.
+// @Tags         Roles
+// @Produce      json
+// @Security     Bearer
+// @Param        id      path      string  true  "User ID (UUID format)"
+// @Param        roleID  path      string  true  "Role ID (UUID format)"
+// @Success      204  "Successfully removed role"
+// @Failure      400  {object}  map[string]string "Invalid user or role ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the roles:write permission)"
+// @Failure      404  {object}  map[string]string "Role not found or not assigned to the user"
+// @Failure      409  {object}  map[string]string "Conflict (last user with the admin role)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/users/{id}/roles/{roleID} [delete]
+// UnassignRole handles requests to take a role away from a user.
+func (h *RoleHandler) UnassignRole(w http.ResponseWriter, r *http.Request) {
+	userID, roleID, ok := h.userAndRoleID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.rbacService.UnassignRole(r.Context(), userID, roleID); err != nil {
+		roleErrorResponse(w, r, err)
+		return
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
+
+// userAndRoleID extracts the user ID and the role ID from the URL.
+// It writes an error response and returns false if either is malformed.
+func (h *RoleHandler) userAndRoleID(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	userID, ok := parseIDParam(w, r, "id", "Invalid user ID format")
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	roleID, ok := parseIDParam(w, r, "roleID", "Invalid role ID format")
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, roleID, true
+}
+
+// parseIDParam parses the UUID in the named URL parameter.
+// It writes a 400 response with message and returns false if it is malformed.
+func parseIDParam(w http.ResponseWriter, r *http.Request, name, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, name))
+	if err != nil {
+		ErrorResponse(w, r, http.StatusBadRequest, message)
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// roleErrorResponse maps rbac service errors to HTTP responses.
+func roleErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, rbac.ErrRoleNotFound):
+		ErrorResponse(w, r, http.StatusNotFound, "Role not found")
+	case errors.Is(err, rbac.ErrUserNotFound):
+		ErrorResponse(w, r, http.StatusNotFound, "User not found")
+	case errors.Is(err, rbac.ErrRoleExists), errors.Is(err, rbac.ErrBuiltinRole), errors.Is(err, rbac.ErrLastAdmin):
+		ErrorResponse(w, r, http.StatusConflict, err.Error())
+	default:
+		ServerErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,311 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"go-api-structure/internal/api/dto"
+	"go-api-structure/internal/apikey"
+	"go-api-structure/internal/audit"
+	"go-api-structure/internal/auth"
+	"go-api-structure/internal/serviceaccount"
+)
+
+// ServiceAccountHandler holds dependencies for the service account HTTP handlers.
+type ServiceAccountHandler struct {
+	accountService serviceaccount.ServiceInterface
+}
+
+// NewServiceAccountHandler creates a new ServiceAccountHandler with the given service account service.
+func NewServiceAccountHandler(accountService serviceaccount.ServiceInterface) *ServiceAccountHandler {
+	return &ServiceAccountHandler{accountService: accountService}
+}
+
+// @Summary      Get current service account
+// @Description  Retrieves the service account the API key belongs to. Keys of users are refused, so integrations can check that they are not running with a person's key. On the TLS listener, a registered client certificate can be used instead of an API key.
+// @Tags         Service Accounts
+// @Produce      json
+// @Security     APIKey
+// @Success      200  {object}  dto.ServiceAccountResponse "Successfully retrieved service account"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid, expired or revoked API key)"
+// @Failure      403  {object}  map[string]string "Forbidden (the API key belongs to a user)"
+// @Router       /service-accounts/me [get]
+// GetMe handles requests for the service account the request is authenticated as.
+func (h *ServiceAccountHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	account := auth.GetServiceAccountFromContext(r.Context())
+	if account == nil {
+		if auth.GetPrincipalFromContext(r.Context()) != nil {
+			ErrorResponse(w, r, http.StatusForbidden, "only service accounts can use this endpoint; users can use /users/me")
+			return
+		}
+		ErrorResponse(w, r, http.StatusUnauthorized, "no authenticated service account found in context")
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewServiceAccountResponse(account))
+}
+
+// @Summary      List service accounts
+// @Description  Lists service accounts, sorted by name. Requires the service_accounts:read permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {array}   dto.ServiceAccountResponse "Successfully retrieved service accounts"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the service_accounts:read permission)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/service-accounts [get]
+// ListServiceAccounts handles requests to list service accounts.
+func (h *ServiceAccountHandler) ListServiceAccounts(w http.ResponseWriter, r *http.Request) {
+	accounts, err := h.accountService.List(r.Context())
+	if err != nil {
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewServiceAccountListResponse(accounts))
+}
+
+// @Summary      Create a service account
+// @Description  Creates a service account for a bot or integration. Service accounts cannot log in; issue an API key for them to authenticate. Requires the service_accounts:write permission.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body dto.CreateServiceAccountRequest true "Service account name and description"
+// @Success      201  {object}  dto.ServiceAccountResponse "Successfully created service account"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the service_accounts:write permission)"
+// @Failure      409  {object}  map[string]string "Conflict (name already taken)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/service-accounts [post]
+// CreateServiceAccount handles requests to create a service account.
+func (h *ServiceAccountHandler) CreateServiceAccount(w http.ResponseWriter, r *http.Request) {
+	actor, ok := currentActor(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.CreateServiceAccountRequest
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	account, err := h.accountService.Create(r.Context(), actor, serviceaccount.CreateParams{
+		Name:        input.Name,
+		Description: input.Description,
+	})
+	if err != nil {
+		serviceAccountErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusCreated, dto.NewServiceAccountResponse(account))
+}
+
+// @Summary      Get a service account
+// @Description  Retrieves a service account with the metadata of its API keys. Requires the service_accounts:read permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Service account ID (UUID format)"
+// @Success      200  {object}  dto.ServiceAccountDetailsResponse "Successfully retrieved service account"
+// @Failure      400  {object}  map[string]string "Invalid service account ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the service_accounts:read permission)"
+// @Failure      404  {object}  map[string]string "Service account not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/service-accounts/{id} [get]
+// GetServiceAccount handles requests for a single service account.
+func (h *ServiceAccountHandler) GetServiceAccount(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := parseIDParam(w, r, "id", "Invalid service account ID format")
+	if !ok {
+		return
+	}
+
+	details, err := h.accountService.Get(r.Context(), accountID)
+	if err != nil {
+		serviceAccountErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewServiceAccountDetailsResponse(&details.ServiceAccount, details.APIKeys))
+}
+
+// @Summary      Update a service account
+// @Description  Renames a service account or changes its description. Requires the service_accounts:write permission.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Service account ID (UUID format)"
+// @Param        request body dto.UpdateServiceAccountRequest true "Fields to change"
+// @Success      200  {object}  dto.ServiceAccountResponse "Successfully updated service account"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON or ID)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the service_accounts:write permission)"
+// @Failure      404  {object}  map[string]string "Service account not found"
+// @Failure      409  {object}  map[string]string "Conflict (name already taken)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/service-accounts/{id} [patch]
+// UpdateServiceAccount handles requests to change a service account.
+func (h *ServiceAccountHandler) UpdateServiceAccount(w http.ResponseWriter, r *http.Request) {
+	actor, accountID, ok := actorAndServiceAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.UpdateServiceAccountRequest
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	account, err := h.accountService.Update(r.Context(), actor, accountID, serviceaccount.UpdateParams{
+		Name:        input.Name,
+		Description: input.Description,
+	})
+	if err != nil {
+		serviceAccountErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewServiceAccountResponse(account))
+}
+
+// @Summary      Delete a service account
+// @Description  Permanently deletes a service account together with its API keys, which stop working immediately. Requires the service_accounts:write permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Service account ID (UUID format)"
+// @Success      204  "Successfully deleted service account"
+// @Failure      400  {object}  map[string]string "Invalid service account ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the service_accounts:write permission)"
+// @Failure      404  {object}  map[string]string "Service account not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/service-accounts/{id} [delete]
+// DeleteServiceAccount handles requests to delete a service account.
+func (h *ServiceAccountHandler) DeleteServiceAccount(w http.ResponseWriter, r *http.Request) {
+	actor, accountID, ok := actorAndServiceAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.accountService.Delete(r.Context(), actor, accountID); err != nil {
+		serviceAccountErrorResponse(w, r, err)
+		return
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
+
+// @Summary      Create a service account API key
+// @Description  Issues an API key for a service account. The key is only returned in this response. Its scopes also act as the account's permissions, since service accounts have no roles. Requires the service_accounts:write permission.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Service account ID (UUID format)"
+// @Param        request body dto.CreateAPIKeyRequest true "API key details"
+// @Success      201  {object}  dto.CreateAPIKeyResponse "Successfully created API key"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON or ID)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the service_accounts:write permission)"
+// @Failure      404  {object}  map[string]string "Service account not found"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/service-accounts/{id}/api-keys [post]
+// CreateServiceAccountAPIKey handles requests to issue an API key for a service account.
+func (h *ServiceAccountHandler) CreateServiceAccountAPIKey(w http.ResponseWriter, r *http.Request) {
+	actor, accountID, ok := actorAndServiceAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.CreateAPIKeyRequest
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	apiKey, rawKey, err := h.accountService.CreateAPIKey(r.Context(), actor, accountID, apikey.CreateParams{
+		Name:      input.Name,
+		Scopes:    input.Scopes,
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		serviceAccountErrorResponse(w, r, err)
+		return
+	}
+
+	encode(w, r, http.StatusCreated, dto.CreateAPIKeyResponse{
+		Key:    rawKey,
+		APIKey: dto.NewAPIKeyResponse(apiKey),
+	})
+}
+
+// @Summary      Revoke a service account API key
+// @Description  Revokes one of a service account's API keys. Revoked keys are rejected immediately but remain listed. Requires the service_accounts:write permission.
+// @Tags         Admin
+// @Produce      json
+// @Security     Bearer
+// @Param        id     path      string  true  "Service account ID (UUID format)"
+// @Param        keyID  path      string  true  "API key ID (UUID format)"
+// @Success      204  "Successfully revoked API key"
+// @Failure      400  {object}  map[string]string "Invalid service account or API key ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (missing the service_accounts:write permission)"
+// @Failure      404  {object}  map[string]string "Service account or API key not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /admin/service-accounts/{id}/api-keys/{keyID} [delete]
+// RevokeServiceAccountAPIKey handles requests to revoke an API key of a service account.
+func (h *ServiceAccountHandler) RevokeServiceAccountAPIKey(w http.ResponseWriter, r *http.Request) {
+	actor, accountID, ok := actorAndServiceAccountID(w, r)
+	if !ok {
+		return
+	}
+	keyID, ok := parseIDParam(w, r, "keyID", "Invalid API key ID format")
+	if !ok {
+		return
+	}
+
+	if _, err := h.accountService.RevokeAPIKey(r.Context(), actor, accountID, keyID); err != nil {
+		serviceAccountErrorResponse(w, r, err)
+		return
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
+
+// actorAndServiceAccountID identifies the administrator making the request and extracts the ID of the
+// service account they act on from the URL. It writes an error response and returns false on failure.
+func actorAndServiceAccountID(w http.ResponseWriter, r *http.Request) (audit.Actor, uuid.UUID, bool) {
+	actor, ok := currentActor(w, r)
+	if !ok {
+		return audit.Actor{}, uuid.Nil, false
+	}
+	accountID, ok := parseIDParam(w, r, "id", "Invalid service account ID format")
+	if !ok {
+		return audit.Actor{}, uuid.Nil, false
+	}
+	return actor, accountID, true
+}
+
+// serviceAccountErrorResponse maps service account service errors to HTTP responses.
+func serviceAccountErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, serviceaccount.ErrServiceAccountNotFound):
+		ErrorResponse(w, r, http.StatusNotFound, "Service account not found")
+	case errors.Is(err, serviceaccount.ErrAPIKeyNotFound):
+		ErrorResponse(w, r, http.StatusNotFound, "API key not found")
+	case errors.Is(err, serviceaccount.ErrNameTaken):
+		ErrorResponse(w, r, http.StatusConflict, err.Error())
+	default:
+		ServerErrorResponse(w, r, err)
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"go-api-structure/internal/api/dto"
+	"go-api-structure/internal/auth"
+)
+
+// @Summary      List sessions
+// @Description  Lists the devices the current user is logged in on, most recently used first: each login starts a session that lasts until logout or until it times out. The session the request was made with is marked as current.
+// @Tags         Users
+// @Produce      json
+// @Security     Bearer
+// @Success      200  {array}   dto.SessionResponse "Successfully retrieved sessions"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., no user in context, invalid token)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /users/me/sessions [get]
+// ListSessions handles requests to list the current user's sessions.
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		ErrorResponse(w, r, http.StatusUnauthorized, "no authenticated user found in context")
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(r.Context(), user.ID)
+	if err != nil {
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	var currentID uuid.UUID
+	if claims := auth.GetClaimsFromContext(r.Context()); claims != nil {
+		currentID, _ = uuid.Parse(claims.SessionID)
+	}
+	encode(w, r, http.StatusOK, dto.NewSessionListResponse(sessions, currentID))
+}
+
+// @Summary      Delete a session
+// @Description  Logs the current user out of one of their sessions, e.g. on a lost device. Its refresh tokens are revoked and its access tokens are rejected from then on.
+// @Tags         Users
+// @Produce      json
+// @Security     Bearer
+// @Param        id   path      string  true  "Session ID (UUID format)"
+// @Success      204  "Successfully deleted session"
+// @Failure      400  {object}  map[string]string "Invalid session ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., no user in context, invalid token)"
+// @Failure      404  {object}  map[string]string "Session not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /users/me/sessions/{id} [delete]
+// DeleteSession handles requests to log the current user out of one session.
+func (h *AuthHandler) DeleteSession(w http.ResponseWriter, r *http.Request) {
+	user := auth.GetUserFromContext(r.Context())
+	if user == nil {
+		ErrorResponse(w, r, http.StatusUnauthorized, "no authenticated user found in context")
+		return
+	}
+	sessionID, ok := parseIDParam(w, r, "id", "Invalid session ID format")
+	if !ok {
+		return
+	}
+
+	if err := h.authService.DeleteSession(r.Context(), user.ID, sessionID); err != nil {
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			ErrorResponse(w, r, http.StatusNotFound, "Session not found")
+			return
+		}
+		ServerErrorResponse(w, r, err)
+		return
+	}
+
+	encode[any](w, r, http.StatusNoContent, nil)
+}
+
+// clientInfo describes the device a login request comes from, to be stored with its session.
+func clientInfo(r *http.Request) auth.ClientInfo {
+	return auth.ClientInfo{IP: clientIP(r), UserAgent: r.UserAgent()}
+}
@@ -32,6 +32,7 @@ func NewUserHandler(userService user.ServiceInterface) *UserHandler { // Changed
 // @Security     Bearer
 // @Success      200  {object}  dto.UserResponse "Successfully retrieved user details"
 // @Failure      401  {object}  map[string]string "Unauthorized (e.g., no user in context, invalid token)"
+// @Failure      403  {object}  map[string]string "Forbidden (the request is authenticated as an OAuth client or service account, not a user)"
 // @Failure      500  {object}  map[string]string "Internal server error"
 // @Router       /users/me [get]
 // GetMe handles requests for the authenticated user's details.
@@ -39,6 +40,11 @@ func NewUserHandler(userService user.ServiceInterface) *UserHandler { // Changed
 func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 	user := auth.GetUserFromContext(r.Context())
 	if user == nil {
+		if principal := auth.GetPrincipalFromContext(r.Context()); principal != nil {
+			// Machines authenticate without a user behind them, so there is no profile to return.
+			ErrorResponse(w, r, http.StatusForbidden, "this endpoint is only available to users, not to principals of kind "+string(principal.Kind))
+			return
+		}
 		// This case should ideally be prevented by the auth middleware.
 		// If it occurs, it implies a misconfiguration or an issue with the middleware chain.
 		ErrorResponse(w, r, http.StatusUnauthorized, "no authenticated user found in context")
@@ -49,25 +55,63 @@ func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 	encode(w, r, http.StatusOK, userResponse)
 }
 
+// @Summary      Update current user's profile
+// @Description  Changes the username of the currently authenticated user.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Security     Bearer
+// @Param        request body dto.UpdateUserRequest true "Profile fields to update"
+// @Success      200  {object}  dto.UserResponse "Successfully updated user"
+// @Failure      400  {object}  map[string]string "Bad request (e.g., malformed JSON)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., no user in context, invalid token)"
+// @Failure      409  {object}  map[string]string "Conflict (username already taken)"
+// @Failure      422  {object}  map[string]string "Unprocessable entity (validation error)"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Router       /users/me [patch]
+// UpdateMe handles requests to update the authenticated user's profile.
+func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	currentUser := auth.GetUserFromContext(r.Context())
+	if currentUser == nil {
+		ErrorResponse(w, r, http.StatusUnauthorized, "no authenticated user found in context")
+		return
+	}
+
+	var input dto.UpdateUserRequest
+	if !decodeAndValidate(w, r, &input) {
+		return // Errors handled by decodeAndValidate
+	}
+
+	updatedUser, err := h.userService.UpdateUsername(r.Context(), currentUser.ID, input.Username)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrUsernameTaken):
+			ErrorResponse(w, r, http.StatusConflict, "a user with this username already exists")
+		default:
+			ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	encode(w, r, http.StatusOK, dto.NewUserResponse(updatedUser))
+}
+
 // @Summary      Get user details by ID
-// @Description  Retrieves the details of a user by their ID.
+// @Description  Retrieves the details of a user by their ID. The API key needs the users:read scope and its owner the users:read permission. On the TLS listener, a registered client certificate with the users:read scope can be used instead of an API key.
 // @Tags         Users
 // @Produce      json
 // @Param        id   path      string  true  "User ID (UUID format)"
 // @Security     APIKey
 // @Success      200  {object}  dto.UserResponse "Successfully retrieved user details"
 // @Failure      400  {object}  map[string]string "Invalid user ID format"
-// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid API key)"
+// @Failure      401  {object}  map[string]string "Unauthorized (e.g., invalid, expired or revoked API key)"
+// @Failure      403  {object}  map[string]string "Forbidden (API key lacks the users:read scope or its owner the users:read permission)"
 // @Failure      404  {object}  map[string]string "User not found"
 // @Failure      500  {object}  map[string]string "Internal server error"
 // @Router       /users/{id} [get]
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
-	// The APIKeyMiddleware should have already authenticated the request.
-	// We don't need to re-check the API key here.
-	// We can optionally retrieve the authenticated user from context if needed for authorization,
-	// e.g., if only admins can fetch any user, or users can only fetch themselves.
-	// For now, let's assume if the API key is valid, the request is allowed to proceed
-	// to fetch the user specified by the path ID.
+	// The APIKeyMiddleware has authenticated the request and RequirePermission has checked
+	// that the key's owner may read other users, so any user can be fetched here.
 
 	userIDStr := chi.URLParam(r, "id")
 	userID, err := uuid.Parse(userIDStr)
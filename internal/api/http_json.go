@@ -6,8 +6,12 @@ import (
 	"fmt" // For methodNotAllowedResponse
 	"io"  // For io.EOF and io.ErrUnexpectedEOF
 	"log/slog"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
 	"strings" // For checking unknown field errors
+	"time"
 )
 
 // encode writes a JSON response with the given status code and data.
@@ -147,6 +151,15 @@ func ServerErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
 	ErrorResponse(w, r, http.StatusInternalServerError, message)
 }
 
+// logError logs an error that is not reported to the client, using the request's logger.
+func logError(r *http.Request, msg string, err error) {
+	logger, _ := r.Context().Value(GetLoggerKey()).(*slog.Logger)
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Error(msg, "error", err.Error())
+}
+
 // BadRequestResponse sends a 400 Bad Request response.
 func BadRequestResponse(w http.ResponseWriter, r *http.Request, err error) {
 	ErrorResponse(w, r, http.StatusBadRequest, err.Error())
@@ -169,6 +182,24 @@ func FailedValidationResponse(w http.ResponseWriter, r *http.Request, errors map
 	ErrorResponse(w, r, http.StatusUnprocessableEntity, errors)
 }
 
+// TooManyRequestsResponse sends a 429 Too Many Requests response with a Retry-After header
+// telling the client how many seconds to wait, rounded up.
+func TooManyRequestsResponse(w http.ResponseWriter, r *http.Request, retryAfter time.Duration) {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	ErrorResponse(w, r, http.StatusTooManyRequests, "too many failed attempts, try again later")
+}
+
+// clientIP returns the client's IP address. Behind a proxy it relies on middleware.RealIP
+// having replaced r.RemoteAddr with the address from X-Forwarded-For or X-Real-IP.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr // RealIP stores a bare address
+	}
+	return host
+}
+
 // TODO: Add more specific error responses as needed (e.g., authentication errors).
 
 // decodeAndValidate decodes the JSON request body into dst and then validates dst.
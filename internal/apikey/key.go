@@ -0,0 +1,66 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+)
+
+// KeyPrefix marks every API key issued by this service so leaked keys are easy
+// to recognise, e.g. by secret scanners. A full key looks like
+// gas_live_<key id>_<secret>.
+const KeyPrefix = "gas_live_"
+
+const (
+	keyIDBytes  = 8  // 16 hex characters, public and used for lookups
+	secretBytes = 32 // 64 hex characters of entropy
+)
+
+// generateKey returns a new raw key together with its public key ID.
+func generateKey() (rawKey, keyID string, err error) {
+	id, err := randomHex(keyIDBytes)
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := randomHex(secretBytes)
+	if err != nil {
+		return "", "", err
+	}
+	return KeyPrefix + id + "_" + secret, id, nil
+}
+
+// parseKeyID extracts the key ID from a raw key.
+// It returns false for keys that are not in the gas_live_ format.
+func parseKeyID(rawKey string) (string, bool) {
+	rest, ok := strings.CutPrefix(rawKey, KeyPrefix)
+	if !ok {
+		return "", false
+	}
+	id, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(id) != keyIDBytes*2 || len(secret) != secretBytes*2 {
+		return "", false
+	}
+	return id, true
+}
+
+// hashKey returns the hex encoded SHA-256 digest of a raw key.
+// Keys carry enough entropy that a fast hash is sufficient.
+func hashKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// keyMatches reports whether rawKey hashes to the stored digest, in constant time.
+func keyMatches(rawKey, keyHash string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashKey(rawKey)), []byte(keyHash)) == 1
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,296 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+var (
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	ErrAPIKeyExpired = errors.New("API key has expired")
+	ErrAPIKeyRevoked = errors.New("API key has been revoked")
+	ErrAPIKeyRotated = errors.New("API key has already been rotated")
+)
+
+// DefaultKeyName is the name of the key carried over from when every user had a single API key.
+// Rotating it keeps the name, so the replacement becomes the new default key.
+const DefaultKeyName = "default"
+
+// CreateParams holds the user supplied settings for a new API key.
+type CreateParams struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time // Optional; nil means the key never expires
+}
+
+// UpdateParams holds the fields of an API key that can be changed. Nil fields are left unchanged.
+type UpdateParams struct {
+	Name   *string
+	Scopes []string
+}
+
+// ServiceInterface defines the operations for managing and authenticating API keys.
+type ServiceInterface interface {
+	// Create issues a new key and returns it together with the raw key.
+	// Only a digest of the key is stored, so this is the only time the raw key is available.
+	Create(ctx context.Context, userID uuid.UUID, params CreateParams) (*db.ApiKey, string, error)
+	List(ctx context.Context, userID uuid.UUID) ([]db.ApiKey, error)
+	Get(ctx context.Context, userID, id uuid.UUID) (*db.ApiKey, error)
+	Update(ctx context.Context, userID, id uuid.UUID, params UpdateParams) (*db.ApiKey, error)
+	Revoke(ctx context.Context, userID, id uuid.UUID) (*db.ApiKey, error)
+	// RevokeAll revokes every key the user owns, e.g. after their password was reset.
+	RevokeAll(ctx context.Context, userID uuid.UUID) error
+	// Rotate replaces a key with a new one carrying the same name, scopes and expiry.
+	// The old key keeps working for the rotation grace period. It returns the new key,
+	// its raw value and the old key.
+	Rotate(ctx context.Context, userID, id uuid.UUID) (*db.ApiKey, string, *db.ApiKey, error)
+	// RotateDefault rotates the user's default key, see DefaultKeyName.
+	RotateDefault(ctx context.Context, userID uuid.UUID) (*db.ApiKey, string, *db.ApiKey, error)
+	// CreateForServiceAccount, ListForServiceAccount and RevokeForServiceAccount manage the keys
+	// of a service account; they work like Create, List and Revoke do for a user's keys.
+	CreateForServiceAccount(ctx context.Context, serviceAccountID uuid.UUID, params CreateParams) (*db.ApiKey, string, error)
+	ListForServiceAccount(ctx context.Context, serviceAccountID uuid.UUID) ([]db.ApiKey, error)
+	RevokeForServiceAccount(ctx context.Context, serviceAccountID, id uuid.UUID) (*db.ApiKey, error)
+	// Authenticate resolves a raw key to its record, rejecting unknown, expired and revoked keys.
+	Authenticate(ctx context.Context, rawKey string) (*db.ApiKey, error)
+}
+
+// Service provides API key operations.
+type Service struct {
+	apiKeyStore         store.APIKeyStore
+	rotationGracePeriod time.Duration
+}
+
+// NewService creates a new API key Service.
+// rotationGracePeriod is how long a rotated key keeps working after its replacement was issued.
+func NewService(apiKeyStore store.APIKeyStore, rotationGracePeriod time.Duration) *Service {
+	return &Service{
+		apiKeyStore:         apiKeyStore,
+		rotationGracePeriod: rotationGracePeriod,
+	}
+}
+
+// Create issues a new API key for the user.
+func (s *Service) Create(ctx context.Context, userID uuid.UUID, params CreateParams) (*db.ApiKey, string, error) {
+	return s.create(ctx, db.CreateAPIKeyParams{UserID: ownerID(userID)}, params)
+}
+
+// CreateForServiceAccount issues a new API key for the service account.
+func (s *Service) CreateForServiceAccount(ctx context.Context, serviceAccountID uuid.UUID, params CreateParams) (*db.ApiKey, string, error) {
+	return s.create(ctx, db.CreateAPIKeyParams{ServiceAccountID: ownerID(serviceAccountID)}, params)
+}
+
+// create issues a new API key for the owner set in arg.
+func (s *Service) create(ctx context.Context, arg db.CreateAPIKeyParams, params CreateParams) (*db.ApiKey, string, error) {
+	rawKey, keyID, err := generateKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+
+	var expiresAt pgtype.Timestamptz
+	if params.ExpiresAt != nil {
+		expiresAt = pgtype.Timestamptz{Time: *params.ExpiresAt, Valid: true}
+	}
+
+	arg.Name = params.Name
+	arg.KeyID = pgtype.Text{String: keyID, Valid: true}
+	arg.KeyHash = hashKey(rawKey)
+	arg.Scopes = normalizeScopes(params.Scopes)
+	arg.ExpiresAt = expiresAt
+
+	apiKey, err := s.apiKeyStore.CreateAPIKey(ctx, arg)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	return &apiKey, rawKey, nil
+}
+
+// List returns all of the user's API keys, newest first.
+func (s *Service) List(ctx context.Context, userID uuid.UUID) ([]db.ApiKey, error) {
+	return s.apiKeyStore.ListAPIKeysForUser(ctx, ownerID(userID))
+}
+
+// ListForServiceAccount returns all of the service account's API keys, newest first.
+func (s *Service) ListForServiceAccount(ctx context.Context, serviceAccountID uuid.UUID) ([]db.ApiKey, error) {
+	return s.apiKeyStore.ListAPIKeysForServiceAccount(ctx, ownerID(serviceAccountID))
+}
+
+// Get returns one of the user's API keys.
+func (s *Service) Get(ctx context.Context, userID, id uuid.UUID) (*db.ApiKey, error) {
+	apiKey, err := s.apiKeyStore.GetAPIKeyForUser(ctx, db.GetAPIKeyForUserParams{ID: id, UserID: ownerID(userID)})
+	if err != nil {
+		return nil, err // Error handling (e.g., store.ErrNotFound) is done in the store layer
+	}
+	return &apiKey, nil
+}
+
+// Update renames a key or replaces its scopes.
+func (s *Service) Update(ctx context.Context, userID, id uuid.UUID, params UpdateParams) (*db.ApiKey, error) {
+	arg := db.UpdateAPIKeyParams{ID: id, UserID: ownerID(userID)}
+	if params.Name != nil {
+		arg.Name = pgtype.Text{String: *params.Name, Valid: true}
+	}
+	if params.Scopes != nil {
+		arg.Scopes = normalizeScopes(params.Scopes)
+	}
+
+	apiKey, err := s.apiKeyStore.UpdateAPIKey(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// Revoke permanently disables a key. The record is kept so it still shows up in listings.
+func (s *Service) Revoke(ctx context.Context, userID, id uuid.UUID) (*db.ApiKey, error) {
+	apiKey, err := s.apiKeyStore.RevokeAPIKey(ctx, db.RevokeAPIKeyParams{ID: id, UserID: ownerID(userID)})
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// RevokeForServiceAccount permanently disables one of the service account's keys.
+func (s *Service) RevokeForServiceAccount(ctx context.Context, serviceAccountID, id uuid.UUID) (*db.ApiKey, error) {
+	apiKey, err := s.apiKeyStore.RevokeServiceAccountAPIKey(ctx, db.RevokeServiceAccountAPIKeyParams{
+		ID:               id,
+		ServiceAccountID: ownerID(serviceAccountID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// RevokeAll revokes all of the user's keys.
+func (s *Service) RevokeAll(ctx context.Context, userID uuid.UUID) error {
+	if err := s.apiKeyStore.RevokeUserAPIKeys(ctx, ownerID(userID)); err != nil {
+		return fmt.Errorf("failed to revoke API keys: %w", err)
+	}
+	return nil
+}
+
+// Rotate issues a replacement for one of the user's keys.
+// The old key's expiry is moved to the end of the grace period (unless it already expires earlier)
+// so integrations using it keep working while they switch to the new key.
+func (s *Service) Rotate(ctx context.Context, userID, id uuid.UUID) (*db.ApiKey, string, *db.ApiKey, error) {
+	oldKey, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	switch {
+	case oldKey.Revoked:
+		return nil, "", nil, ErrAPIKeyRevoked
+	case oldKey.ReplacedBy.Valid:
+		return nil, "", nil, ErrAPIKeyRotated
+	case oldKey.ExpiresAt.Valid && time.Now().After(oldKey.ExpiresAt.Time):
+		return nil, "", nil, ErrAPIKeyExpired
+	}
+
+	params := CreateParams{Name: oldKey.Name, Scopes: oldKey.Scopes}
+	if oldKey.ExpiresAt.Valid {
+		params.ExpiresAt = &oldKey.ExpiresAt.Time
+	}
+	newKey, rawKey, err := s.Create(ctx, userID, params)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	rotated, err := s.apiKeyStore.MarkAPIKeyRotated(ctx, db.MarkAPIKeyRotatedParams{
+		ReplacedBy:  pgtype.UUID{Bytes: newKey.ID, Valid: true},
+		GraceEndsAt: pgtype.Timestamptz{Time: time.Now().Add(s.rotationGracePeriod), Valid: true},
+		ID:          oldKey.ID,
+		UserID:      ownerID(userID),
+	})
+	if err != nil {
+		// The key was rotated or revoked concurrently; don't leave a second replacement behind.
+		if _, revokeErr := s.Revoke(ctx, userID, newKey.ID); revokeErr != nil {
+			return nil, "", nil, fmt.Errorf("failed to revoke replacement API key: %w", revokeErr)
+		}
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, "", nil, ErrAPIKeyRotated
+		}
+		return nil, "", nil, fmt.Errorf("failed to rotate API key: %w", err)
+	}
+
+	return newKey, rawKey, &rotated, nil
+}
+
+// RotateDefault rotates the user's newest default key that has not been revoked or rotated yet.
+// It returns store.ErrNotFound if there is none.
+func (s *Service) RotateDefault(ctx context.Context, userID uuid.UUID) (*db.ApiKey, string, *db.ApiKey, error) {
+	apiKeys, err := s.List(ctx, userID)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	for _, apiKey := range apiKeys {
+		if apiKey.Name == DefaultKeyName && !apiKey.Revoked && !apiKey.ReplacedBy.Valid {
+			return s.Rotate(ctx, userID, apiKey.ID)
+		}
+	}
+	return nil, "", nil, store.ErrNotFound
+}
+
+// Authenticate looks up a raw key and checks that it is still usable.
+// Keys are found by their public key ID and then verified against the stored digest.
+func (s *Service) Authenticate(ctx context.Context, rawKey string) (*db.ApiKey, error) {
+	var apiKey db.ApiKey
+	var err error
+	if keyID, ok := parseKeyID(rawKey); ok {
+		apiKey, err = s.apiKeyStore.GetAPIKeyByKeyID(ctx, pgtype.Text{String: keyID, Valid: true})
+	} else {
+		// Keys issued before the gas_live_ format have no key ID.
+		apiKey, err = s.apiKeyStore.GetAPIKeyByHash(ctx, hashKey(rawKey))
+	}
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	if !keyMatches(rawKey, apiKey.KeyHash) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if apiKey.Revoked {
+		return nil, ErrAPIKeyRevoked
+	}
+	if apiKey.ExpiresAt.Valid && time.Now().After(apiKey.ExpiresAt.Time) {
+		return nil, ErrAPIKeyExpired
+	}
+
+	if err := s.apiKeyStore.TouchAPIKey(ctx, apiKey.ID); err != nil {
+		return nil, fmt.Errorf("failed to record API key usage: %w", err)
+	}
+
+	return &apiKey, nil
+}
+
+// HasScope reports whether the key was granted the given scope.
+func HasScope(apiKey *db.ApiKey, scope string) bool {
+	return slices.Contains(apiKey.Scopes, scope)
+}
+
+// ownerID converts the ID of a key's owner to the type of the owner columns,
+// which are nullable since a key belongs to either a user or a service account.
+func ownerID(id uuid.UUID) pgtype.UUID {
+	return pgtype.UUID{Bytes: id, Valid: true}
+}
+
+// normalizeScopes sorts scopes and removes duplicates so stored keys compare predictably.
+func normalizeScopes(scopes []string) []string {
+	normalized := slices.Clone(scopes)
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
@@ -1,7 +1,16 @@
 package auth
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/apikey"
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
 )
 
 const (
@@ -9,27 +18,113 @@ const (
 )
 
 // APIKeyMiddleware creates a middleware that authenticates requests using an API key.
-// It expects the AuthService to have a UserService instance provided to it.
-func (s *AuthService) APIKeyMiddleware(errorFunc func(w http.ResponseWriter, r *http.Request, statusCode int, message any)) func(next http.Handler) http.Handler {
+// Keys that are unknown, revoked or expired are rejected with 401, and keys lacking
+// any of the requiredScopes or belonging to a suspended user are rejected with 403.
+// Keys of service accounts put the service account in the context instead of a user.
+// Instead of a key, OAuth clients may send an access token from the client credentials grant
+// as a bearer token; it must carry the requiredScopes just the same.
+func (s *AuthService) APIKeyMiddleware(errorFunc func(w http.ResponseWriter, r *http.Request, statusCode int, message any), requiredScopes ...string) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			apiKey := r.Header.Get(APIKeyHeader)
-			if apiKey == "" {
+			rawKey := r.Header.Get(APIKeyHeader)
+			if rawKey == "" {
+				if scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " "); strings.EqualFold(scheme, "bearer") && token != "" {
+					s.serveClientToken(w, r, next, errorFunc, token, requiredScopes)
+					return
+				}
 				errorFunc(w, r, http.StatusUnauthorized, "API key required")
 				return
 			}
 
-			dbUser, err := s.userService.GetUserByAPIKey(r.Context(), apiKey)
+			apiKey, err := s.apiKeyService.Authenticate(r.Context(), rawKey)
 			if err != nil {
-				// Consider logging the error for internal review: log.Printf("API key validation error: %v", err)
-				// For the client, it's an invalid API key.
-				errorFunc(w, r, http.StatusUnauthorized, "Invalid API key")
+				switch {
+				case errors.Is(err, apikey.ErrAPIKeyExpired):
+					errorFunc(w, r, http.StatusUnauthorized, "API key has expired")
+				case errors.Is(err, apikey.ErrAPIKeyRevoked):
+					errorFunc(w, r, http.StatusUnauthorized, "API key has been revoked")
+				case errors.Is(err, apikey.ErrInvalidAPIKey):
+					errorFunc(w, r, http.StatusUnauthorized, "Invalid API key")
+				default:
+					errorFunc(w, r, http.StatusInternalServerError, "Failed to validate API key")
+				}
 				return
 			}
 
-			// Add user to context using the existing mechanism (assuming ContextSetUser is available)
-			ctxWithUser := ContextSetUser(r.Context(), dbUser)
-			next.ServeHTTP(w, r.WithContext(ctxWithUser))
+			for _, required := range requiredScopes {
+				if !apikey.HasScope(apiKey, required) {
+					errorFunc(w, r, http.StatusForbidden, "API key is missing required scope: "+required)
+					return
+				}
+			}
+
+			ctx, ok := s.apiKeyOwnerContext(w, r, errorFunc, apiKey)
+			if !ok {
+				return
+			}
+			ctx = ContextSetAPIKey(ctx, apiKey)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// apiKeyOwnerContext loads the user or service account that owns an authenticated API key.
+// On success it returns the request context with the owner added; otherwise it renders an error and returns false.
+func (s *AuthService) apiKeyOwnerContext(w http.ResponseWriter, r *http.Request, errorFunc func(w http.ResponseWriter, r *http.Request, statusCode int, message any), apiKey *db.ApiKey) (context.Context, bool) {
+	return s.credentialOwnerContext(w, r, errorFunc, apiKey.UserID, apiKey.ServiceAccountID, "API key")
+}
+
+// credentialOwnerContext loads the user or service account, whichever ID is valid, that owns a
+// credential such as an API key or client certificate. Owners that no longer exist make the
+// credential invalid and suspended users are refused. credential names it in error messages.
+func (s *AuthService) credentialOwnerContext(w http.ResponseWriter, r *http.Request, errorFunc func(w http.ResponseWriter, r *http.Request, statusCode int, message any), userID, serviceAccountID pgtype.UUID, credential string) (context.Context, bool) {
+	if serviceAccountID.Valid {
+		account, err := s.serviceAccountStore.GetServiceAccount(r.Context(), serviceAccountID.Bytes)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				errorFunc(w, r, http.StatusUnauthorized, "Invalid "+credential)
+				return nil, false
+			}
+			errorFunc(w, r, http.StatusInternalServerError, "Failed to validate "+credential)
+			return nil, false
+		}
+		return ContextSetServiceAccount(r.Context(), &account), true
+	}
+
+	dbUser, err := s.userStore.GetUserByID(r.Context(), userID.Bytes)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			errorFunc(w, r, http.StatusUnauthorized, "Invalid "+credential)
+			return nil, false
+		}
+		errorFunc(w, r, http.StatusInternalServerError, "Failed to validate "+credential)
+		return nil, false
+	}
+	if dbUser.SuspendedAt.Valid {
+		errorFunc(w, r, http.StatusForbidden, "account is suspended")
+		return nil, false
+	}
+	return ContextSetUser(r.Context(), &dbUser), true
+}
+
+// serveClientToken authenticates a request to an API key route with a client access token.
+func (s *AuthService) serveClientToken(w http.ResponseWriter, r *http.Request, next http.Handler, errorFunc func(w http.ResponseWriter, r *http.Request, statusCode int, message any), token string, requiredScopes []string) {
+	claims, err := s.parseClaims(token, TokenTypeClientAccess)
+	if err != nil {
+		errorFunc(w, r, http.StatusUnauthorized, tokenErrorMessage(err))
+		return
+	}
+
+	for _, required := range requiredScopes {
+		if !claims.HasScope(required) {
+			errorFunc(w, r, http.StatusForbidden, "token is missing required scope: "+required)
+			return
+		}
+	}
+
+	ctx, ok := s.authenticateClientToken(w, r, errorFunc, claims)
+	if !ok {
+		return
+	}
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Token types, carried in the token_type claim.
+// Checking the type stops a token minted for one purpose from being replayed for another.
+const (
+	TokenTypeAccess            = "access"
+	TokenTypeMFAPending        = "mfa_pending" // Password accepted, second factor outstanding
+	TokenTypeEmailVerification = "email_verification"
+	TokenTypeClientAccess      = "client_access" // Issued to an OAuth client, not a user
+)
+
+// Authentication methods, carried in the amr claim (RFC 8176).
+const (
+	AuthMethodPassword     = "pwd"
+	AuthMethodOTP          = "otp" // TOTP code
+	AuthMethodRecoveryCode = "rec" // Single use recovery code; not registered in RFC 8176
+	AuthMethodMFA          = "mfa"
+	AuthMethodFederated    = "fed" // Signed in at an OpenID Connect provider; not registered in RFC 8176
+)
+
+var ErrWrongTokenType = errors.New("token has the wrong type")
+
+// Claims is the set of claims carried by tokens issued by AuthService.
+type Claims struct {
+	jwt.RegisteredClaims
+	TokenType   string   `json:"token_type"`
+	AuthMethods []string `json:"amr,omitempty"`
+	Scope       string   `json:"scope,omitempty"`     // Space separated, as in RFC 8693
+	Roles       []string `json:"roles,omitempty"`     // Names of the user's roles when an access token was issued
+	Email       string   `json:"email,omitempty"`     // Address a verification token was sent to
+	ClientID    string   `json:"client_id,omitempty"` // OAuth client a client access token was issued to (RFC 9068)
+	Act         *Act     `json:"act,omitempty"`       // Administrator impersonating the subject (RFC 8693)
+	SessionID   string   `json:"sid,omitempty"`       // Session an access token belongs to
+}
+
+// Act identifies the party acting on behalf of a token's subject (RFC 8693 section 4.1).
+type Act struct {
+	Subject string `json:"sub"`
+}
+
+// Impersonated reports whether the token was issued to an administrator acting as its subject.
+func (c *Claims) Impersonated() bool {
+	return c.Act != nil
+}
+
+// Scopes returns the scopes granted to the token.
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// HasScope reports whether the token was granted the given scope.
+func (c *Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes(), scope)
+}
+
+// HasRole reports whether the token carries the given role. Roles are a snapshot taken when the
+// token was issued; RequirePermission checks the user's current permissions instead.
+func (c *Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+// newClaims returns claims for a token of the given type, valid for ttl from now.
+func (s *AuthService) newClaims(subject, tokenType string, ttl time.Duration) *Claims {
+	now := time.Now()
+	return &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(), // Unique token ID, used for revocation
+			Subject:   subject,
+			Issuer:    s.issuer,
+			Audience:  jwt.ClaimStrings{s.audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		TokenType: tokenType,
+	}
+}
+
+// parseClaims verifies a token's signature, issuer, audience and lifetime
+// and checks that it is of one of the expected types.
+func (s *AuthService) parseClaims(tokenString string, tokenTypes ...string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := s.parser.ParseWithClaims(tokenString, claims, s.keys.Keyfunc)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	if !slices.Contains(tokenTypes, claims.TokenType) {
+		return nil, fmt.Errorf("%w: expected %q, got %q", ErrWrongTokenType, tokenTypes, claims.TokenType)
+	}
+	return claims, nil
+}
+
+// newParser builds the JWT parser that enforces the configured validation rules.
+func newParser(issuer, audience string, leeway time.Duration) *jwt.Parser {
+	return jwt.NewParser(
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(audience),
+		jwt.WithLeeway(leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+}
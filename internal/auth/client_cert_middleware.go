@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"go-api-structure/internal/clientcert"
+)
+
+// HasClientCertificate reports whether the request arrived on the TLS listener with a client
+// certificate that was verified against the configured CA bundle.
+func HasClientCertificate(r *http.Request) bool {
+	return r.TLS != nil && len(r.TLS.VerifiedChains) > 0
+}
+
+// ClientCertMiddleware creates a middleware that authenticates requests using the client certificate
+// presented to the TLS listener. The TLS layer has already checked that it was issued by a trusted
+// CA; the certificate must also match a registration by its public key fingerprint or subject.
+// Requests without a verified certificate or matching no registration are rejected with 401, and
+// certificates lacking any of the requiredScopes or belonging to a suspended user with 403.
+// Like API keys, certificates put either a user or a service account in the context.
+func (s *AuthService) ClientCertMiddleware(errorFunc func(w http.ResponseWriter, r *http.Request, statusCode int, message any), requiredScopes ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !HasClientCertificate(r) || s.clientCertificates == nil {
+				errorFunc(w, r, http.StatusUnauthorized, "client certificate required")
+				return
+			}
+
+			certificate, err := s.clientCertificates.Authenticate(r.Context(), r.TLS.PeerCertificates[0])
+			if err != nil {
+				if errors.Is(err, clientcert.ErrUnknownCertificate) {
+					errorFunc(w, r, http.StatusUnauthorized, "client certificate is not registered")
+				} else {
+					errorFunc(w, r, http.StatusInternalServerError, "Failed to validate client certificate")
+				}
+				return
+			}
+
+			for _, required := range requiredScopes {
+				if !clientcert.HasScope(certificate, required) {
+					errorFunc(w, r, http.StatusForbidden, "client certificate is missing required scope: "+required)
+					return
+				}
+			}
+
+			ctx, ok := s.credentialOwnerContext(w, r, errorFunc, certificate.UserID, certificate.ServiceAccountID, "client certificate")
+			if !ok {
+				return
+			}
+			ctx = ContextSetClientCertificate(ctx, certificate)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// APIKeyOrClientCertMiddleware authenticates requests that present a verified client certificate
+// with ClientCertMiddleware and all others with APIKeyMiddleware, requiring the same scopes either way.
+// It lets machine clients on the TLS listener use mutual TLS wherever they could use an API key.
+func (s *AuthService) APIKeyOrClientCertMiddleware(errorFunc func(w http.ResponseWriter, r *http.Request, statusCode int, message any), requiredScopes ...string) func(next http.Handler) http.Handler {
+	withAPIKey := s.APIKeyMiddleware(errorFunc, requiredScopes...)
+	withClientCert := s.ClientCertMiddleware(errorFunc, requiredScopes...)
+	return func(next http.Handler) http.Handler {
+		apiKeyHandler, clientCertHandler := withAPIKey(next), withClientCert(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if HasClientCertificate(r) {
+				clientCertHandler.ServeHTTP(w, r)
+				return
+			}
+			apiKeyHandler.ServeHTTP(w, r)
+		})
+	}
+}
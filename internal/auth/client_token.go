@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+var (
+	// ErrInvalidScope is returned by IssueClientToken when a client asks for a scope it was not granted.
+	ErrInvalidScope = errors.New("requested scope is not allowed for this client")
+	// ErrClientRevoked is returned for tokens of clients that were revoked since the token was issued.
+	ErrClientRevoked = errors.New("client has been revoked")
+)
+
+// ClientToken is an access token issued to an OAuth client with the client credentials grant.
+type ClientToken struct {
+	AccessToken string
+	Claims      *Claims
+}
+
+// TokenIntrospection describes an access token as the introspection endpoint reports it (RFC 7662).
+// Only Active is meaningful for tokens that are not active.
+type TokenIntrospection struct {
+	Active bool
+	Claims *Claims
+	// Username is set for tokens issued to users.
+	Username string
+}
+
+// IssueClientToken issues an access token for a client that has already been authenticated.
+// requestedScopes must be a subset of the client's scopes; if none are requested, the token
+// carries all of them.
+func (s *AuthService) IssueClientToken(client *db.OauthClient, requestedScopes []string) (*ClientToken, error) {
+	granted := client.Scopes
+	if len(requestedScopes) > 0 {
+		for _, requested := range requestedScopes {
+			if !slices.Contains(client.Scopes, requested) {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidScope, requested)
+			}
+		}
+		granted = slices.Compact(slices.Sorted(slices.Values(requestedScopes)))
+	}
+
+	claims := s.newClaims(client.ID.String(), TokenTypeClientAccess, s.clientTokenExpiry)
+	claims.ClientID = client.ID.String()
+	claims.Scope = strings.Join(granted, " ")
+
+	signedToken, err := s.keys.Sign(claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return &ClientToken{AccessToken: signedToken, Claims: claims}, nil
+}
+
+// IntrospectToken reports whether an access token, issued to a user or to a client, is currently
+// accepted, and if so what it carries. Tokens that are malformed, expired, revoked, whose session
+// has ended or whose user or client can no longer authenticate are reported as inactive.
+// Introspection does not count as a use of the session, so a resource server polling for a
+// token does not keep the session from timing out.
+func (s *AuthService) IntrospectToken(ctx context.Context, tokenString string) (*TokenIntrospection, error) {
+	inactive := &TokenIntrospection{}
+
+	claims, err := s.parseClaims(tokenString, TokenTypeAccess, TokenTypeClientAccess)
+	if err != nil {
+		return inactive, nil
+	}
+	revoked, err := s.isRevoked(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return inactive, nil
+	}
+
+	if claims.TokenType == TokenTypeClientAccess {
+		if _, err := s.tokenClient(ctx, claims); err != nil {
+			if errors.Is(err, ErrClientRevoked) {
+				return inactive, nil
+			}
+			return nil, err
+		}
+		return &TokenIntrospection{Active: true, Claims: claims}, nil
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return inactive, nil
+	}
+	user, err := s.userStore.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return inactive, nil
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.SuspendedAt.Valid || issuedBeforeRevocation(claims, &user) {
+		return inactive, nil
+	}
+	if err := s.tokenSession(ctx, claims, user.ID, s.liveSession); err != nil {
+		if errors.Is(err, ErrSessionNotFound) || errors.Is(err, ErrSessionExpired) || errors.Is(err, errSessionMismatch) {
+			return inactive, nil
+		}
+		return nil, err
+	}
+	if claims.Impersonated() {
+		if _, err := s.tokenImpersonator(ctx, claims); err != nil {
+			if errors.Is(err, ErrImpersonatorInvalid) {
+				return inactive, nil
+			}
+			return nil, err
+		}
+	}
+	return &TokenIntrospection{Active: true, Claims: claims, Username: user.Username}, nil
+}
+
+// RevokeClientToken revokes an access token the client obtained (RFC 7009). Tokens that are
+// invalid, already expired or were issued to someone else are left alone without an error,
+// so the answer does not tell the client anything about them.
+func (s *AuthService) RevokeClientToken(ctx context.Context, client *db.OauthClient, tokenString string) error {
+	claims, err := s.parseClaims(tokenString, TokenTypeClientAccess)
+	if err != nil || claims.Subject != client.ID.String() {
+		return nil
+	}
+	return s.revokeClientAccessToken(ctx, claims)
+}
+
+// revokeClientAccessToken adds a client access token to the revocation list.
+func (s *AuthService) revokeClientAccessToken(ctx context.Context, claims *Claims) error {
+	clientID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return fmt.Errorf("invalid subject claim: %w", err)
+	}
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return fmt.Errorf("invalid jti claim: %w", err)
+	}
+	if claims.ExpiresAt == nil {
+		return errors.New("token has no expiry")
+	}
+
+	err = s.revokedTokenStore.RevokeClientToken(ctx, db.RevokeClientTokenParams{
+		Jti:       tokenID,
+		ClientID:  pgtype.UUID{Bytes: clientID, Valid: true},
+		ExpiresAt: pgtype.Timestamptz{Time: claims.ExpiresAt.Time, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke client access token: %w", err)
+	}
+	return nil
+}
+
+// isRevoked reports whether the token's jti is on the revocation list.
+// Tokens without a valid jti count as revoked, since they could never be revoked otherwise.
+func (s *AuthService) isRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return true, nil
+	}
+	revoked, err := s.revokedTokenStore.IsTokenRevoked(ctx, tokenID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	return revoked, nil
+}
+
+// tokenClient returns the client a client access token was issued to,
+// or ErrClientRevoked if the client was revoked or no longer exists.
+func (s *AuthService) tokenClient(ctx context.Context, claims *Claims) (*db.OauthClient, error) {
+	clientID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, ErrClientRevoked
+	}
+	client, err := s.oauthClientStore.GetOAuthClient(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrClientRevoked
+		}
+		return nil, fmt.Errorf("failed to get OAuth client: %w", err)
+	}
+	if client.RevokedAt.Valid {
+		return nil, ErrClientRevoked
+	}
+	return &client, nil
+}
+
+// authenticateClientToken completes authentication with a client access token whose claims have
+// been parsed, checking that neither the token nor its client was revoked. On success it returns
+// the request context with the client and claims added; otherwise it renders an error and returns false.
+func (s *AuthService) authenticateClientToken(w http.ResponseWriter, r *http.Request, errorFunc func(w http.ResponseWriter, r *http.Request, statusCode int, message any), claims *Claims) (context.Context, bool) {
+	revoked, err := s.isRevoked(r.Context(), claims)
+	if err != nil {
+		errorFunc(w, r, http.StatusInternalServerError, "error checking token revocation")
+		return nil, false
+	}
+	if revoked {
+		errorFunc(w, r, http.StatusUnauthorized, "token revoked")
+		return nil, false
+	}
+
+	client, err := s.tokenClient(r.Context(), claims)
+	if err != nil {
+		if errors.Is(err, ErrClientRevoked) {
+			errorFunc(w, r, http.StatusUnauthorized, "client has been revoked")
+		} else {
+			errorFunc(w, r, http.StatusInternalServerError, "error retrieving client")
+		}
+		return nil, false
+	}
+
+	ctx := ContextSetClient(r.Context(), client)
+	return ContextSetClaims(ctx, claims), true
+}
+
+// tokenErrorMessage describes why a token was rejected by parseClaims.
+func tokenErrorMessage(err error) string {
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return "token expired"
+	}
+	return "invalid token"
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestIntrospectTokenSession(t *testing.T) {
+	lastSeen := time.Now().Add(-30 * time.Minute)
+
+	tests := []struct {
+		name       string
+		prepare    func(st *fakeStore, sessionID uuid.UUID)
+		wantActive bool
+	}{
+		{"active", func(*fakeStore, uuid.UUID) {}, true},
+		{"idle", func(st *fakeStore, id uuid.UUID) {
+			session := st.sessions[id]
+			session.LastSeenAt.Time = time.Now().Add(-2 * time.Hour)
+			st.sessions[id] = session
+		}, false},
+		{"past its absolute timeout", func(st *fakeStore, id uuid.UUID) {
+			session := st.sessions[id]
+			session.ExpiresAt.Time = time.Now().Add(-time.Second)
+			st.sessions[id] = session
+		}, false},
+		{"logged out", func(st *fakeStore, id uuid.UUID) {
+			delete(st.sessions, id)
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newFakeStore()
+			s := newTestService(t, st, func(cfg *Config) { cfg.SessionIdleTimeout = time.Hour })
+			_, tokens := loginForTest(t, st, s)
+			claims, err := s.parseClaims(tokens.AccessToken, TokenTypeAccess)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sessionID := uuid.MustParse(claims.SessionID)
+
+			st.mu.Lock()
+			if session, ok := st.sessions[sessionID]; ok {
+				session.LastSeenAt.Time = lastSeen
+				st.sessions[sessionID] = session
+			}
+			tt.prepare(st, sessionID)
+			before, existed := st.sessions[sessionID]
+			st.mu.Unlock()
+
+			got, err := s.IntrospectToken(context.Background(), tokens.AccessToken)
+			if err != nil {
+				t.Fatalf("IntrospectToken() error = %v", err)
+			}
+			if got.Active != tt.wantActive {
+				t.Errorf("IntrospectToken() active = %v, want %v", got.Active, tt.wantActive)
+			}
+
+			// Introspection must neither keep the session alive nor end it.
+			st.mu.Lock()
+			defer st.mu.Unlock()
+			after, exists := st.sessions[sessionID]
+			if exists != existed || !after.LastSeenAt.Time.Equal(before.LastSeenAt.Time) {
+				t.Errorf("session changed by introspection: %+v, want %+v", after, before)
+			}
+			for _, token := range st.refreshTokens {
+				if token.RevokedAt.Valid {
+					t.Error("introspection revoked the session's refresh tokens")
+				}
+			}
+		})
+	}
+}
@@ -13,6 +13,24 @@ type contextKey string
 // userContextKey is the key used to store the authenticated user in the request context.
 const userContextKey = contextKey("user")
 
+// apiKeyContextKey is the key used to store the API key a request was authenticated with.
+const apiKeyContextKey = contextKey("api_key")
+
+// clientCertificateContextKey is the key used to store the client certificate registration a request was authenticated with.
+const clientCertificateContextKey = contextKey("client_certificate")
+
+// serviceAccountContextKey is the key used to store the service account a request was authenticated as.
+const serviceAccountContextKey = contextKey("service_account")
+
+// clientContextKey is the key used to store the OAuth client a request was authenticated as.
+const clientContextKey = contextKey("client")
+
+// impersonatorContextKey is the key used to store the administrator acting as the authenticated user.
+const impersonatorContextKey = contextKey("impersonator")
+
+// claimsContextKey is the key used to store the validated token claims in the request context.
+const claimsContextKey = contextKey("claims")
+
 // ContextSetUser adds the user to the given context with the userContextKey.
 func ContextSetUser(ctx context.Context, user *db.User) context.Context {
 	return context.WithValue(ctx, userContextKey, user)
@@ -27,3 +45,96 @@ func GetUserFromContext(ctx context.Context) *db.User {
 	}
 	return user
 }
+
+// ContextSetClaims adds the validated token claims to the given context.
+func ContextSetClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey, claims)
+}
+
+// GetClaimsFromContext retrieves the validated token claims from the request context.
+// The claims have already been checked for signature, issuer, audience, lifetime and type.
+// It returns nil if the request was not authenticated with a JWT.
+func GetClaimsFromContext(ctx context.Context) *Claims {
+	claims, ok := ctx.Value(claimsContextKey).(*Claims)
+	if !ok {
+		return nil
+	}
+	return claims
+}
+
+// ContextSetAPIKey adds the API key used to authenticate the request to the given context.
+func ContextSetAPIKey(ctx context.Context, apiKey *db.ApiKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey, apiKey)
+}
+
+// GetAPIKeyFromContext retrieves the API key the request was authenticated with.
+// It returns nil if the request was not authenticated with an API key.
+func GetAPIKeyFromContext(ctx context.Context) *db.ApiKey {
+	apiKey, ok := ctx.Value(apiKeyContextKey).(*db.ApiKey)
+	if !ok {
+		return nil
+	}
+	return apiKey
+}
+
+// ContextSetClientCertificate adds the registration of the client certificate used to authenticate
+// the request to the given context.
+func ContextSetClientCertificate(ctx context.Context, certificate *db.ClientCertificate) context.Context {
+	return context.WithValue(ctx, clientCertificateContextKey, certificate)
+}
+
+// GetClientCertificateFromContext retrieves the registration of the client certificate the request
+// was authenticated with. It returns nil if the request was not authenticated with a client certificate.
+func GetClientCertificateFromContext(ctx context.Context) *db.ClientCertificate {
+	certificate, ok := ctx.Value(clientCertificateContextKey).(*db.ClientCertificate)
+	if !ok {
+		return nil
+	}
+	return certificate
+}
+
+// ContextSetClient adds the OAuth client the request was authenticated as to the given context.
+func ContextSetClient(ctx context.Context, client *db.OauthClient) context.Context {
+	return context.WithValue(ctx, clientContextKey, client)
+}
+
+// GetClientFromContext retrieves the OAuth client the request was authenticated as.
+// It returns nil unless the request carried a client access token; such requests have no user.
+func GetClientFromContext(ctx context.Context) *db.OauthClient {
+	client, ok := ctx.Value(clientContextKey).(*db.OauthClient)
+	if !ok {
+		return nil
+	}
+	return client
+}
+
+// ContextSetServiceAccount adds the service account the request was authenticated as to the given context.
+func ContextSetServiceAccount(ctx context.Context, account *db.ServiceAccount) context.Context {
+	return context.WithValue(ctx, serviceAccountContextKey, account)
+}
+
+// GetServiceAccountFromContext retrieves the service account the request was authenticated as.
+// It returns nil unless the request carried an API key of a service account; such requests have no user.
+func GetServiceAccountFromContext(ctx context.Context) *db.ServiceAccount {
+	account, ok := ctx.Value(serviceAccountContextKey).(*db.ServiceAccount)
+	if !ok {
+		return nil
+	}
+	return account
+}
+
+// ContextSetImpersonator adds the administrator acting as the authenticated user to the given context.
+func ContextSetImpersonator(ctx context.Context, impersonator *db.User) context.Context {
+	return context.WithValue(ctx, impersonatorContextKey, impersonator)
+}
+
+// GetImpersonatorFromContext retrieves the administrator acting as the authenticated user.
+// It returns nil unless the request carried an impersonation token; GetUserFromContext
+// then returns the impersonated user.
+func GetImpersonatorFromContext(ctx context.Context) *db.User {
+	impersonator, ok := ctx.Value(impersonatorContextKey).(*db.User)
+	if !ok {
+		return nil
+	}
+	return impersonator
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CSRFHeader is the request header that has to repeat the CSRF cookie on state changing
+// requests authenticated with the session cookie.
+const CSRFHeader = "X-CSRF-Token"
+
+// Base names of the session cookies, see cookieName.
+const (
+	accessTokenCookie  = "access_token"
+	refreshTokenCookie = "refresh_token"
+	csrfTokenCookie    = "csrf_token"
+)
+
+// csrfTokenBytes is the amount of entropy in a CSRF token.
+const csrfTokenBytes = 32
+
+// CookieConfig controls browser sessions, in which the tokens are kept in HttpOnly cookies
+// out of reach of JavaScript instead of being returned in the response body.
+type CookieConfig struct {
+	Enabled bool
+	// Secure restricts the cookies to HTTPS. Only disable it for local development over plain HTTP.
+	Secure   bool
+	SameSite http.SameSite
+	// Domain shares the cookies with subdomains; empty limits them to the API host.
+	Domain string
+	// RefreshPath is the only path the refresh token cookie is sent to, e.g. /api/v1/auth.
+	RefreshPath string
+}
+
+// CookieSessionsEnabled reports whether clients may ask for their tokens in cookies.
+func (s *AuthService) CookieSessionsEnabled() bool {
+	return s.cookies.Enabled
+}
+
+// SetSessionCookies stores tokens in HttpOnly cookies on w, along with a new CSRF token
+// in a cookie that JavaScript can read. The CSRF token is also returned, for front ends
+// served from another origin that cannot read the API's cookies.
+func (s *AuthService) SetSessionCookies(w http.ResponseWriter, tokens *TokenPair) (string, error) {
+	raw := make([]byte, csrfTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate CSRF token: %w", err)
+	}
+	csrfToken := base64.RawURLEncoding.EncodeToString(raw)
+
+	http.SetCookie(w, s.cookie(accessTokenCookie, "/", tokens.AccessToken, s.tokenExpiry, true))
+	http.SetCookie(w, s.cookie(refreshTokenCookie, s.cookies.RefreshPath, tokens.RefreshToken, s.refreshExpiry, true))
+	// The CSRF cookie lives as long as the refresh token so refreshing keeps working
+	// after the access token cookie has expired.
+	http.SetCookie(w, s.cookie(csrfTokenCookie, "/", csrfToken, s.refreshExpiry, false))
+	return csrfToken, nil
+}
+
+// ClearSessionCookies tells the browser to delete the session cookies.
+func (s *AuthService) ClearSessionCookies(w http.ResponseWriter) {
+	http.SetCookie(w, s.cookie(accessTokenCookie, "/", "", -1, true))
+	http.SetCookie(w, s.cookie(refreshTokenCookie, s.cookies.RefreshPath, "", -1, true))
+	http.SetCookie(w, s.cookie(csrfTokenCookie, "/", "", -1, false))
+}
+
+// RefreshTokenFromCookie returns the refresh token sent in the session cookie,
+// or an empty string if there is none or cookie sessions are disabled.
+func (s *AuthService) RefreshTokenFromCookie(r *http.Request) string {
+	return s.cookieValue(r, refreshTokenCookie)
+}
+
+// VerifyCSRF reports whether r may be processed on the strength of its session cookies.
+// Safe methods always may; other requests have to repeat the CSRF cookie in the X-CSRF-Token
+// header, which a cross-site form or script cannot do because it cannot read the cookie.
+func (s *AuthService) VerifyCSRF(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	cookie := s.cookieValue(r, csrfTokenCookie)
+	header := r.Header.Get(CSRFHeader)
+	return cookie != "" && subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
+
+// cookieValue returns the value of the named session cookie of r, or an empty string.
+func (s *AuthService) cookieValue(r *http.Request, name string) string {
+	if !s.cookies.Enabled {
+		return ""
+	}
+	path := "/"
+	if name == refreshTokenCookie {
+		path = s.cookies.RefreshPath
+	}
+	cookie, err := r.Cookie(s.cookieName(name, path))
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// cookie builds a session cookie. A negative maxAge deletes it.
+func (s *AuthService) cookie(name, path, value string, maxAge time.Duration, httpOnly bool) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     s.cookieName(name, path),
+		Value:    value,
+		Path:     path,
+		Domain:   s.cookies.Domain,
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   s.cookies.Secure,
+		HttpOnly: httpOnly,
+		SameSite: s.cookies.SameSite,
+	}
+	if maxAge < 0 {
+		cookie.MaxAge = -1
+	}
+	return cookie
+}
+
+// cookieName prefixes secure cookies so browsers refuse to let plain HTTP pages or, for
+// __Host- cookies, other subdomains overwrite them with a value chosen by an attacker.
+func (s *AuthService) cookieName(name, path string) string {
+	switch {
+	case !s.cookies.Secure:
+		return name
+	case s.cookies.Domain == "" && path == "/":
+		return "__Host-" + name
+	default:
+		return "__Secure-" + name
+	}
+}
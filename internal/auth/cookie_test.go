@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// renderError writes just the status code, standing in for api.ErrorResponse.
+func renderError(w http.ResponseWriter, r *http.Request, status int, message any) {
+	w.WriteHeader(status)
+}
+
+func TestCookieSessionCSRF(t *testing.T) {
+	st := newFakeStore()
+	s := newTestService(t, st, func(cfg *Config) {
+		cfg.Cookies = CookieConfig{Enabled: true, Secure: true, SameSite: http.SameSiteLaxMode, RefreshPath: "/api/v1/auth"}
+	})
+	_, tokens := loginForTest(t, st, s)
+
+	rec := httptest.NewRecorder()
+	csrfToken, err := s.SetSessionCookies(rec, tokens)
+	if err != nil {
+		t.Fatalf("SetSessionCookies() error = %v", err)
+	}
+	cookies := rec.Result().Cookies()
+
+	byName := make(map[string]*http.Cookie)
+	for _, cookie := range cookies {
+		byName[cookie.Name] = cookie
+	}
+	if c := byName["__Host-access_token"]; c == nil || !c.HttpOnly || !c.Secure || c.Path != "/" {
+		t.Errorf("access token cookie = %+v, want an HttpOnly __Host- cookie", c)
+	}
+	if c := byName["__Secure-refresh_token"]; c == nil || !c.HttpOnly || c.Path != "/api/v1/auth" {
+		t.Errorf("refresh token cookie = %+v, want an HttpOnly __Secure- cookie limited to the refresh path", c)
+	}
+	if c := byName["__Host-csrf_token"]; c == nil || c.HttpOnly || c.Value != csrfToken {
+		t.Errorf("CSRF cookie = %+v, want a cookie JavaScript can read holding %q", c, csrfToken)
+	}
+
+	handler := s.JWTMiddleware(renderError)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetUserFromContext(r.Context()) == nil {
+			t.Error("no user in the context")
+		}
+	}))
+
+	tests := []struct {
+		name       string
+		method     string
+		withCookie bool
+		csrfHeader string
+		bearer     string
+		wantStatus int
+	}{
+		{"GET without CSRF header", http.MethodGet, true, "", "", http.StatusOK},
+		{"HEAD without CSRF header", http.MethodHead, true, "", "", http.StatusOK},
+		{"POST with CSRF header", http.MethodPost, true, csrfToken, "", http.StatusOK},
+		{"DELETE with CSRF header", http.MethodDelete, true, csrfToken, "", http.StatusOK},
+		{"POST without CSRF header", http.MethodPost, true, "", "", http.StatusForbidden},
+		{"PUT with wrong CSRF header", http.MethodPut, true, csrfToken + "x", "", http.StatusForbidden},
+		{"PATCH with the access token as CSRF header", http.MethodPatch, true, tokens.AccessToken, "", http.StatusForbidden},
+		{"POST with bearer token", http.MethodPost, false, "", tokens.AccessToken, http.StatusOK},
+		{"POST with bearer token and cookies", http.MethodPost, true, "", tokens.AccessToken, http.StatusOK},
+		{"no credentials", http.MethodGet, false, "", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/api/v1/users/me", nil)
+			if tt.withCookie {
+				for _, cookie := range cookies {
+					r.AddCookie(cookie)
+				}
+			}
+			if tt.csrfHeader != "" {
+				r.Header.Set(CSRFHeader, tt.csrfHeader)
+			}
+			if tt.bearer != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestVerifyCSRF(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		secure  bool
+		cookie  string // Name of the CSRF cookie sent, if any
+		header  string
+		want    bool
+	}{
+		{"matching", true, false, "csrf_token", "token", true},
+		{"matching __Host- cookie", true, true, "__Host-csrf_token", "token", true},
+		{"unprefixed cookie while secure", true, true, "csrf_token", "token", false},
+		{"mismatch", true, false, "csrf_token", "other", false},
+		{"no header", true, false, "csrf_token", "", false},
+		{"no cookie", true, false, "", "token", false},
+		{"no cookie and no header", true, false, "", "", false},
+		{"cookie sessions disabled", false, false, "csrf_token", "token", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &AuthService{cookies: CookieConfig{Enabled: tt.enabled, Secure: tt.secure, RefreshPath: "/"}}
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: tt.cookie, Value: "token"})
+			}
+			if tt.header != "" {
+				r.Header.Set(CSRFHeader, tt.header)
+			}
+			if got := s.VerifyCSRF(r); got != tt.want {
+				t.Errorf("VerifyCSRF() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCookieName(t *testing.T) {
+	tests := []struct {
+		cookies CookieConfig
+		path    string
+		want    string
+	}{
+		{CookieConfig{}, "/", "access_token"},
+		{CookieConfig{Secure: true}, "/", "__Host-access_token"},
+		{CookieConfig{Secure: true}, "/api/v1/auth", "__Secure-access_token"},
+		{CookieConfig{Secure: true, Domain: "example.com"}, "/", "__Secure-access_token"},
+	}
+	for _, tt := range tests {
+		s := &AuthService{cookies: tt.cookies}
+		if got := s.cookieName(accessTokenCookie, tt.path); got != tt.want {
+			t.Errorf("cookieName() with %+v and path %q = %q, want %q", tt.cookies, tt.path, got, tt.want)
+		}
+	}
+}
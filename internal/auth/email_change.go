@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/mailer"
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+var (
+	ErrEmailTaken              = errors.New("email address is already in use")
+	ErrEmailUnchanged          = errors.New("new email address is the same as the current one")
+	ErrInvalidEmailChangeToken = errors.New("invalid or expired email change token")
+)
+
+// emailChangeTokenBytes is the amount of entropy in email change confirmation and cancellation tokens.
+const emailChangeTokenBytes = 32
+
+// RequestEmailChange starts changing the user's email address to newEmail after checking their password.
+// The new address is stored as pending and only replaces the current one once it is confirmed
+// with the token mailed to it (see ConfirmEmailChange). The current address is notified and
+// receives a token to cancel the change (see CancelEmailChange). A new request replaces a pending one.
+// It returns ErrInvalidCredentials if currentPassword is wrong and ErrEmailTaken if newEmail belongs to another account.
+func (s *AuthService) RequestEmailChange(ctx context.Context, user *db.User, currentPassword, newEmail string) (*db.EmailChangeRequest, error) {
+	if !s.checkPassword(currentPassword, user) {
+		return nil, ErrInvalidCredentials
+	}
+	if newEmail == user.Email {
+		return nil, ErrEmailUnchanged
+	}
+
+	// Checked again when the change is confirmed, since the address may be registered in between.
+	if _, err := s.userStore.GetUserByEmail(ctx, newEmail); err == nil {
+		return nil, ErrEmailTaken
+	} else if !errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	if err := s.emailChangeStore.CancelPendingEmailChangeRequests(ctx, user.ID); err != nil {
+		return nil, fmt.Errorf("failed to cancel pending email changes: %w", err)
+	}
+
+	confirmToken, err := generateToken(emailChangeTokenBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate email change token: %w", err)
+	}
+	cancelToken, err := generateToken(emailChangeTokenBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate email change cancellation token: %w", err)
+	}
+
+	request, err := s.emailChangeStore.CreateEmailChangeRequest(ctx, db.CreateEmailChangeRequestParams{
+		UserID:          user.ID,
+		OldEmail:        user.Email,
+		NewEmail:        newEmail,
+		TokenHash:       hashToken(confirmToken),
+		CancelTokenHash: hashToken(cancelToken),
+		ExpiresAt:       pgtype.Timestamptz{Time: time.Now().Add(s.emailVerificationExpiry), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store email change request: %w", err)
+	}
+
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm that you want to use this address for your account by opening the link below:\n\n%s\n\nThe link expires in %s. If you did not ask for this, you can ignore this email.\n",
+			user.Username, s.link("/confirm-email-change", confirmToken), s.emailVerificationExpiry),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send email change confirmation: %w", err)
+	}
+
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Your email address is being changed",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to change the email address of your account to %s. If it was you, there is nothing to do.\n\nIf it was not, open the link below to cancel the change. If it has already been confirmed, your address is restored and every session of your account is signed out:\n\n%s\n\nThe link expires in %s. We also recommend changing your password.\n",
+			user.Username, newEmail, s.link("/cancel-email-change", cancelToken), s.emailVerificationExpiry),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send email change notification: %w", err)
+	}
+
+	return &request, nil
+}
+
+// ConfirmEmailChange replaces the user's email address with the pending one a confirmation token was issued for.
+// The new address is marked verified and outstanding password reset links, which were sent to the old address,
+// are invalidated. It returns ErrEmailTaken if another account registered the address in the meantime.
+func (s *AuthService) ConfirmEmailChange(ctx context.Context, token string) (*db.User, error) {
+	request, err := s.emailChangeStore.GetEmailChangeRequestByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrInvalidEmailChangeToken
+		}
+		return nil, fmt.Errorf("failed to get email change request: %w", err)
+	}
+	if request.ConfirmedAt.Valid || request.CancelledAt.Valid || time.Now().After(request.ExpiresAt.Time) {
+		return nil, ErrInvalidEmailChangeToken
+	}
+
+	// Claim the request atomically so it cannot be confirmed twice or after it was cancelled.
+	if _, err := s.emailChangeStore.MarkEmailChangeRequestConfirmed(ctx, request.ID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrInvalidEmailChangeToken
+		}
+		return nil, fmt.Errorf("failed to mark email change as confirmed: %w", err)
+	}
+
+	user, err := s.userStore.ChangeUserEmail(ctx, db.ChangeUserEmailParams{
+		ID:       request.UserID,
+		OldEmail: request.OldEmail,
+		NewEmail: request.NewEmail,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrConflict):
+			return nil, ErrEmailTaken
+		case errors.Is(err, store.ErrNotFound):
+			// The user is gone or their address changed since the request was made.
+			return nil, ErrInvalidEmailChangeToken
+		}
+		return nil, fmt.Errorf("failed to change email: %w", err)
+	}
+
+	if err := s.passwordResetStore.InvalidateUserPasswordResetTokens(ctx, user.ID); err != nil {
+		return nil, fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+	}
+
+	return &user, nil
+}
+
+// CancelEmailChange cancels the email change a cancellation token was issued for.
+// If the change was already confirmed, the previous address is restored and every session
+// and API key of the user is revoked, since the change was presumably made by someone else.
+func (s *AuthService) CancelEmailChange(ctx context.Context, token string) error {
+	request, err := s.emailChangeStore.GetEmailChangeRequestByCancelTokenHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return ErrInvalidEmailChangeToken
+		}
+		return fmt.Errorf("failed to get email change request: %w", err)
+	}
+	if request.CancelledAt.Valid || time.Now().After(request.ExpiresAt.Time) {
+		return ErrInvalidEmailChangeToken
+	}
+
+	request, err = s.emailChangeStore.MarkEmailChangeRequestCancelled(ctx, request.ID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return ErrInvalidEmailChangeToken
+		}
+		return fmt.Errorf("failed to mark email change as cancelled: %w", err)
+	}
+	if !request.ConfirmedAt.Valid {
+		return nil
+	}
+
+	_, err = s.userStore.ChangeUserEmail(ctx, db.ChangeUserEmailParams{
+		ID:       request.UserID,
+		OldEmail: request.NewEmail,
+		NewEmail: request.OldEmail,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrConflict):
+			return ErrEmailTaken
+		case errors.Is(err, store.ErrNotFound):
+			// The address has been changed again since; this token no longer applies.
+			return ErrInvalidEmailChangeToken
+		}
+		return fmt.Errorf("failed to restore email: %w", err)
+	}
+
+	if err := s.passwordResetStore.InvalidateUserPasswordResetTokens(ctx, request.UserID); err != nil {
+		return fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+	}
+	if err := s.RevokeAllSessions(ctx, request.UserID); err != nil {
+		return err
+	}
+	return s.apiKeyService.RevokeAll(ctx, request.UserID)
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/mailer"
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+var (
+	ErrEmailNotVerified              = errors.New("email address has not been verified")
+	ErrInvalidEmailVerificationToken = errors.New("invalid or expired email verification token")
+)
+
+// SendVerificationEmail mails the user a link to verify their email address.
+// The link carries a signed token that is bound to the current address and can be used once.
+func (s *AuthService) SendVerificationEmail(ctx context.Context, user *db.User) error {
+	claims := s.newClaims(user.ID.String(), TokenTypeEmailVerification, s.emailVerificationExpiry)
+	claims.Email = user.Email
+
+	token, err := s.keys.Sign(claims)
+	if err != nil {
+		return fmt.Errorf("failed to sign email verification token: %w", err)
+	}
+
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			user.Username, s.link("/verify-email", token), s.emailVerificationExpiry),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+	return nil
+}
+
+// ResendVerificationEmail sends a new verification email to the account registered with email.
+// It silently does nothing if there is no such account or it is already verified,
+// so callers cannot use it to find out which addresses are registered. The email is sent
+// in the background, so the response does not take longer for registered addresses either.
+func (s *AuthService) ResendVerificationEmail(ctx context.Context, email string) error {
+	user, err := s.userStore.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user by email: %w", err)
+	}
+	if user.EmailVerifiedAt.Valid {
+		return nil
+	}
+
+	s.inBackground(ctx, "verification email", func(ctx context.Context) error {
+		return s.SendVerificationEmail(ctx, &user)
+	})
+	return nil
+}
+
+// VerifyEmail marks the address a verification token was issued for as verified.
+// Tokens are rejected once used, once expired, or if the user's address has changed since.
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) (*db.User, error) {
+	claims, err := s.parseClaims(token, TokenTypeEmailVerification)
+	if err != nil {
+		return nil, ErrInvalidEmailVerificationToken
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, ErrInvalidEmailVerificationToken
+	}
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, ErrInvalidEmailVerificationToken
+	}
+
+	revoked, err := s.revokedTokenStore.IsTokenRevoked(ctx, tokenID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return nil, ErrInvalidEmailVerificationToken
+	}
+
+	user, err := s.userStore.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrInvalidEmailVerificationToken
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Email != claims.Email {
+		return nil, ErrInvalidEmailVerificationToken
+	}
+
+	if !user.EmailVerifiedAt.Valid {
+		_, err = s.userStore.MarkUserEmailVerified(ctx, db.MarkUserEmailVerifiedParams{ID: user.ID, Email: claims.Email})
+		if err != nil {
+			return nil, fmt.Errorf("failed to mark email as verified: %w", err)
+		}
+	}
+
+	// Tokens are single use.
+	err = s.revokedTokenStore.RevokeToken(ctx, db.RevokeTokenParams{
+		Jti:       tokenID,
+		UserID:    pgtype.UUID{Bytes: user.ID, Valid: true},
+		ExpiresAt: pgtype.Timestamptz{Time: claims.ExpiresAt.Time, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke email verification token: %w", err)
+	}
+
+	// Reload so the caller sees the verification timestamp.
+	user, err = s.userStore.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// link builds an absolute URL to a page of the front end, passing token as a query parameter.
+func (s *AuthService) link(path, token string) string {
+	return s.appBaseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// inBackground runs send without holding up the current request, for emails whose
+// sending time would otherwise reveal whether an address is registered. Failures
+// can no longer be reported to the caller and are logged instead.
+func (s *AuthService) inBackground(ctx context.Context, what string, send func(context.Context) error) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := send(ctx); err != nil {
+			slog.ErrorContext(ctx, "failed to send "+what, "error", err)
+		}
+	}()
+}
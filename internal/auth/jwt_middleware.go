@@ -3,11 +3,9 @@ package auth
 import (
 	// "context" // No longer directly used here
 	"errors"
-	"fmt"
 	"net/http"
 	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 
 	// "go-api-structure/internal/api" // Removed to break import cycle
@@ -16,53 +14,75 @@ import (
 )
 
 // Middleware is a JWT authentication middleware.
-// It checks for a valid JWT in the Authorization header.
+// It checks for a valid JWT in the Authorization header or, if cookie sessions are enabled
+// and the header is absent, in the session cookie. Access tokens issued to OAuth clients are
+// accepted too; they add the client instead of a user to the context (see ContextSetClient).
+// Impersonation tokens add the user and the administrator acting as them (see ContextSetImpersonator),
+// mark the response with the X-Impersonated-By header and are recorded in the audit trail. Cookie authenticated requests other than
+// GET, HEAD and OPTIONS also need the X-CSRF-Token header to match the CSRF cookie.
 // If the token is valid, it retrieves the user from the store and adds them to the request context using ContextSetUser.
 // It calls the provided errorRenderer for sending HTTP error responses.
 func (s *AuthService) JWTMiddleware(errorRenderer func(w http.ResponseWriter, r *http.Request, status int, message any)) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var tokenString string
 			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
+			if authHeader != "" {
+				headerParts := strings.Split(authHeader, " ")
+				if len(headerParts) != 2 || strings.ToLower(headerParts[0]) != "bearer" {
+					errorRenderer(w, r, http.StatusUnauthorized, "invalid authorization header format")
+					return
+				}
+				tokenString = headerParts[1]
+			} else if cookie := s.cookieValue(r, accessTokenCookie); cookie != "" {
+				// Browsers send cookies along with cross-site requests, but cannot set the header.
+				if !s.VerifyCSRF(r) {
+					errorRenderer(w, r, http.StatusForbidden, "missing or invalid CSRF token")
+					return
+				}
+				tokenString = cookie
+			} else {
 				errorRenderer(w, r, http.StatusUnauthorized, "authorization header missing")
 				return
 			}
 
-			headerParts := strings.Split(authHeader, " ")
-			if len(headerParts) != 2 || strings.ToLower(headerParts[0]) != "bearer" {
-				errorRenderer(w, r, http.StatusUnauthorized, "invalid authorization header format")
+			// Verifies signature, issuer, audience, expiry (with leeway) and the token type.
+			claims, err := s.parseClaims(tokenString, TokenTypeAccess, TokenTypeClientAccess)
+			if err != nil {
+				errorRenderer(w, r, http.StatusUnauthorized, tokenErrorMessage(err))
 				return
 			}
 
-			tokenString := headerParts[1]
-
-			claims := &jwt.RegisteredClaims{}
-			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			// OAuth clients are a principal of their own, without a user.
+			if claims.TokenType == TokenTypeClientAccess {
+				ctx, ok := s.authenticateClientToken(w, r, errorRenderer, claims)
+				if ok {
+					next.ServeHTTP(w, r.WithContext(ctx))
 				}
-				return []byte(s.jwtSecret), nil
-			})
+				return
+			}
 
+			userID, err := uuid.Parse(claims.Subject)
 			if err != nil {
-				if errors.Is(err, jwt.ErrTokenExpired) {
-					errorRenderer(w, r, http.StatusUnauthorized, "token expired")
-				} else {
-					errorRenderer(w, r, http.StatusUnauthorized, "invalid token")
-				}
+				// Log this error as it's likely a server-side issue with token generation or parsing claims
+				// For the client, it's still an invalid token situation or internal error.
+				errorRenderer(w, r, http.StatusUnauthorized, "invalid token claims") // Or InternalServerError
 				return
 			}
 
-			if !token.Valid {
-				errorRenderer(w, r, http.StatusUnauthorized, "invalid token")
+			tokenID, err := uuid.Parse(claims.ID)
+			if err != nil {
+				errorRenderer(w, r, http.StatusUnauthorized, "invalid token claims")
 				return
 			}
 
-			userID, err := uuid.Parse(claims.Subject)
+			revoked, err := s.revokedTokenStore.IsTokenRevoked(r.Context(), tokenID)
 			if err != nil {
-				// Log this error as it's likely a server-side issue with token generation or parsing claims
-				// For the client, it's still an invalid token situation or internal error.
-				errorRenderer(w, r, http.StatusUnauthorized, "invalid token claims") // Or InternalServerError
+				errorRenderer(w, r, http.StatusInternalServerError, "error checking token revocation")
+				return
+			}
+			if revoked {
+				errorRenderer(w, r, http.StatusUnauthorized, "token revoked")
 				return
 			}
 
@@ -77,8 +97,31 @@ func (s *AuthService) JWTMiddleware(errorRenderer func(w http.ResponseWriter, r
 				return
 			}
 
-			// Add user to context
+			// Reject tokens issued before the user's last "revoke all sessions".
+			if issuedBeforeRevocation(claims, &user) {
+				errorRenderer(w, r, http.StatusUnauthorized, "token revoked")
+				return
+			}
+
+			if user.SuspendedAt.Valid {
+				errorRenderer(w, r, http.StatusForbidden, "account is suspended")
+				return
+			}
+
+			// Reject tokens of sessions the user logged out of or that timed out.
+			if !s.checkSession(w, r, errorRenderer, claims, userID) {
+				return
+			}
+
+			// Add user and claims to context
 			ctxWithUser := ContextSetUser(r.Context(), &user) // Use ContextSetUser from context.go
+			ctxWithUser = ContextSetClaims(ctxWithUser, claims)
+
+			// An administrator acting as the user; both are exposed and the request is recorded.
+			if claims.Impersonated() {
+				s.serveImpersonated(w, r.WithContext(ctxWithUser), next, errorRenderer, claims, &user)
+				return
+			}
 			next.ServeHTTP(w, r.WithContext(ctxWithUser))
 		})
 	}
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSAKeyBits is the smallest RSA modulus accepted for signing keys.
+const minRSAKeyBits = 2048
+
+// KeyFile points at a PEM encoded private key identified by a key ID (kid).
+type KeyFile struct {
+	ID   string
+	Path string
+}
+
+// signingKey is a single key in a KeySet.
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   any              // Private key (or HMAC secret) used to sign tokens
+	verifyKey any              // Public key (or HMAC secret) used to verify tokens
+	publicKey crypto.PublicKey // Published through JWKS; nil for HMAC keys
+}
+
+// KeySet holds every key that tokens may be verified with, and the one key new tokens are signed with.
+// Keeping retired keys in the set lets tokens signed before a rotation stay valid until they expire.
+type KeySet struct {
+	keys   map[string]*signingKey
+	order  []*signingKey // Load order, used for stable JWKS output
+	active *signingKey
+}
+
+// LoadKeySet reads the given PEM files and builds a KeySet that signs with activeKeyID.
+// If activeKeyID is empty, the first file is used for signing.
+// If hmacSecret is non-empty it is registered as a legacy HS256 key without a kid,
+// so tokens issued before asymmetric keys were introduced keep working. It is only
+// used for signing when no key files are given.
+func LoadKeySet(files []KeyFile, activeKeyID, hmacSecret string) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*signingKey)}
+
+	if hmacSecret != "" {
+		ks.add(&signingKey{
+			method:    jwt.SigningMethodHS256,
+			signKey:   []byte(hmacSecret),
+			verifyKey: []byte(hmacSecret),
+		})
+	}
+
+	for _, file := range files {
+		if file.ID == "" {
+			return nil, fmt.Errorf("signing key %s has no key ID", file.Path)
+		}
+		if _, exists := ks.keys[file.ID]; exists {
+			return nil, fmt.Errorf("duplicate signing key ID %q", file.ID)
+		}
+
+		data, err := os.ReadFile(file.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key %q: %w", file.ID, err)
+		}
+
+		key, err := parseSigningKey(file.ID, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse signing key %q: %w", file.ID, err)
+		}
+		ks.add(key)
+	}
+
+	switch {
+	case activeKeyID != "":
+		active, ok := ks.keys[activeKeyID]
+		if !ok {
+			return nil, fmt.Errorf("active signing key %q is not loaded", activeKeyID)
+		}
+		ks.active = active
+	case len(files) > 0:
+		ks.active = ks.keys[files[0].ID]
+	case hmacSecret != "":
+		ks.active = ks.keys[""]
+	default:
+		return nil, errors.New("no signing keys configured")
+	}
+
+	return ks, nil
+}
+
+func (ks *KeySet) add(key *signingKey) {
+	ks.keys[key.id] = key
+	ks.order = append(ks.order, key)
+}
+
+// ActiveKeyID returns the kid of the key new tokens are signed with.
+// It is empty when signing with the legacy HMAC secret.
+func (ks *KeySet) ActiveKeyID() string {
+	return ks.active.id
+}
+
+// Sign signs claims with the active key, setting the kid header for asymmetric keys.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.method, claims)
+	if ks.active.id != "" {
+		token.Header["kid"] = ks.active.id
+	}
+	return token.SignedString(ks.active.signKey)
+}
+
+// Keyfunc returns the verification key for a parsed token.
+// The key is chosen by the token's kid header and must match the token's algorithm,
+// which prevents algorithm confusion between HMAC and public keys.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verifyKey, nil
+}
+
+// parseSigningKey decodes a PEM private key and picks the JWT algorithm that matches it.
+func parseSigningKey(id string, data []byte) (*signingKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var (
+		parsed any
+		err    error
+	)
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch key := parsed.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", minRSAKeyBits)
+		}
+		return &signingKey{id: id, method: jwt.SigningMethodRS256, signKey: key, verifyKey: &key.PublicKey, publicKey: &key.PublicKey}, nil
+	case *ecdsa.PrivateKey:
+		var method jwt.SigningMethod
+		switch key.Curve {
+		case elliptic.P256():
+			method = jwt.SigningMethodES256
+		case elliptic.P384():
+			method = jwt.SigningMethodES384
+		case elliptic.P521():
+			method = jwt.SigningMethodES512
+		default:
+			return nil, errors.New("unsupported elliptic curve")
+		}
+		return &signingKey{id: id, method: method, signKey: key, verifyKey: &key.PublicKey, publicKey: &key.PublicKey}, nil
+	case ed25519.PrivateKey:
+		public := key.Public().(ed25519.PublicKey)
+		return &signingKey{id: id, method: jwt.SigningMethodEdDSA, signKey: key, verifyKey: public, publicKey: public}, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", parsed)
+	}
+}
@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeKeyFile stores key as a PKCS #8 PEM file and returns a KeyFile for it.
+func writeKeyFile(t *testing.T, id string, key any) KeyFile {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key %q: %v", id, err)
+	}
+	path := filepath.Join(t.TempDir(), id+".pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write key %q: %v", id, err)
+	}
+	return KeyFile{ID: id, Path: path}
+}
+
+// testKeys holds one key of each supported type.
+type testKeys struct {
+	rsa     *rsa.PrivateKey
+	ec      *ecdsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+	files   []KeyFile
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, minRSAKeyBits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testKeys{
+		rsa:     rsaKey,
+		ec:      ecKey,
+		ed25519: edKey,
+		files: []KeyFile{
+			writeKeyFile(t, "rsa", rsaKey),
+			writeKeyFile(t, "ec", ecKey),
+			writeKeyFile(t, "ed", edKey),
+		},
+	}
+}
+
+func TestKeySetKeyfunc(t *testing.T) {
+	keys := newTestKeys(t)
+	ks, err := LoadKeySet(keys.files, "ec", "legacy secret")
+	if err != nil {
+		t.Fatalf("LoadKeySet() error = %v", err)
+	}
+
+	sign := func(method jwt.SigningMethod, kid string, key any) string {
+		t.Helper()
+		token := jwt.NewWithClaims(method, jwt.RegisteredClaims{Subject: "user"})
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return signed
+	}
+	rsaPublicPEM, err := x509.MarshalPKIXPublicKey(&keys.rsa.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"active key", sign(jwt.SigningMethodES256, "ec", keys.ec), false},
+		{"retired RSA key", sign(jwt.SigningMethodRS256, "rsa", keys.rsa), false},
+		{"retired Ed25519 key", sign(jwt.SigningMethodEdDSA, "ed", keys.ed25519), false},
+		{"legacy HMAC secret without kid", sign(jwt.SigningMethodHS256, "", []byte("legacy secret")), false},
+		{"unknown kid", sign(jwt.SigningMethodES256, "other", keys.ec), true},
+		{"kid of another key", sign(jwt.SigningMethodES256, "rsa", keys.ec), true},
+		{"HMAC signed with the RSA public key", sign(jwt.SigningMethodHS256, "rsa", rsaPublicPEM), true},
+		{"HMAC with a kid", sign(jwt.SigningMethodHS256, "ec", []byte("legacy secret")), true},
+		{"RS512 with an RS256 key", sign(jwt.SigningMethodRS512, "rsa", keys.rsa), true},
+		{"unsigned", sign(jwt.SigningMethodNone, "ec", jwt.UnsafeAllowNoneSignatureType), true},
+		{"unsigned without kid", sign(jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jwt.Parse(tt.token, ks.Keyfunc)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeySetSign(t *testing.T) {
+	keys := newTestKeys(t)
+
+	tests := []struct {
+		name       string
+		activeID   string
+		hmacSecret string
+		files      []KeyFile
+		wantKid    string
+		wantAlg    string
+	}{
+		{"first file by default", "", "", keys.files, "rsa", "RS256"},
+		{"configured key", "ed", "", keys.files, "ed", "EdDSA"},
+		{"files win over the HMAC secret", "", "secret", keys.files, "rsa", "RS256"},
+		{"HMAC secret only", "", "secret", nil, "", "HS256"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks, err := LoadKeySet(tt.files, tt.activeID, tt.hmacSecret)
+			if err != nil {
+				t.Fatalf("LoadKeySet() error = %v", err)
+			}
+			signed, err := ks.Sign(jwt.RegisteredClaims{Subject: "user"})
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			token, err := jwt.Parse(signed, ks.Keyfunc)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if kid, _ := token.Header["kid"].(string); kid != tt.wantKid {
+				t.Errorf("kid = %q, want %q", kid, tt.wantKid)
+			}
+			if alg := token.Method.Alg(); alg != tt.wantAlg {
+				t.Errorf("alg = %q, want %q", alg, tt.wantAlg)
+			}
+		})
+	}
+}
+
+func TestLoadKeySetErrors(t *testing.T) {
+	keys := newTestKeys(t)
+	smallRSA, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p224, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notPEM := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(notPEM, []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		files    []KeyFile
+		activeID string
+		wantErr  string
+	}{
+		{"nothing configured", nil, "", "no signing keys"},
+		{"unknown active key", keys.files, "missing", "not loaded"},
+		{"duplicate key ID", []KeyFile{keys.files[0], {ID: "rsa", Path: keys.files[1].Path}}, "", "duplicate"},
+		{"missing key ID", []KeyFile{{Path: keys.files[0].Path}}, "", "no key ID"},
+		{"missing file", []KeyFile{{ID: "gone", Path: filepath.Join(t.TempDir(), "gone.pem")}}, "", "failed to read"},
+		{"not PEM", []KeyFile{{ID: "bad", Path: notPEM}}, "", "no PEM block"},
+		{"RSA key too small", []KeyFile{writeKeyFile(t, "small", smallRSA)}, "", "at least 2048 bits"},
+		{"unsupported curve", []KeyFile{writeKeyFile(t, "p224", p224)}, "", "unsupported elliptic curve"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadKeySet(tt.files, tt.activeID, "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadKeySet() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeySetJWKS(t *testing.T) {
+	keys := newTestKeys(t)
+	ks, err := LoadKeySet(keys.files, "", "legacy secret")
+	if err != nil {
+		t.Fatalf("LoadKeySet() error = %v", err)
+	}
+
+	set := ks.JWKS()
+	if len(set.Keys) != 3 {
+		t.Fatalf("JWKS() has %d keys, want 3 (the HMAC secret must not be published)", len(set.Keys))
+	}
+
+	decode := func(s string) *big.Int {
+		t.Helper()
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatalf("invalid base64url %q: %v", s, err)
+		}
+		return new(big.Int).SetBytes(b)
+	}
+
+	rsaJWK, ecJWK, edJWK := set.Keys[0], set.Keys[1], set.Keys[2]
+	if rsaJWK.KeyID != "rsa" || rsaJWK.KeyType != "RSA" || rsaJWK.Algorithm != "RS256" || rsaJWK.Use != "sig" {
+		t.Errorf("RSA key = %+v", rsaJWK)
+	}
+	if decode(rsaJWK.N).Cmp(keys.rsa.N) != 0 || decode(rsaJWK.E).Int64() != int64(keys.rsa.E) {
+		t.Error("RSA key does not carry the public modulus and exponent")
+	}
+
+	if ecJWK.KeyID != "ec" || ecJWK.KeyType != "EC" || ecJWK.Algorithm != "ES256" || ecJWK.Curve != "P-256" {
+		t.Errorf("EC key = %+v", ecJWK)
+	}
+	if len(ecJWK.X) != 43 || len(ecJWK.Y) != 43 {
+		t.Errorf("EC coordinates are not padded to 32 bytes: x=%q y=%q", ecJWK.X, ecJWK.Y)
+	}
+	if decode(ecJWK.X).Cmp(keys.ec.X) != 0 || decode(ecJWK.Y).Cmp(keys.ec.Y) != 0 {
+		t.Error("EC key does not carry the public point")
+	}
+
+	if edJWK.KeyID != "ed" || edJWK.KeyType != "OKP" || edJWK.Algorithm != "EdDSA" || edJWK.Curve != "Ed25519" {
+		t.Errorf("Ed25519 key = %+v", edJWK)
+	}
+	if edJWK.X != base64.RawURLEncoding.EncodeToString(keys.ed25519.Public().(ed25519.PublicKey)) {
+		t.Error("Ed25519 key does not carry the public key")
+	}
+}
@@ -0,0 +1,312 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+var (
+	ErrMFARequired       = errors.New("multi-factor authentication required")
+	ErrInvalidMFAToken   = errors.New("invalid or expired MFA token")
+	ErrInvalidMFACode    = errors.New("invalid MFA code")
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled    = errors.New("two-factor authentication has not been set up")
+)
+
+const (
+	// mfaTokenExpiry is how long a user has to enter their second factor after the password was accepted.
+	mfaTokenExpiry = 5 * time.Minute
+	// recoveryCodeCount is the number of recovery codes issued when TOTP is confirmed.
+	recoveryCodeCount = 10
+)
+
+// recoveryCodeEncoding yields codes without easily confused padding characters.
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MFAChallengeError is returned by Login in place of tokens when the user has a second factor enrolled.
+// Token is a short-lived "mfa pending" token to be exchanged, together with a code, via VerifyMFA.
+// It matches ErrMFARequired with errors.Is.
+type MFAChallengeError struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+func (e *MFAChallengeError) Error() string { return ErrMFARequired.Error() }
+
+func (e *MFAChallengeError) Is(target error) bool { return target == ErrMFARequired }
+
+// TOTPEnrollment holds what a user needs to add the account to an authenticator app.
+type TOTPEnrollment struct {
+	Secret string
+	URI    string // otpauth:// URI, usually rendered as a QR code
+}
+
+// EnrollTOTP generates a new TOTP secret for the user. The secret has no effect
+// until it is confirmed with ConfirmTOTP; enrolling again replaces a pending secret.
+func (s *AuthService) EnrollTOTP(ctx context.Context, user *db.User) (*TOTPEnrollment, error) {
+	if user.TotpEnabledAt.Valid {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
+	_, err = s.mfaStore.SetUserTOTPSecret(ctx, db.SetUserTOTPSecretParams{
+		ID:         user.ID,
+		TotpSecret: pgtype.Text{String: secret, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, fmt.Errorf("failed to store TOTP secret: %w", err)
+	}
+
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    totpURI(s.totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables TOTP after checking a first code from the user's authenticator.
+// It returns freshly generated recovery codes; they are stored hashed and cannot be shown again.
+func (s *AuthService) ConfirmTOTP(ctx context.Context, user *db.User, code string) ([]string, error) {
+	if user.TotpEnabledAt.Valid {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if !user.TotpSecret.Valid {
+		return nil, ErrMFANotEnrolled
+	}
+
+	step, ok := validateTOTP(user.TotpSecret.String, normalizeCode(code), time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	_, err := s.mfaStore.EnableUserTOTP(ctx, db.EnableUserTOTPParams{
+		ID:               user.ID,
+		TotpLastUsedStep: pgtype.Int8{Int64: step, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, fmt.Errorf("failed to enable TOTP: %w", err)
+	}
+
+	return s.replaceRecoveryCodes(ctx, user.ID)
+}
+
+// DisableTOTP turns off the second factor. The user has to prove possession of it
+// with a current TOTP code or an unused recovery code. Wrong codes are throttled
+// together with those sent to VerifyMFA.
+func (s *AuthService) DisableTOTP(ctx context.Context, user *db.User, code string, client ClientInfo) error {
+	if !user.TotpEnabledAt.Valid {
+		return ErrMFANotEnrolled
+	}
+
+	if _, err := s.verifySecondFactorThrottled(ctx, user, code, client); err != nil {
+		return err
+	}
+
+	if err := s.mfaStore.DisableUserTOTP(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to disable TOTP: %w", err)
+	}
+	if err := s.mfaStore.DeleteMFARecoveryCodes(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	return nil
+}
+
+// VerifyMFA completes a two-step login. It exchanges the mfa pending token handed out
+// by Login, together with a TOTP or recovery code, for a full token pair.
+// Wrong codes are throttled like failed logins; see ThrottleConfig.
+func (s *AuthService) VerifyMFA(ctx context.Context, mfaToken, code string, client ClientInfo) (*TokenPair, *db.User, error) {
+	claims, err := s.parseClaims(mfaToken, TokenTypeMFAPending)
+	if err != nil {
+		return nil, nil, ErrInvalidMFAToken
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, nil, ErrInvalidMFAToken
+	}
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, nil, ErrInvalidMFAToken
+	}
+
+	revoked, err := s.revokedTokenStore.IsTokenRevoked(ctx, tokenID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check MFA token revocation: %w", err)
+	}
+	if revoked {
+		return nil, nil, ErrInvalidMFAToken
+	}
+
+	user, err := s.userStore.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil, ErrInvalidMFAToken
+		}
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.TotpEnabledAt.Valid {
+		return nil, nil, ErrInvalidMFAToken
+	}
+	if user.SuspendedAt.Valid {
+		return nil, nil, ErrAccountSuspended
+	}
+
+	method, err := s.verifySecondFactorThrottled(ctx, &user, code, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The pending token is single use.
+	err = s.revokedTokenStore.RevokeToken(ctx, db.RevokeTokenParams{
+		Jti:       tokenID,
+		UserID:    pgtype.UUID{Bytes: userID, Valid: true},
+		ExpiresAt: pgtype.Timestamptz{Time: claims.ExpiresAt.Time, Valid: true},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to revoke MFA token: %w", err)
+	}
+
+	authMethods := append(slices.Clone(claims.AuthMethods), method, AuthMethodMFA)
+	tokens, err := s.startSession(ctx, &user, client, authMethods)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, &user, nil
+}
+
+// issueMFAChallenge mints the short-lived token a user exchanges for real tokens at VerifyMFA.
+// authMethods records how the user passed the first factor.
+func (s *AuthService) issueMFAChallenge(user *db.User, authMethods []string) error {
+	claims := s.newClaims(user.ID.String(), TokenTypeMFAPending, mfaTokenExpiry)
+	claims.AuthMethods = authMethods
+
+	token, err := s.keys.Sign(claims)
+	if err != nil {
+		return fmt.Errorf("failed to sign MFA token: %w", err)
+	}
+
+	return &MFAChallengeError{Token: token, ExpiresAt: claims.ExpiresAt.Time}
+}
+
+// verifySecondFactorThrottled runs verifySecondFactor behind the MFA throttle.
+// Codes are short enough to guess, so failures are counted per user rather than per
+// MFA token, and per client IP.
+func (s *AuthService) verifySecondFactorThrottled(ctx context.Context, user *db.User, code string, client ClientInfo) (string, error) {
+	throttleKeys := []throttleKey{
+		{scope: store.LoginScopeMFA, identifier: user.ID.String()},
+		{scope: store.LoginScopeIP, identifier: client.IP},
+	}
+	if err := s.checkThrottle(ctx, throttleKeys...); err != nil {
+		return "", err
+	}
+
+	method, err := s.verifySecondFactor(ctx, user, code)
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			if err := s.recordFailure(ctx, throttleKeys...); err != nil {
+				return "", err
+			}
+		}
+		return "", err
+	}
+	if err := s.clearFailures(ctx, throttleKeys[0]); err != nil {
+		return "", err
+	}
+	return method, nil
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code
+// and returns the amr value describing which one was used.
+func (s *AuthService) verifySecondFactor(ctx context.Context, user *db.User, code string) (string, error) {
+	code = normalizeCode(code)
+
+	if len(code) == totpDigits {
+		step, ok := validateTOTP(user.TotpSecret.String, code, time.Now())
+		if !ok {
+			return "", ErrInvalidMFACode
+		}
+		// Each code is only accepted once, even within its validity window.
+		n, err := s.mfaStore.RecordUserTOTPStep(ctx, db.RecordUserTOTPStepParams{Step: step, ID: user.ID})
+		if err != nil {
+			return "", fmt.Errorf("failed to record TOTP step: %w", err)
+		}
+		if n == 0 {
+			return "", ErrInvalidMFACode
+		}
+		return AuthMethodOTP, nil
+	}
+
+	n, err := s.mfaStore.UseMFARecoveryCode(ctx, db.UseMFARecoveryCodeParams{
+		UserID:   user.ID,
+		CodeHash: hashToken(code),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	if n == 0 {
+		return "", ErrInvalidMFACode
+	}
+	return AuthMethodRecoveryCode, nil
+}
+
+// replaceRecoveryCodes discards the user's recovery codes and issues a new set.
+func (s *AuthService) replaceRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	if err := s.mfaStore.DeleteMFARecoveryCodes(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		err = s.mfaStore.CreateMFARecoveryCode(ctx, db.CreateMFARecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: hashToken(normalizeCode(code)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to store recovery code: %w", err)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode returns a random code formatted as two groups of five characters.
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeCode strips the formatting users tend to type around codes.
+func normalizeCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer(" ", "", "-", "").Replace(code)
+}
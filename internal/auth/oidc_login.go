@@ -0,0 +1,281 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/oidc"
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+var (
+	ErrUnknownOIDCProvider = errors.New("unknown OpenID Connect provider")
+	ErrInvalidOIDCState    = errors.New("invalid or expired OpenID Connect login")
+	// ErrOIDCLoginFailed is returned when the provider refuses the authorization code or its ID token is not valid.
+	ErrOIDCLoginFailed = errors.New("OpenID Connect login failed")
+	// ErrOIDCEmailNotVerified is returned when a provider account that is not linked yet has no verified email address.
+	ErrOIDCEmailNotVerified = errors.New("the identity provider did not verify the email address")
+	// ErrOIDCAccountNotLinkable is returned when the provider's email address belongs to an account
+	// whose owner has not verified it, so the account cannot be assumed to belong to the same person.
+	ErrOIDCAccountNotLinkable = errors.New("an account with this email address exists but its address is not verified")
+)
+
+const (
+	// oidcLoginExpiry is how long a user has to sign in at the provider and come back.
+	oidcLoginExpiry = 10 * time.Minute
+	// oidcStateBytes is the amount of entropy in the state parameter.
+	oidcStateBytes = 32
+	// federatedUsernameAttempts is how often a random suffix is tried when a new user's username is taken.
+	federatedUsernameAttempts = 5
+)
+
+// unusablePasswordHash is stored for users who signed up through an OpenID Connect provider.
+// No password verifies against it; the user can set one through the password reset flow.
+const unusablePasswordHash = "!"
+
+// OIDCAuthorization is a started OpenID Connect login.
+// The user is sent to URL; State comes back with the authorization code and must be passed
+// to CompleteOIDCLogin together with it.
+type OIDCAuthorization struct {
+	URL       string
+	State     string
+	ExpiresAt time.Time
+}
+
+// OIDCProviders returns the names of the configured OpenID Connect providers, sorted.
+func (s *AuthService) OIDCProviders() []string {
+	names := make([]string, 0, len(s.oidcProviders))
+	for name := range s.oidcProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StartOIDCLogin begins signing in through the named provider. The nonce and PKCE code verifier
+// never leave the server; only a digest of the state is stored.
+func (s *AuthService) StartOIDCLogin(ctx context.Context, providerName string) (*OIDCAuthorization, error) {
+	provider, ok := s.oidcProviders[providerName]
+	if !ok {
+		return nil, ErrUnknownOIDCProvider
+	}
+
+	state, err := generateToken(oidcStateBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate OpenID Connect state: %w", err)
+	}
+	nonce, err := oidc.NewNonce()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate OpenID Connect nonce: %w", err)
+	}
+	codeVerifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate PKCE code verifier: %w", err)
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(oidcLoginExpiry)
+	err = s.oidcLoginStateStore.CreateOIDCLoginState(ctx, db.CreateOIDCLoginStateParams{
+		Provider:     providerName,
+		StateHash:    hashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store OpenID Connect login: %w", err)
+	}
+
+	return &OIDCAuthorization{URL: authURL, State: state, ExpiresAt: expiresAt}, nil
+}
+
+// CompleteOIDCLogin finishes a login started with StartOIDCLogin, once the provider has
+// redirected the user back with an authorization code. The provider account is matched to
+// a user through an earlier login, or else through a verified email address; if no user has
+// that address, a new one is created. Like Login, it returns an *MFAChallengeError for users
+// with TOTP enabled.
+func (s *AuthService) CompleteOIDCLogin(ctx context.Context, providerName, code, state string, client ClientInfo) (*TokenPair, *db.User, error) {
+	provider, ok := s.oidcProviders[providerName]
+	if !ok {
+		return nil, nil, ErrUnknownOIDCProvider
+	}
+
+	// Consuming the state makes it single use, whatever happens next.
+	login, err := s.oidcLoginStateStore.ConsumeOIDCLoginState(ctx, hashToken(state))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil, ErrInvalidOIDCState
+		}
+		return nil, nil, fmt.Errorf("failed to get OpenID Connect login: %w", err)
+	}
+	if login.Provider != providerName || time.Now().After(login.ExpiresAt.Time) {
+		return nil, nil, ErrInvalidOIDCState
+	}
+
+	rawIDToken, err := provider.Exchange(ctx, code, login.CodeVerifier)
+	if err != nil {
+		if errors.Is(err, oidc.ErrCodeRejected) || errors.Is(err, oidc.ErrInvalidIDToken) {
+			return nil, nil, fmt.Errorf("%w: %w", ErrOIDCLoginFailed, err)
+		}
+		return nil, nil, err
+	}
+	claims, err := provider.VerifyIDToken(ctx, rawIDToken, login.Nonce)
+	if err != nil {
+		if errors.Is(err, oidc.ErrInvalidIDToken) {
+			return nil, nil, fmt.Errorf("%w: %w", ErrOIDCLoginFailed, err)
+		}
+		return nil, nil, err
+	}
+
+	user, err := s.userForIdentity(ctx, provider, claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user.SuspendedAt.Valid {
+		return nil, nil, ErrAccountSuspended
+	}
+
+	authMethods := []string{AuthMethodFederated}
+	if user.TotpEnabledAt.Valid {
+		return nil, nil, s.issueMFAChallenge(user, authMethods)
+	}
+
+	tokens, err := s.startSession(ctx, user, client, authMethods)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, user, nil
+}
+
+// userForIdentity returns the user a provider account belongs to, linking or creating one
+// on the account's first login.
+func (s *AuthService) userForIdentity(ctx context.Context, provider *oidc.Provider, claims *oidc.Claims) (*db.User, error) {
+	identity, err := s.userIdentityStore.GetUserIdentity(ctx, db.GetUserIdentityParams{
+		Provider: provider.Name(),
+		Subject:  claims.Subject,
+	})
+	if err == nil {
+		err = s.userIdentityStore.TouchUserIdentity(ctx, db.TouchUserIdentityParams{ID: identity.ID, Email: claims.Email})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update identity: %w", err)
+		}
+		user, err := s.userStore.GetUserByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		return &user, nil
+	}
+	if !errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	// Without a verified address, anyone could claim an existing account by entering its email at the provider.
+	if claims.Email == "" || !(bool(claims.EmailVerified) || provider.TrustsEmail()) {
+		return nil, ErrOIDCEmailNotVerified
+	}
+
+	user, err := s.userStore.GetUserByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		// Someone may have registered the address without owning it, waiting for its owner to sign in.
+		if !user.EmailVerifiedAt.Valid {
+			return nil, ErrOIDCAccountNotLinkable
+		}
+	case errors.Is(err, store.ErrNotFound):
+		user, err = s.createFederatedUser(ctx, claims)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	_, err = s.userIdentityStore.CreateUserIdentity(ctx, db.CreateUserIdentityParams{
+		UserID:   user.ID,
+		Provider: provider.Name(),
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	return &user, nil
+}
+
+// createFederatedUser creates a user without a password for a provider account,
+// with the provider's email address marked verified.
+func (s *AuthService) createFederatedUser(ctx context.Context, claims *oidc.Claims) (db.User, error) {
+	base := federatedUsername(claims)
+	username := base
+
+	for attempt := 0; ; attempt++ {
+		user, err := s.userStore.CreateUser(ctx, db.CreateUserParams{
+			Username:     username,
+			Email:        claims.Email,
+			PasswordHash: unusablePasswordHash,
+		})
+		if err == nil {
+			_, err = s.userStore.MarkUserEmailVerified(ctx, db.MarkUserEmailVerifiedParams{ID: user.ID, Email: user.Email})
+			if err != nil {
+				return db.User{}, fmt.Errorf("failed to mark email as verified: %w", err)
+			}
+			user.EmailVerifiedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+			return user, nil
+		}
+		if !errors.Is(err, store.ErrConflict) {
+			return db.User{}, fmt.Errorf("failed to create user: %w", err)
+		}
+		if attempt == federatedUsernameAttempts {
+			return db.User{}, ErrUserAlreadyExists
+		}
+
+		suffix := make([]byte, 3)
+		if _, err := rand.Read(suffix); err != nil {
+			return db.User{}, fmt.Errorf("failed to generate username suffix: %w", err)
+		}
+		username = base + "-" + hex.EncodeToString(suffix)
+	}
+}
+
+// federatedUsername derives a username from the provider's preferred username or the email address,
+// keeping it within the limits registration enforces.
+func federatedUsername(claims *oidc.Claims) string {
+	candidate := claims.PreferredUsername
+	if candidate == "" || strings.Contains(candidate, "@") {
+		candidate, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(candidate) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' || r == '-' {
+			b.WriteRune(r)
+		}
+	}
+	username := b.String()
+	for len(username) > 40 { // Leaves room for a suffix
+		_, size := utf8.DecodeLastRuneInString(username)
+		username = username[:len(username)-size]
+	}
+	if len(username) < 3 {
+		username = "user" + username
+	}
+	return username
+}
@@ -0,0 +1,292 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"go-api-structure/internal/oidc"
+	"go-api-structure/internal/oidc/oidctest"
+	"go-api-structure/internal/store/db"
+)
+
+// oidcTest is an AuthService that can sign users in through a mock provider named "test".
+type oidcTest struct {
+	st       *fakeStore
+	s        *AuthService
+	provider *oidctest.Server
+}
+
+func newOIDCTest(t *testing.T, trustEmail bool) *oidcTest {
+	t.Helper()
+	provider := oidctest.NewServer(t, "api", "api secret")
+	st := newFakeStore()
+	s := newTestService(t, st, func(cfg *Config) {
+		cfg.OIDCProviders = []*oidc.Provider{oidc.NewProvider(oidc.Config{
+			Name:         "test",
+			Issuer:       provider.URL,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  "https://app.example.com/login/test/callback",
+			Scopes:       []string{"email", "profile"},
+			TrustEmail:   trustEmail,
+		}, provider.Client())}
+	})
+	return &oidcTest{st: st, s: s, provider: provider}
+}
+
+// login starts a login, signs in at the provider as identity and completes the login.
+func (o *oidcTest) login(t *testing.T, identity oidctest.Identity) (*TokenPair, *db.User, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	authorization, err := o.s.StartOIDCLogin(ctx, "test")
+	if err != nil {
+		t.Fatalf("StartOIDCLogin() error = %v", err)
+	}
+	code, state, err := o.provider.Login(authorization.URL, identity)
+	if err != nil {
+		t.Fatalf("provider login: %v", err)
+	}
+	if state != authorization.State {
+		t.Fatalf("provider returned state %q, want %q", state, authorization.State)
+	}
+	return o.s.CompleteOIDCLogin(ctx, "test", code, state, ClientInfo{IP: "192.0.2.1"})
+}
+
+var jane = oidctest.Identity{Subject: "jane-at-idp", Email: "jane@example.com", EmailVerified: true, PreferredUsername: "Jane.Doe"}
+
+func TestOIDCLoginNewUser(t *testing.T) {
+	o := newOIDCTest(t, false)
+
+	tokens, user, err := o.login(t, jane)
+	if err != nil {
+		t.Fatalf("CompleteOIDCLogin() error = %v", err)
+	}
+	if user.Email != jane.Email || user.Username != "jane.doe" {
+		t.Errorf("new user = %q <%s>, want jane.doe <%s>", user.Username, user.Email, jane.Email)
+	}
+	if !user.EmailVerifiedAt.Valid || !o.st.user(user.ID).EmailVerifiedAt.Valid {
+		t.Error("the provider's verified address was not marked verified")
+	}
+	if user.PasswordHash != unusablePasswordHash {
+		t.Errorf("new user has password hash %q, want none", user.PasswordHash)
+	}
+	if len(o.st.identities) != 1 || o.st.identities[0].UserID != user.ID || o.st.identities[0].Subject != jane.Subject {
+		t.Errorf("identities = %+v, want one linking %s to the new user", o.st.identities, jane.Subject)
+	}
+
+	claims, err := o.s.parseClaims(tokens.AccessToken, TokenTypeAccess)
+	if err != nil {
+		t.Fatalf("access token: %v", err)
+	}
+	if claims.Subject != user.ID.String() || !slices.Equal(claims.AuthMethods, []string{AuthMethodFederated}) {
+		t.Errorf("access token sub = %s amr = %v, want %s [%s]", claims.Subject, claims.AuthMethods, user.ID, AuthMethodFederated)
+	}
+
+	// A password login must not work for an account without a password.
+	if _, _, err := o.s.Login(context.Background(), jane.Email, unusablePasswordHash, ClientInfo{}); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Login() error = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestOIDCLoginReturningUser(t *testing.T) {
+	o := newOIDCTest(t, false)
+
+	_, first, err := o.login(t, jane)
+	if err != nil {
+		t.Fatalf("first CompleteOIDCLogin() error = %v", err)
+	}
+
+	// The identity is matched by subject, even after the address changed at the provider.
+	changed := jane
+	changed.Email, changed.EmailVerified = "jane.doe@example.org", false
+	_, second, err := o.login(t, changed)
+	if err != nil {
+		t.Fatalf("second CompleteOIDCLogin() error = %v", err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("second login signed in user %v, want %v", second.ID, first.ID)
+	}
+	if len(o.st.users) != 1 || len(o.st.identities) != 1 {
+		t.Errorf("%d users and %d identities, want one of each", len(o.st.users), len(o.st.identities))
+	}
+	if o.st.identities[0].Email != changed.Email {
+		t.Errorf("identity email = %q, want it updated to %q", o.st.identities[0].Email, changed.Email)
+	}
+}
+
+func TestOIDCLoginLinking(t *testing.T) {
+	tests := []struct {
+		name          string
+		trustEmail    bool
+		identity      oidctest.Identity
+		existingEmail string
+		verified      bool
+		wantErr       error
+		wantLinked    bool
+	}{
+		{"verified account", false, jane, jane.Email, true, nil, true},
+		{"address differs in case", false, jane, "Jane@Example.com", true, nil, true},
+		{"unverified account", false, jane, jane.Email, false, ErrOIDCAccountNotLinkable, false},
+		{"address not verified by the provider", false, oidctest.Identity{Subject: "x", Email: jane.Email}, jane.Email, true, ErrOIDCEmailNotVerified, false},
+		{"trusted provider", true, oidctest.Identity{Subject: "x", Email: jane.Email}, jane.Email, true, nil, true},
+		{"no address", true, oidctest.Identity{Subject: "x"}, jane.Email, true, ErrOIDCEmailNotVerified, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOIDCTest(t, tt.trustEmail)
+			existing := o.st.addUser(tt.existingEmail, hashPassword(t, "password"))
+			if !tt.verified {
+				o.st.updateUser(existing.ID, func(u *db.User) { u.EmailVerifiedAt.Valid = false })
+			}
+
+			_, user, err := o.login(t, tt.identity)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CompleteOIDCLogin() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantLinked && user.ID != existing.ID {
+				t.Errorf("signed in user %v, want the existing account %v", user.ID, existing.ID)
+			}
+			if linked := len(o.st.identities) == 1; linked != tt.wantLinked {
+				t.Errorf("identity linked = %v, want %v", linked, tt.wantLinked)
+			}
+			if len(o.st.users) != 1 {
+				t.Errorf("%d users, want no new one", len(o.st.users))
+			}
+		})
+	}
+}
+
+func TestOIDCLoginRejected(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		run     func(t *testing.T, o *oidcTest) error
+		wantErr error
+	}{
+		{"unknown provider", func(t *testing.T, o *oidcTest) error {
+			_, err := o.s.StartOIDCLogin(ctx, "other")
+			return err
+		}, ErrUnknownOIDCProvider},
+		{"unknown state", func(t *testing.T, o *oidcTest) error {
+			_, _, err := o.s.CompleteOIDCLogin(ctx, "test", "code", "made up", ClientInfo{})
+			return err
+		}, ErrInvalidOIDCState},
+		{"state used twice", func(t *testing.T, o *oidcTest) error {
+			authorization, err := o.s.StartOIDCLogin(ctx, "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			code, state, err := o.provider.Login(authorization.URL, jane)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := o.s.CompleteOIDCLogin(ctx, "test", code, state, ClientInfo{}); err != nil {
+				t.Fatalf("first CompleteOIDCLogin() error = %v", err)
+			}
+			_, _, err = o.s.CompleteOIDCLogin(ctx, "test", code, state, ClientInfo{})
+			return err
+		}, ErrInvalidOIDCState},
+		{"expired state", func(t *testing.T, o *oidcTest) error {
+			authorization, err := o.s.StartOIDCLogin(ctx, "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			o.st.mu.Lock()
+			for hash, login := range o.st.oidcLogins {
+				login.ExpiresAt.Time = time.Now().Add(-time.Second)
+				o.st.oidcLogins[hash] = login
+			}
+			o.st.mu.Unlock()
+			code, state, err := o.provider.Login(authorization.URL, jane)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _, err = o.s.CompleteOIDCLogin(ctx, "test", code, state, ClientInfo{})
+			return err
+		}, ErrInvalidOIDCState},
+		{"code rejected by the provider", func(t *testing.T, o *oidcTest) error {
+			authorization, err := o.s.StartOIDCLogin(ctx, "test")
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _, err = o.s.CompleteOIDCLogin(ctx, "test", "made up", authorization.State, ClientInfo{})
+			return err
+		}, ErrOIDCLoginFailed},
+		{"ID token for another login", func(t *testing.T, o *oidcTest) error {
+			o.provider.ModifyIDTokens(func(claims jwt.MapClaims) { claims["nonce"] = "replayed" })
+			_, _, err := o.login(t, jane)
+			return err
+		}, ErrOIDCLoginFailed},
+		{"ID token for another client", func(t *testing.T, o *oidcTest) error {
+			o.provider.ModifyIDTokens(func(claims jwt.MapClaims) { claims["aud"] = "other" })
+			_, _, err := o.login(t, jane)
+			return err
+		}, ErrOIDCLoginFailed},
+		{"expired ID token", func(t *testing.T, o *oidcTest) error {
+			o.provider.ModifyIDTokens(func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() })
+			_, _, err := o.login(t, jane)
+			return err
+		}, ErrOIDCLoginFailed},
+		{"suspended user", func(t *testing.T, o *oidcTest) error {
+			_, user, err := o.login(t, jane)
+			if err != nil {
+				t.Fatal(err)
+			}
+			o.st.updateUser(user.ID, func(u *db.User) { u.SuspendedAt = timestampNow() })
+			_, _, err = o.login(t, jane)
+			return err
+		}, ErrAccountSuspended},
+		{"second factor enrolled", func(t *testing.T, o *oidcTest) error {
+			_, user, err := o.login(t, jane)
+			if err != nil {
+				t.Fatal(err)
+			}
+			o.st.updateUser(user.ID, func(u *db.User) { u.TotpEnabledAt = timestampNow() })
+			_, _, err = o.login(t, jane)
+			var challenge *MFAChallengeError
+			if errors.As(err, &challenge) && challenge.Token == "" {
+				t.Error("MFA challenge without a token")
+			}
+			return err
+		}, ErrMFARequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOIDCTest(t, false)
+			if err := tt.run(t, o); !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFederatedUsername(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims oidc.Claims
+		want   string
+	}{
+		{"preferred username", oidc.Claims{PreferredUsername: "Jane.Doe", Email: "jdoe@example.com"}, "jane.doe"},
+		{"email as preferred username", oidc.Claims{PreferredUsername: "jane@corp.example.com", Email: "jdoe@example.com"}, "jdoe"},
+		{"no preferred username", oidc.Claims{Email: "jane_doe@example.com"}, "jane_doe"},
+		{"disallowed characters", oidc.Claims{PreferredUsername: "jane doe+test!"}, "janedoetest"},
+		{"too short", oidc.Claims{Email: "j@example.com"}, "userj"},
+		{"nothing usable", oidc.Claims{PreferredUsername: "!!!"}, "user"},
+		{"too long", oidc.Claims{PreferredUsername: "abcdefghijklmnopqrstuvwxyzabcdefghijklmnopqrstuvwxyz"}, "abcdefghijklmnopqrstuvwxyzabcdefghijklmn"},
+		{"multibyte cut on a rune boundary", oidc.Claims{PreferredUsername: "ééééééééééééééééééééééééééééé"}, "éééééééééééééééééééé"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := federatedUsername(&tt.claims); got != tt.want {
+				t.Errorf("federatedUsername() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
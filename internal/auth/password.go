@@ -1,24 +1,147 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
 	"fmt"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
+
+	"go-api-structure/internal/store/db"
 )
 
-// HashPassword generates a bcrypt hash of the password.
-// It uses the default cost for bcrypt.
-func HashPassword(password string) (string, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+// PasswordHasher hashes passwords for storage and checks passwords against stored hashes.
+type PasswordHasher interface {
+	// Hash returns an encoded hash of password that Verify accepts.
+	Hash(password string) (string, error)
+	// Verify reports whether password matches the encoded hash, and whether a matching hash
+	// is outdated and should be replaced with a fresh one from Hash.
+	Verify(password, encoded string) (match, rehash bool)
+}
+
+// Argon2Params are the argon2id cost parameters, see RFC 9106.
+type Argon2Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params follows the OWASP recommendation of 19 MiB, two passes and one lane.
+var DefaultArgon2Params = Argon2Params{
+	Memory:      19 * 1024,
+	Iterations:  2,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Argon2idHasher writes argon2id hashes in the PHC string format, e.g.
+// $argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>. It also verifies bcrypt hashes
+// written before argon2id was introduced and reports them as outdated, as it does
+// argon2id hashes written with different parameters.
+type Argon2idHasher struct {
+	params Argon2Params
+}
+
+// NewArgon2idHasher creates an Argon2idHasher that hashes with params.
+func NewArgon2idHasher(params Argon2Params) *Argon2idHasher {
+	return &Argon2idHasher{params: params}
+}
+
+// Hash returns the PHC encoded argon2id hash of password with a random salt.
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify checks password against an argon2id or bcrypt hash.
+func (h *Argon2idHasher) Verify(password, encoded string) (match, rehash bool) {
+	if isBcryptHash(encoded) {
+		return bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password)) == nil, true
+	}
+
+	params, salt, key, err := decodeArgon2idHash(encoded)
+	if err != nil {
+		return false, false
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return false, false
+	}
+
+	outdated := params.Memory != h.params.Memory ||
+		params.Iterations != h.params.Iterations ||
+		params.Parallelism != h.params.Parallelism ||
+		params.SaltLength < h.params.SaltLength ||
+		params.KeyLength != h.params.KeyLength
+	return true, outdated
+}
+
+// isBcryptHash reports whether encoded is in the modular crypt format bcrypt uses.
+func isBcryptHash(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+// decodeArgon2idHash parses a PHC encoded argon2id hash.
+func decodeArgon2idHash(encoded string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != "argon2id" {
+		return params, nil, nil, fmt.Errorf("not an argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version %q", parts[2])
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
 	if err != nil {
-		return "", fmt.Errorf("failed to hash password: %w", err)
+		return params, nil, nil, fmt.Errorf("invalid argon2 hash: %w", err)
 	}
-	return string(hashedPassword), nil
+	if len(key) == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, fmt.Errorf("invalid argon2 parameters")
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
+
+// dummyPassword is hashed once per service so that requests without an account
+// to check a password against can do the same work as a wrong password.
+const dummyPassword = "not a real password"
+
+// checkDummyPassword does the work of checking password without an account to check it against.
+func (s *AuthService) checkDummyPassword(password string) {
+	_, _ = s.passwords.Verify(password, s.dummyPasswordHash())
 }
 
-// CheckPasswordHash compares a plaintext password with a bcrypt hash.
-// It returns true if the password matches the hash, and false otherwise.
-func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+// checkPassword reports whether password is the user's current password.
+func (s *AuthService) checkPassword(password string, user *db.User) bool {
+	match, _ := s.passwords.Verify(password, user.PasswordHash)
+	return match
 }
@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"context"
+
+	"go-api-structure/internal/passwordpolicy"
+	"go-api-structure/internal/store/db"
+)
+
+// ChangePassword replaces the user's password after checking the current one.
+// It returns ErrInvalidCredentials if currentPassword is wrong, and a *passwordpolicy.Error
+// or ErrPasswordReused if newPassword is not acceptable.
+func (s *AuthService) ChangePassword(ctx context.Context, user *db.User, currentPassword, newPassword string) error {
+	if !s.checkPassword(currentPassword, user) {
+		return ErrInvalidCredentials
+	}
+
+	account := passwordpolicy.Account{Username: user.Username, Email: user.Email}
+	if err := s.checkNewPassword(ctx, newPassword, account, user); err != nil {
+		return err
+	}
+
+	return s.setPassword(ctx, user, newPassword)
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-api-structure/internal/passwordpolicy"
+	"go-api-structure/internal/store/db"
+)
+
+var ErrPasswordReused = errors.New("password was used recently")
+
+// checkNewPassword runs the password policy against a password about to be set.
+// For an existing user it also rejects the current password and, if a password history
+// is kept, the previous ones with ErrPasswordReused. Policy violations are returned
+// as *passwordpolicy.Error.
+func (s *AuthService) checkNewPassword(ctx context.Context, password string, account passwordpolicy.Account, user *db.User) error {
+	if s.passwordPolicy != nil {
+		if err := s.passwordPolicy.Check(password, account); err != nil {
+			return err
+		}
+	}
+
+	if user == nil || s.passwordHistory <= 0 {
+		return nil
+	}
+
+	if match, _ := s.passwords.Verify(password, user.PasswordHash); match {
+		return ErrPasswordReused
+	}
+	if s.passwordHistory == 1 {
+		return nil
+	}
+
+	previous, err := s.passwordHistoryStore.ListPasswordHistory(ctx, db.ListPasswordHistoryParams{
+		UserID: user.ID,
+		Limit:  int32(s.passwordHistory - 1),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list password history: %w", err)
+	}
+	for _, entry := range previous {
+		if match, _ := s.passwords.Verify(password, entry.PasswordHash); match {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// setPassword stores a new password hash for the user, moving the current one into
+// the password history if one is kept.
+func (s *AuthService) setPassword(ctx context.Context, user *db.User, password string) error {
+	hashedPassword, err := s.passwords.Hash(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := s.userStore.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{ID: user.ID, PasswordHash: hashedPassword}); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	// The current password counts as the first of the last N, so N-1 previous ones are kept.
+	if keep := s.passwordHistory - 1; keep > 0 {
+		err := s.passwordHistoryStore.AddPasswordHistory(ctx, db.AddPasswordHistoryParams{
+			UserID:       user.ID,
+			PasswordHash: user.PasswordHash,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add password history: %w", err)
+		}
+		err = s.passwordHistoryStore.PrunePasswordHistory(ctx, db.PrunePasswordHistoryParams{
+			UserID: user.ID,
+			Keep:   int32(keep),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to prune password history: %w", err)
+		}
+	}
+
+	user.PasswordHash = hashedPassword
+	return nil
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/mailer"
+	"go-api-structure/internal/passwordpolicy"
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+var ErrInvalidPasswordResetToken = errors.New("invalid or expired password reset token")
+
+// passwordResetTokenBytes is the amount of entropy in a password reset token.
+const passwordResetTokenBytes = 32
+
+// ForgotPassword emails a password reset link to the account registered with email.
+// It silently does nothing if there is no such account, so callers cannot use it
+// to find out which addresses are registered. Requesting a new link invalidates older ones.
+// The link is issued and sent in the background, so the response does not take longer
+// for registered addresses either.
+func (s *AuthService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := s.userStore.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	s.inBackground(ctx, "password reset email", func(ctx context.Context) error {
+		return s.sendPasswordResetEmail(ctx, &user)
+	})
+	return nil
+}
+
+// sendPasswordResetEmail issues a password reset token for user and mails them the link.
+func (s *AuthService) sendPasswordResetEmail(ctx context.Context, user *db.User) error {
+	token, err := s.issuePasswordResetToken(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password for your account. If it was you, open the link below to choose a new one:\n\n%s\n\nThe link expires in %s. If you did not ask for this, you can ignore this email.\n",
+			user.Username, s.link("/reset-password", token), s.passwordResetExpiry),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send password reset email: %w", err)
+	}
+	return nil
+}
+
+// RequirePasswordReset makes a user choose a new password before they can log in again,
+// e.g. after an administrator found their account compromised. Every session of the user
+// is revoked and a password reset link is emailed to them; Login returns ErrPasswordResetRequired
+// until the password has been reset.
+func (s *AuthService) RequirePasswordReset(ctx context.Context, user *db.User) error {
+	if err := s.userStore.RequireUserPasswordReset(ctx, user.ID); err != nil {
+		return fmt.Errorf("failed to require password reset: %w", err)
+	}
+	if err := s.RevokeAllSessions(ctx, user.ID); err != nil {
+		return err
+	}
+
+	token, err := s.issuePasswordResetToken(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	err = s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Choose a new password",
+		Body: fmt.Sprintf("Hi %s,\n\nAn administrator has asked you to choose a new password for your account. You have been logged out everywhere and can log in again once you have set a new password here:\n\n%s\n\nThe link expires in %s. If it does, request a new one at %s/forgot-password.\n",
+			user.Username, s.link("/reset-password", token), s.passwordResetExpiry, s.appBaseURL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send password reset email: %w", err)
+	}
+	return nil
+}
+
+// issuePasswordResetToken creates a new password reset token for the user, invalidating older ones.
+func (s *AuthService) issuePasswordResetToken(ctx context.Context, userID uuid.UUID) (string, error) {
+	if err := s.passwordResetStore.InvalidateUserPasswordResetTokens(ctx, userID); err != nil {
+		return "", fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+	}
+
+	token, err := generateToken(passwordResetTokenBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+
+	_, err = s.passwordResetStore.CreatePasswordResetToken(ctx, db.CreatePasswordResetTokenParams{
+		UserID:    userID,
+		TokenHash: hashToken(token),
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(s.passwordResetExpiry), Valid: true},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store password reset token: %w", err)
+	}
+	return token, nil
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword.
+// On success every session and API key of the user is revoked, since whoever
+// held the old password may have used it to create them. A new password that is rejected
+// with a *passwordpolicy.Error or ErrPasswordReused leaves the token usable.
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	resetToken, err := s.passwordResetStore.GetPasswordResetTokenByHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return ErrInvalidPasswordResetToken
+		}
+		return fmt.Errorf("failed to get password reset token: %w", err)
+	}
+	if resetToken.UsedAt.Valid || time.Now().After(resetToken.ExpiresAt.Time) {
+		return ErrInvalidPasswordResetToken
+	}
+
+	user, err := s.userStore.GetUserByID(ctx, resetToken.UserID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return ErrInvalidPasswordResetToken
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	account := passwordpolicy.Account{Username: user.Username, Email: user.Email}
+	if err := s.checkNewPassword(ctx, newPassword, account, &user); err != nil {
+		return err
+	}
+
+	// Claim the token atomically so it cannot be used twice.
+	if _, err := s.passwordResetStore.MarkPasswordResetTokenUsed(ctx, resetToken.ID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return ErrInvalidPasswordResetToken
+		}
+		return fmt.Errorf("failed to mark password reset token as used: %w", err)
+	}
+
+	if err := s.setPassword(ctx, &user, newPassword); err != nil {
+		return err
+	}
+
+	if err := s.RevokeAllSessions(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := s.apiKeyService.RevokeAll(ctx, user.ID); err != nil {
+		return err
+	}
+
+	// Following the emailed link proves ownership of the address.
+	if !user.EmailVerifiedAt.Valid {
+		_, err = s.userStore.MarkUserEmailVerified(ctx, db.MarkUserEmailVerifiedParams{ID: user.ID, Email: user.Email})
+		if err != nil {
+			return fmt.Errorf("failed to mark email as verified: %w", err)
+		}
+	}
+
+	return nil
+}
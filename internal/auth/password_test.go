@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2Params keeps hashing fast in tests.
+var testArgon2Params = Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2idHasher(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2Params)
+
+	encoded, err := hasher.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if !strings.HasPrefix(encoded, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("Hash() = %q, want a PHC argon2id string with the configured parameters", encoded)
+	}
+
+	if match, rehash := hasher.Verify("correct horse battery staple", encoded); !match || rehash {
+		t.Errorf("Verify(correct password) = %v, %v, want true, false", match, rehash)
+	}
+	if match, _ := hasher.Verify("wrong password", encoded); match {
+		t.Error("Verify(wrong password) matched")
+	}
+
+	other, err := hasher.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if other == encoded {
+		t.Error("Hash() returned the same string twice; salts are not random")
+	}
+}
+
+func TestArgon2idHasherRehash(t *testing.T) {
+	hash := func(params Argon2Params) string {
+		t.Helper()
+		encoded, err := NewArgon2idHasher(params).Hash("password")
+		if err != nil {
+			t.Fatalf("Hash() error = %v", err)
+		}
+		return encoded
+	}
+	with := func(change func(*Argon2Params)) Argon2Params {
+		params := testArgon2Params
+		change(&params)
+		return params
+	}
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		encoded    string
+		wantRehash bool
+	}{
+		{"current parameters", hash(testArgon2Params), false},
+		{"less memory", hash(with(func(p *Argon2Params) { p.Memory = 32 })), true},
+		{"more iterations", hash(with(func(p *Argon2Params) { p.Iterations = 2 })), true},
+		{"more lanes", hash(with(func(p *Argon2Params) { p.Parallelism = 2 })), true},
+		{"shorter key", hash(with(func(p *Argon2Params) { p.KeyLength = 16 })), true},
+		{"shorter salt", hash(with(func(p *Argon2Params) { p.SaltLength = 8 })), true},
+		{"longer salt", hash(with(func(p *Argon2Params) { p.SaltLength = 32 })), false},
+		{"bcrypt", string(bcryptHash), true},
+	}
+	hasher := NewArgon2idHasher(testArgon2Params)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, rehash := hasher.Verify("password", tt.encoded)
+			if !match {
+				t.Fatal("Verify() did not match")
+			}
+			if rehash != tt.wantRehash {
+				t.Errorf("Verify() rehash = %v, want %v", rehash, tt.wantRehash)
+			}
+		})
+	}
+}
+
+func TestDecodeArgon2idHash(t *testing.T) {
+	const salt, key = "c2FsdHNhbHRzYWx0c2FsdA", "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5"
+
+	params, _, _, err := decodeArgon2idHash("$argon2id$v=19$m=19456,t=2,p=1$" + salt + "$" + key)
+	if err != nil {
+		t.Fatalf("decodeArgon2idHash() error = %v", err)
+	}
+	want := Argon2Params{Memory: 19456, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 30}
+	if params != want {
+		t.Errorf("decodeArgon2idHash() params = %+v, want %+v", params, want)
+	}
+
+	invalid := []struct {
+		name    string
+		encoded string
+	}{
+		{"empty", ""},
+		{"argon2i", "$argon2i$v=19$m=19456,t=2,p=1$" + salt + "$" + key},
+		{"old version", "$argon2id$v=16$m=19456,t=2,p=1$" + salt + "$" + key},
+		{"missing version", "$argon2id$m=19456,t=2,p=1$" + salt + "$" + key},
+		{"malformed parameters", "$argon2id$v=19$m=19456;t=2;p=1$" + salt + "$" + key},
+		{"zero iterations", "$argon2id$v=19$m=19456,t=0,p=1$" + salt + "$" + key},
+		{"zero lanes", "$argon2id$v=19$m=19456,t=2,p=0$" + salt + "$" + key},
+		{"lanes out of range", "$argon2id$v=19$m=19456,t=2,p=256$" + salt + "$" + key},
+		{"padded salt", "$argon2id$v=19$m=19456,t=2,p=1$" + salt + "==$" + key},
+		{"invalid hash", "$argon2id$v=19$m=19456,t=2,p=1$" + salt + "$not base64!"},
+		{"empty hash", "$argon2id$v=19$m=19456,t=2,p=1$" + salt + "$"},
+		{"trailing field", "$argon2id$v=19$m=19456,t=2,p=1$" + salt + "$" + key + "$"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := decodeArgon2idHash(tt.encoded); err == nil {
+				t.Errorf("decodeArgon2idHash(%q) succeeded", tt.encoded)
+			}
+			if match, _ := NewArgon2idHasher(testArgon2Params).Verify("password", tt.encoded); match {
+				t.Errorf("Verify() matched %q", tt.encoded)
+			}
+		})
+	}
+}
+
+func TestLoginUpgradesPasswordHash(t *testing.T) {
+	st := newFakeStore()
+	s := newTestService(t, st, nil)
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("bcrypt error = %v", err)
+	}
+	user := st.addUser("jane@example.com", string(bcryptHash))
+
+	if _, _, err := s.Login(context.Background(), user.Email, "password", ClientInfo{IP: "192.0.2.1"}); err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	upgraded := st.user(user.ID).PasswordHash
+	if !strings.HasPrefix(upgraded, "$argon2id$") {
+		t.Fatalf("password hash after login = %q, want argon2id", upgraded)
+	}
+	if match, rehash := NewArgon2idHasher(testArgon2Params).Verify("password", upgraded); !match || rehash {
+		t.Errorf("Verify() of the upgraded hash = %v, %v, want true, false", match, rehash)
+	}
+}
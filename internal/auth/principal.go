@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// PrincipalKind tells apart the kinds of callers a request can be authenticated as.
+type PrincipalKind string
+
+const (
+	// PrincipalUser is a person signed in with a token or using one of their API keys.
+	PrincipalUser PrincipalKind = "user"
+	// PrincipalServiceAccount is a non-human account using one of its API keys.
+	PrincipalServiceAccount PrincipalKind = "service_account"
+	// PrincipalClient is an OAuth client using an access token from the client credentials grant.
+	PrincipalClient PrincipalKind = "client"
+)
+
+// Principal is whoever a request was authenticated as, whatever its kind.
+// Handlers that need the full record use GetUserFromContext, GetServiceAccountFromContext
+// or GetClientFromContext instead; only the one matching Kind returns non-nil.
+type Principal struct {
+	Kind PrincipalKind
+	ID   uuid.UUID
+	// Name is the username, or the name of the service account or client.
+	Name string
+}
+
+// GetPrincipalFromContext returns the principal the request was authenticated as,
+// or nil if it was not authenticated.
+func GetPrincipalFromContext(ctx context.Context) *Principal {
+	if user := GetUserFromContext(ctx); user != nil {
+		return &Principal{Kind: PrincipalUser, ID: user.ID, Name: user.Username}
+	}
+	if account := GetServiceAccountFromContext(ctx); account != nil {
+		return &Principal{Kind: PrincipalServiceAccount, ID: account.ID, Name: account.Name}
+	}
+	if client := GetClientFromContext(ctx); client != nil {
+		return &Principal{Kind: PrincipalClient, ID: client.ID, Name: client.Name}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used")
+)
+
+// refreshTokenBytes is the amount of entropy in a refresh token.
+const refreshTokenBytes = 32
+
+// TokenPair is the set of tokens handed to a client after authentication.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// Refresh exchanges a refresh token for a new token pair.
+// Every refresh token can be used exactly once. Presenting a token that was
+// already rotated is treated as theft and revokes the whole token family,
+// forcing both the attacker and the legitimate client to log in again.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, *db.User, error) {
+	current, err := s.refreshTokenStore.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if current.RevokedAt.Valid {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	if current.UsedAt.Valid {
+		return nil, nil, s.revokeReusedFamily(ctx, current.FamilyID)
+	}
+
+	if time.Now().After(current.ExpiresAt.Time) {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	// Claim the token atomically so two concurrent refreshes cannot both succeed.
+	if _, err := s.refreshTokenStore.MarkRefreshTokenUsed(ctx, current.ID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil, s.revokeReusedFamily(ctx, current.FamilyID)
+		}
+		return nil, nil, fmt.Errorf("failed to mark refresh token as used: %w", err)
+	}
+
+	// Logging out of a session, or its timing out, ends the refresh tokens with it.
+	session, err := s.activeSession(ctx, current.FamilyID)
+	if err != nil {
+		if errors.Is(err, ErrSessionNotFound) || errors.Is(err, ErrSessionExpired) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, err
+	}
+
+	user, err := s.userStore.GetUserByID(ctx, current.UserID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil, ErrInvalidRefreshToken
+		}
+		return nil, nil, fmt.Errorf("failed to get user for refresh token: %w", err)
+	}
+	if user.SuspendedAt.Valid {
+		return nil, nil, ErrAccountSuspended
+	}
+
+	tokens, err := s.issueTokenPair(ctx, &user, session, current.AuthMethods)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, &user, nil
+}
+
+// revokeReusedFamily revokes every token in a family after reuse was detected.
+// It returns ErrRefreshTokenReused unless the revocation itself fails.
+func (s *AuthService) revokeReusedFamily(ctx context.Context, familyID uuid.UUID) error {
+	if err := s.refreshTokenStore.RevokeRefreshTokenFamily(ctx, familyID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return ErrRefreshTokenReused
+}
+
+// issueTokenPair creates an access token and a refresh token belonging to session; the session's
+// ID is the refresh token's family. The refresh token does not outlive the session.
+// authMethods is stored with the refresh token so rotated access tokens keep the same amr claim.
+func (s *AuthService) issueTokenPair(ctx context.Context, user *db.User, session *db.Session, authMethods []string) (*TokenPair, error) {
+	accessToken, err := s.issueAccessToken(ctx, user, session.ID, authMethods)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := generateToken(refreshTokenBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	expiresAt := time.Now().Add(s.refreshExpiry)
+	if session.ExpiresAt.Time.Before(expiresAt) {
+		expiresAt = session.ExpiresAt.Time
+	}
+
+	_, err = s.refreshTokenStore.CreateRefreshToken(ctx, db.CreateRefreshTokenParams{
+		UserID:      user.ID,
+		FamilyID:    session.ID,
+		TokenHash:   hashToken(refreshToken),
+		ExpiresAt:   pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		AuthMethods: authMethods,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return &TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// generateToken returns a URL-safe random string carrying n bytes of entropy.
+func generateToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex encoded SHA-256 digest of an opaque token.
+// Opaque tokens have enough entropy that a fast hash is sufficient.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go-api-structure/internal/store/db"
+)
+
+// loginForTest logs a new user in with a password and returns the user and their token pair.
+func loginForTest(t *testing.T, st *fakeStore, s *AuthService) (db.User, *TokenPair) {
+	t.Helper()
+	user := st.addUser("jane@example.com", hashPassword(t, "password"))
+	tokens, _, err := s.Login(context.Background(), user.Email, "password", ClientInfo{IP: "192.0.2.1"})
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	return user, tokens
+}
+
+func TestRefreshRotation(t *testing.T) {
+	ctx := context.Background()
+	st := newFakeStore()
+	s := newTestService(t, st, nil)
+	user, first := loginForTest(t, st, s)
+
+	second, got, err := s.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if got.ID != user.ID {
+		t.Errorf("Refresh() user = %v, want %v", got.ID, user.ID)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Error("Refresh() returned the same refresh token")
+	}
+
+	// The rotated access token stays in the same session and keeps how the user logged in.
+	before, err := s.parseClaims(first.AccessToken, TokenTypeAccess)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := s.parseClaims(second.AccessToken, TokenTypeAccess)
+	if err != nil {
+		t.Fatalf("rotated access token: %v", err)
+	}
+	if after.SessionID == "" || after.SessionID != before.SessionID {
+		t.Errorf("rotated access token sid = %q, want %q", after.SessionID, before.SessionID)
+	}
+	if !slices.Equal(after.AuthMethods, []string{AuthMethodPassword}) {
+		t.Errorf("rotated access token amr = %v, want [%s]", after.AuthMethods, AuthMethodPassword)
+	}
+
+	if _, _, err := s.Refresh(ctx, second.RefreshToken); err != nil {
+		t.Errorf("Refresh() with the rotated token error = %v", err)
+	}
+}
+
+func TestRefreshReuseDetection(t *testing.T) {
+	ctx := context.Background()
+	st := newFakeStore()
+	s := newTestService(t, st, nil)
+	_, stolen := loginForTest(t, st, s)
+
+	legitimate, _, err := s.Refresh(ctx, stolen.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	// Replaying a rotated token revokes the whole family, including the legitimate client's newer token.
+	if _, _, err := s.Refresh(ctx, stolen.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Refresh() with a used token error = %v, want ErrRefreshTokenReused", err)
+	}
+	if _, _, err := s.Refresh(ctx, legitimate.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Refresh() after reuse error = %v, want ErrInvalidRefreshToken", err)
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for _, token := range st.refreshTokens {
+		if !token.RevokedAt.Valid {
+			t.Errorf("refresh token %v was not revoked", token.ID)
+		}
+	}
+}
+
+// racingStore marks a refresh token used right after it was read, as a concurrent refresh would.
+type racingStore struct {
+	*fakeStore
+}
+
+func (r racingStore) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (db.RefreshToken, error) {
+	token, err := r.fakeStore.GetRefreshTokenByHash(ctx, tokenHash)
+	if err == nil {
+		_, err = r.fakeStore.MarkRefreshTokenUsed(ctx, token.ID)
+	}
+	return token, err
+}
+
+func TestRefreshConcurrentUse(t *testing.T) {
+	st := newFakeStore()
+	_, tokens := loginForTest(t, st, newTestService(t, st, nil))
+
+	s := newTestService(t, racingStore{st}, nil)
+	if _, _, err := s.Refresh(context.Background(), tokens.RefreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Errorf("Refresh() losing the race error = %v, want ErrRefreshTokenReused", err)
+	}
+}
+
+func TestRefreshRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(st *fakeStore, user db.User, tokens *TokenPair) string
+		wantErr error
+	}{
+		{"unknown token", func(*fakeStore, db.User, *TokenPair) string { return "made up" }, ErrInvalidRefreshToken},
+		{"access token", func(_ *fakeStore, _ db.User, tokens *TokenPair) string { return tokens.AccessToken }, ErrInvalidRefreshToken},
+		{"expired", func(st *fakeStore, _ db.User, tokens *TokenPair) string {
+			st.mu.Lock()
+			defer st.mu.Unlock()
+			for id, token := range st.refreshTokens {
+				token.ExpiresAt.Time = time.Now().Add(-time.Second)
+				st.refreshTokens[id] = token
+			}
+			return tokens.RefreshToken
+		}, ErrInvalidRefreshToken},
+		{"revoked", func(st *fakeStore, _ db.User, tokens *TokenPair) string {
+			st.mu.Lock()
+			defer st.mu.Unlock()
+			for id, token := range st.refreshTokens {
+				token.RevokedAt = timestampNow()
+				st.refreshTokens[id] = token
+			}
+			return tokens.RefreshToken
+		}, ErrInvalidRefreshToken},
+		{"session logged out", func(st *fakeStore, _ db.User, tokens *TokenPair) string {
+			st.mu.Lock()
+			defer st.mu.Unlock()
+			clear(st.sessions)
+			return tokens.RefreshToken
+		}, ErrInvalidRefreshToken},
+		{"session timed out", func(st *fakeStore, _ db.User, tokens *TokenPair) string {
+			st.mu.Lock()
+			defer st.mu.Unlock()
+			for id, session := range st.sessions {
+				session.ExpiresAt.Time = time.Now().Add(-time.Second)
+				st.sessions[id] = session
+			}
+			return tokens.RefreshToken
+		}, ErrInvalidRefreshToken},
+		{"user deleted", func(st *fakeStore, user db.User, tokens *TokenPair) string {
+			st.mu.Lock()
+			defer st.mu.Unlock()
+			delete(st.users, user.ID)
+			return tokens.RefreshToken
+		}, ErrInvalidRefreshToken},
+		{"user suspended", func(st *fakeStore, user db.User, tokens *TokenPair) string {
+			st.updateUser(user.ID, func(u *db.User) { u.SuspendedAt = timestampNow() })
+			return tokens.RefreshToken
+		}, ErrAccountSuspended},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newFakeStore()
+			s := newTestService(t, st, nil)
+			user, tokens := loginForTest(t, st, s)
+
+			if _, _, err := s.Refresh(context.Background(), tt.prepare(st, user, tokens)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Refresh() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenDoesNotOutliveSession(t *testing.T) {
+	st := newFakeStore()
+	s := newTestService(t, st, func(cfg *Config) { cfg.SessionAbsoluteTimeout = time.Hour })
+	loginForTest(t, st, s)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for _, token := range st.refreshTokens {
+		session := st.sessions[token.FamilyID]
+		if session.ID == uuid.Nil {
+			t.Fatalf("refresh token family %v is not a session", token.FamilyID)
+		}
+		if token.ExpiresAt.Time.After(session.ExpiresAt.Time) {
+			t.Errorf("refresh token expires at %v, after its session at %v", token.ExpiresAt.Time, session.ExpiresAt.Time)
+		}
+	}
+}
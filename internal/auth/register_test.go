@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-api-structure/internal/api/dto"
+	"go-api-structure/internal/mailer"
+)
+
+// failingMailer fails every message and hands it to sent.
+type failingMailer struct {
+	sent chan mailer.Message
+}
+
+func (m failingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.sent <- msg
+	return errors.New("mail server unavailable")
+}
+
+func TestRegisterConcealsExistingAccount(t *testing.T) {
+	st := newFakeStore()
+	existing := st.addUser("alice@example.com", hashPassword(t, "correct horse battery staple"))
+	s := newTestService(t, st, func(cfg *Config) { cfg.ConcealExistingAccounts = true })
+	m := failingMailer{sent: make(chan mailer.Message, 1)}
+	s.mailer = m
+
+	_, err := s.Register(context.Background(), &dto.CreateUserRequest{
+		Username: "mallory",
+		Email:    existing.Email,
+		Password: "another long passphrase",
+	})
+	if !errors.Is(err, ErrUserAlreadyExists) {
+		t.Fatalf("Register() error = %v, want ErrUserAlreadyExists even though the mailer fails", err)
+	}
+
+	select {
+	case msg := <-m.sent:
+		if msg.To != existing.Email {
+			t.Errorf("notice sent to %q, want %q", msg.To, existing.Email)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the owner of the existing account was not notified")
+	}
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+// Logout revokes the access token described by claims and ends the session it belongs to.
+// If refreshToken is non-empty and belongs to the same user, its whole family is revoked
+// as well so the client cannot silently obtain a new access token.
+// Access tokens of OAuth clients are revoked on their own; clients have no refresh tokens.
+func (s *AuthService) Logout(ctx context.Context, claims *Claims, refreshToken string) error {
+	if claims.TokenType == TokenTypeClientAccess {
+		return s.revokeClientAccessToken(ctx, claims)
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return fmt.Errorf("invalid subject claim: %w", err)
+	}
+
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return fmt.Errorf("invalid jti claim: %w", err)
+	}
+
+	if claims.ExpiresAt == nil {
+		return errors.New("token has no expiry")
+	}
+
+	err = s.revokedTokenStore.RevokeToken(ctx, db.RevokeTokenParams{
+		Jti:       tokenID,
+		UserID:    pgtype.UUID{Bytes: userID, Valid: true},
+		ExpiresAt: pgtype.Timestamptz{Time: claims.ExpiresAt.Time, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	if sessionID, err := uuid.Parse(claims.SessionID); err == nil {
+		if err := s.endSession(ctx, sessionID); err != nil {
+			return err
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	token, err := s.refreshTokenStore.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil // Nothing to revoke
+		}
+		return fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	if token.UserID != userID {
+		return nil // Never let one user revoke another user's tokens
+	}
+
+	if err := s.refreshTokenStore.RevokeRefreshTokenFamily(ctx, token.FamilyID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
+// RevokeAllSessions invalidates every access and refresh token issued to the user so far
+// and deletes their sessions.
+func (s *AuthService) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	if err := s.userStore.RevokeUserTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	if err := s.refreshTokenStore.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	if err := s.sessionStore.DeleteUserSessions(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	return nil
+}
+
+// issuedBeforeRevocation reports whether the token described by claims was issued before the user's
+// last "revoke all sessions". The iat claim only has whole seconds, so it is compared with the
+// revocation time truncated to the second: a token issued in the same second is still accepted.
+func issuedBeforeRevocation(claims *Claims, user *db.User) bool {
+	return user.TokensRevokedBefore.Valid && claims.IssuedAt != nil &&
+		claims.IssuedAt.Before(user.TokensRevokedBefore.Time.Truncate(time.Second))
+}
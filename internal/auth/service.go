@@ -4,12 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"go-api-structure/internal/api/dto" // Assuming CreateUserRequest is here
+	"go-api-structure/internal/apikey"
+	"go-api-structure/internal/audit"
+	"go-api-structure/internal/clientcert"
+	"go-api-structure/internal/mailer"
+	"go-api-structure/internal/oidc"
+	"go-api-structure/internal/passwordpolicy"
 	"go-api-structure/internal/store"
 	"go-api-structure/internal/store/db" // sqlc generated models and params
-	"go-api-structure/internal/user"     // Added for UserService
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -17,84 +24,383 @@ import (
 
 var (
 	ErrUserAlreadyExists  = errors.New("user with this email or username already exists")
+	ErrUsernameTaken      = errors.New("username is already taken")
 	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrAccountSuspended is returned when a suspended user tries to log in or refresh their tokens.
+	ErrAccountSuspended = errors.New("account is suspended")
+	// ErrPasswordResetRequired is returned by Login when an administrator requires the user to set a new password.
+	ErrPasswordResetRequired = errors.New("password must be reset before logging in")
 )
 
+// Config holds the token settings used by AuthService.
+type Config struct {
+	SigningKeys        *KeySet
+	AccessTokenExpiry  time.Duration
+	RefreshTokenExpiry time.Duration
+	Issuer             string        // Expected and issued "iss" claim
+	Audience           string        // Expected and issued "aud" claim
+	Leeway             time.Duration // Allowed clock skew when validating time based claims
+	TOTPIssuer         string        // Account issuer shown in authenticator apps
+	// AppBaseURL is the front end URL links in emails point to, e.g. https://app.example.com.
+	AppBaseURL              string
+	EmailVerificationExpiry time.Duration
+	PasswordResetExpiry     time.Duration
+	// RequireVerifiedEmail makes Login refuse accounts whose email address is not verified.
+	RequireVerifiedEmail bool
+	// ConcealExistingAccounts makes Register answer the same way for registered email addresses,
+	// notifying their owner instead of reporting a conflict.
+	ConcealExistingAccounts bool
+	// Throttle controls the delays and lockouts applied after failed logins.
+	Throttle ThrottleConfig
+	// PasswordHasher hashes and verifies passwords; it defaults to argon2id with DefaultArgon2Params.
+	PasswordHasher PasswordHasher
+	// PasswordPolicy checks passwords set at registration, reset and change; nil accepts any password.
+	PasswordPolicy passwordpolicy.Policy
+	// PasswordHistory is how many of their most recent passwords, including the current one,
+	// users cannot set again; zero allows reuse.
+	PasswordHistory int
+	// Cookies controls browser sessions kept in cookies.
+	Cookies CookieConfig
+	// OIDCProviders are the OpenID Connect providers users can sign in with.
+	OIDCProviders []*oidc.Provider
+	// ClientTokenExpiry is the lifetime of access tokens issued to OAuth clients.
+	ClientTokenExpiry time.Duration
+	// ImpersonationTokenExpiry is the lifetime of the tokens administrators get to act as another user.
+	ImpersonationTokenExpiry time.Duration
+	// AuditRecorder records every request made with an impersonation token; nil records nothing.
+	AuditRecorder *audit.Recorder
+	// SessionIdleTimeout ends sessions that have not been used for this long; zero disables it.
+	SessionIdleTimeout time.Duration
+	// SessionAbsoluteTimeout ends sessions this long after the login, however much they are used.
+	SessionAbsoluteTimeout time.Duration
+	// ClientCertificates resolves the client certificates ClientCertMiddleware accepts; nil rejects all of them.
+	ClientCertificates clientcert.ServiceInterface
+}
+
 // AuthService provides methods for user authentication and registration.
 type AuthService struct {
-	userStore   store.UserStore
-	userService user.ServiceInterface // Added UserService
-	jwtSecret   string
-	tokenExpiry time.Duration
+	userStore            store.UserStore
+	refreshTokenStore    store.RefreshTokenStore
+	revokedTokenStore    store.RevokedTokenStore
+	mfaStore             store.MFAStore
+	passwordResetStore   store.PasswordResetTokenStore
+	emailChangeStore     store.EmailChangeStore
+	loginAttemptStore    store.LoginAttemptStore
+	passwordHistoryStore store.PasswordHistoryStore
+	userIdentityStore    store.UserIdentityStore
+	oidcLoginStateStore  store.OIDCLoginStateStore
+	oauthClientStore     store.OAuthClientStore
+	serviceAccountStore  store.ServiceAccountStore
+	sessionStore         store.SessionStore
+	roleStore            store.RoleStore
+	apiKeyService        apikey.ServiceInterface
+	mailer               mailer.Mailer
+	keys                 *KeySet
+	parser               *jwt.Parser
+	issuer               string
+	audience             string
+	tokenExpiry          time.Duration
+	refreshExpiry        time.Duration
+	totpIssuer           string
+
+	appBaseURL              string
+	emailVerificationExpiry time.Duration
+	passwordResetExpiry     time.Duration
+	requireVerifiedEmail    bool
+	concealExistingAccounts bool
+	throttle                ThrottleConfig
+
+	passwords         PasswordHasher
+	dummyPasswordHash func() string
+	passwordPolicy    passwordpolicy.Policy
+	passwordHistory   int
+
+	cookies CookieConfig
+
+	oidcProviders map[string]*oidc.Provider
+
+	clientTokenExpiry time.Duration
+
+	impersonationExpiry time.Duration
+	recorder            *audit.Recorder
+
+	sessionIdleTimeout     time.Duration
+	sessionAbsoluteTimeout time.Duration
+
+	clientCertificates clientcert.ServiceInterface
 }
 
 // NewAuthService creates a new AuthService.
-func NewAuthService(userStore store.UserStore, userService user.ServiceInterface, jwtSecret string, tokenExpiry time.Duration) *AuthService {
+func NewAuthService(store store.Store, apiKeyService apikey.ServiceInterface, mailer mailer.Mailer, cfg Config) *AuthService {
+	passwords := cfg.PasswordHasher
+	if passwords == nil {
+		passwords = NewArgon2idHasher(DefaultArgon2Params)
+	}
+	cookies := cfg.Cookies
+	if cookies.RefreshPath == "" {
+		cookies.RefreshPath = "/"
+	}
+	oidcProviders := make(map[string]*oidc.Provider, len(cfg.OIDCProviders))
+	for _, provider := range cfg.OIDCProviders {
+		oidcProviders[provider.Name()] = provider
+	}
+
 	return &AuthService{
-		userStore:   userStore,
-		userService: userService, // Added UserService
-		jwtSecret:   jwtSecret,
-		tokenExpiry: tokenExpiry,
+		userStore:            store,
+		refreshTokenStore:    store,
+		revokedTokenStore:    store,
+		mfaStore:             store,
+		passwordResetStore:   store,
+		emailChangeStore:     store,
+		loginAttemptStore:    store,
+		passwordHistoryStore: store,
+		userIdentityStore:    store,
+		oidcLoginStateStore:  store,
+		oauthClientStore:     store,
+		serviceAccountStore:  store,
+		sessionStore:         store,
+		roleStore:            store,
+		apiKeyService:        apiKeyService,
+		mailer:               mailer,
+		keys:                 cfg.SigningKeys,
+		parser:               newParser(cfg.Issuer, cfg.Audience, cfg.Leeway),
+		issuer:               cfg.Issuer,
+		audience:             cfg.Audience,
+		tokenExpiry:          cfg.AccessTokenExpiry,
+		refreshExpiry:        cfg.RefreshTokenExpiry,
+		totpIssuer:           cfg.TOTPIssuer,
+
+		appBaseURL:              strings.TrimSuffix(cfg.AppBaseURL, "/"),
+		emailVerificationExpiry: cfg.EmailVerificationExpiry,
+		passwordResetExpiry:     cfg.PasswordResetExpiry,
+		requireVerifiedEmail:    cfg.RequireVerifiedEmail,
+		concealExistingAccounts: cfg.ConcealExistingAccounts,
+		throttle:                cfg.Throttle,
+
+		passwords: passwords,
+		dummyPasswordHash: sync.OnceValue(func() string {
+			hash, _ := passwords.Hash(dummyPassword)
+			return hash
+		}),
+		passwordPolicy:  cfg.PasswordPolicy,
+		passwordHistory: cfg.PasswordHistory,
+
+		cookies: cookies,
+
+		oidcProviders: oidcProviders,
+
+		clientTokenExpiry: cfg.ClientTokenExpiry,
+
+		impersonationExpiry: cfg.ImpersonationTokenExpiry,
+		recorder:            cfg.AuditRecorder,
+
+		sessionIdleTimeout:     cfg.SessionIdleTimeout,
+		sessionAbsoluteTimeout: cfg.SessionAbsoluteTimeout,
+
+		clientCertificates: cfg.ClientCertificates,
 	}
 }
 
 // Register creates a new user after validating input and hashing the password.
+// The new account's email address is unverified; see SendVerificationEmail.
+// It returns a *passwordpolicy.Error if the password is not acceptable and ErrUserAlreadyExists
+// if the username or email is taken. When existing accounts are concealed (see ConcealsExistingAccounts),
+// a taken email is reported as ErrUserAlreadyExists and its owner is notified by email in the background,
+// so that neither the response time nor a mail failure reveals the account; a taken username is
+// reported as ErrUsernameTaken.
 func (s *AuthService) Register(ctx context.Context, req *dto.CreateUserRequest) (*db.User, error) {
-	hashedPassword, err := HashPassword(req.Password)
+	account := passwordpolicy.Account{Username: req.Username, Email: req.Email}
+	if err := s.checkNewPassword(ctx, req.Password, account, nil); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := s.passwords.Hash(req.Password)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password during registration: %w", err)
 	}
 
-	// Generate a new API key
-	apiKey, err := uuid.NewRandom()
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	if s.concealExistingAccounts {
+		existing, err := s.userStore.GetUserByEmail(ctx, req.Email)
+		if err == nil {
+			s.inBackground(ctx, "account exists email", func(ctx context.Context) error {
+				return s.sendAccountExistsEmail(ctx, &existing)
+			})
+			return nil, ErrUserAlreadyExists
+		}
+		if !errors.Is(err, store.ErrNotFound) {
+			return nil, fmt.Errorf("failed to get user by email: %w", err)
+		}
 	}
 
 	params := db.CreateUserParams{
 		Username:     req.Username,
 		Email:        req.Email,
 		PasswordHash: hashedPassword,
-		ApiKey:       apiKey.String(), // Add the generated API key here
 	}
 
 	user, err := s.userStore.CreateUser(ctx, params)
 	if err != nil {
-		// TODO: Check for specific database errors, e.g., unique constraint violation from pgx,
-		// and wrap them into ErrUserAlreadyExists if applicable.
+		if errors.Is(err, store.ErrConflict) {
+			if s.concealExistingAccounts {
+				// The email was free a moment ago, so it is almost certainly the username.
+				return nil, ErrUsernameTaken
+			}
+			return nil, ErrUserAlreadyExists
+		}
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
 	return &user, nil
 }
 
-// Login authenticates a user by email and password, returning a JWT if successful.
-func (s *AuthService) Login(ctx context.Context, email, password string) (string, *db.User, error) {
+// ConcealsExistingAccounts reports whether registration must answer the same way
+// whether or not the email address is already registered.
+func (s *AuthService) ConcealsExistingAccounts() bool {
+	return s.concealExistingAccounts
+}
+
+// sendAccountExistsEmail tells the owner of an account that someone tried to register with their address.
+func (s *AuthService) sendAccountExistsEmail(ctx context.Context, user *db.User) error {
+	err := s.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "You already have an account",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone tried to create an account with this email address, but you already have one. If it was you, log in at %s/login or, if you forgot your password, reset it at %s/forgot-password.\n\nIf it was not you, you can ignore this email.\n",
+			user.Username, s.appBaseURL, s.appBaseURL),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send account exists email: %w", err)
+	}
+	return nil
+}
+
+// Login authenticates a user by email and password.
+// On success it returns an access token together with a new refresh token family.
+// Users with TOTP enabled get an *MFAChallengeError instead, which matches ErrMFARequired
+// and carries the token to complete the login with at VerifyMFA.
+// Failed attempts are counted per email address and per clientIP; while either is throttled
+// or locked, Login returns a *ThrottledError without checking the password.
+func (s *AuthService) Login(ctx context.Context, email, password string, client ClientInfo) (*TokenPair, *db.User, error) {
+	throttleKeys := loginThrottleKeys(email, client.IP)
+	if err := s.checkThrottle(ctx, throttleKeys...); err != nil {
+		return nil, nil, err
+	}
+
 	user, err := s.userStore.GetUserByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
-			return "", nil, ErrInvalidCredentials
+			// Spend as long as on a wrong password, and count the failure too,
+			// so that neither timing nor lockouts reveal which addresses are registered.
+			s.checkDummyPassword(password)
+			if err := s.recordFailure(ctx, throttleKeys...); err != nil {
+				return nil, nil, err
+			}
+			return nil, nil, ErrInvalidCredentials
+		}
+		return nil, nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	var match, rehash bool
+	if user.PasswordHash == unusablePasswordHash {
+		// Users who signed up through an OpenID Connect provider have no password; spend as long as on a wrong one.
+		s.checkDummyPassword(password)
+	} else {
+		match, rehash = s.passwords.Verify(password, user.PasswordHash)
+	}
+	if !match {
+		if err := s.recordFailure(ctx, throttleKeys...); err != nil {
+			return nil, nil, err
 		}
-		return "", nil, fmt.Errorf("failed to get user by email: %w", err)
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	// Upgrade hashes written with an older algorithm or cost while the password is at hand.
+	if rehash {
+		if err := s.rehashPassword(ctx, &user, password); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Only the account's count is reset; an IP may be guessing passwords for many accounts.
+	if err := s.clearFailures(ctx, throttleKeys[0]); err != nil {
+		return nil, nil, err
+	}
+
+	// Checked after the password so the response does not reveal whether an address is registered.
+	if user.SuspendedAt.Valid {
+		return nil, nil, ErrAccountSuspended
+	}
+	if user.PasswordResetRequiredAt.Valid {
+		return nil, nil, ErrPasswordResetRequired
+	}
+	if s.requireVerifiedEmail && !user.EmailVerifiedAt.Valid {
+		return nil, nil, ErrEmailNotVerified
+	}
+
+	if user.TotpEnabledAt.Valid {
+		return nil, nil, s.issueMFAChallenge(&user, []string{AuthMethodPassword})
+	}
+
+	tokens, err := s.startSession(ctx, &user, client, []string{AuthMethodPassword})
+	if err != nil {
+		return nil, nil, err
 	}
 
-	if !CheckPasswordHash(password, user.PasswordHash) {
-		return "", nil, ErrInvalidCredentials
+	return tokens, &user, nil
+}
+
+// rehashPassword replaces the user's stored password hash with a fresh one of the same password.
+func (s *AuthService) rehashPassword(ctx context.Context, user *db.User, password string) error {
+	hashedPassword, err := s.passwords.Hash(password)
+	if err != nil {
+		return fmt.Errorf("failed to rehash password: %w", err)
 	}
+	err = s.userStore.RehashUserPassword(ctx, db.RehashUserPasswordParams{
+		ID:      user.ID,
+		OldHash: user.PasswordHash,
+		NewHash: hashedPassword,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store rehashed password: %w", err)
+	}
+	user.PasswordHash = hashedPassword
+	return nil
+}
 
-	// Create JWT claims
-	claims := jwt.RegisteredClaims{
-		Subject:   user.ID.String(), // Use user's UUID as subject
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.tokenExpiry)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		Issuer:    "go-api-structure", // Optional: identify the issuer
+// JWKS returns the public keys tokens issued by this service can be verified with.
+func (s *AuthService) JWKS() JWKSet {
+	return s.keys.JWKS()
+}
+
+// issueAccessToken signs a short-lived JWT for the given user, belonging to the given session.
+// authMethods records how the user authenticated and ends up in the amr claim.
+func (s *AuthService) issueAccessToken(ctx context.Context, user *db.User, sessionID uuid.UUID, authMethods []string) (string, error) {
+	claims := s.newClaims(user.ID.String(), TokenTypeAccess, s.tokenExpiry) // Use user's UUID as subject
+	claims.AuthMethods = authMethods
+	claims.SessionID = sessionID.String()
+
+	roles, err := s.userRoleNames(ctx, user.ID)
+	if err != nil {
+		return "", err
 	}
+	claims.Roles = roles
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(s.jwtSecret))
+	signedToken, err := s.keys.Sign(claims)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to sign token: %w", err)
+		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
 
-	return signedToken, &user, nil
+	return signedToken, nil
+}
+
+// userRoleNames returns the names of the user's roles, for the roles claim of their access tokens.
+func (s *AuthService) userRoleNames(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	roles, err := s.roleStore.ListUserRoles(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user roles: %w", err)
+	}
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names, nil
 }
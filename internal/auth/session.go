@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrSessionExpired is returned for sessions that reached their idle or absolute timeout.
+	ErrSessionExpired = errors.New("session has expired")
+)
+
+// maxUserAgentLength bounds the User-Agent header stored with a session.
+const maxUserAgentLength = 512
+
+// ClientInfo describes the device a login comes from. It is stored with the session the login starts.
+type ClientInfo struct {
+	IP        string // As set by the RealIP middleware
+	UserAgent string
+}
+
+// ListSessions returns the sessions the user is logged in with, most recently used first.
+// Sessions that have timed out are left out.
+func (s *AuthService) ListSessions(ctx context.Context, userID uuid.UUID) ([]db.Session, error) {
+	sessions, err := s.sessionStore.ListUserSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	active := sessions[:0]
+	for _, session := range sessions {
+		if !s.sessionExpired(&session) {
+			active = append(active, session)
+		}
+	}
+	return active, nil
+}
+
+// DeleteSession logs the user out of one of their sessions. Its refresh tokens are revoked and
+// JWTMiddleware rejects its access tokens from then on. It returns ErrSessionNotFound if the
+// session does not exist or belongs to someone else.
+func (s *AuthService) DeleteSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	deleted, err := s.sessionStore.DeleteUserSession(ctx, db.DeleteUserSessionParams{ID: sessionID, UserID: userID})
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	if deleted == 0 {
+		return ErrSessionNotFound
+	}
+	if err := s.refreshTokenStore.RevokeRefreshTokenFamily(ctx, sessionID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
+// startSession records a new session for a user who just logged in and issues its first token pair.
+func (s *AuthService) startSession(ctx context.Context, user *db.User, client ClientInfo, authMethods []string) (*TokenPair, error) {
+	userAgent := client.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	session, err := s.sessionStore.CreateSession(ctx, db.CreateSessionParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		UserAgent: userAgent,
+		IpAddress: client.IP,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(s.sessionAbsoluteTimeout), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return s.issueTokenPair(ctx, user, &session, authMethods)
+}
+
+// activeSession returns a session that has not timed out and records that it was used.
+// Timed out sessions are deleted and reported as ErrSessionExpired.
+func (s *AuthService) activeSession(ctx context.Context, id uuid.UUID) (*db.Session, error) {
+	session, err := s.liveSession(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrSessionExpired) {
+			if err := s.endSession(ctx, id); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	if err := s.sessionStore.TouchSession(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to record session usage: %w", err)
+	}
+	return session, nil
+}
+
+// liveSession returns a session that has not timed out, like activeSession, but leaves it untouched:
+// its use is not recorded and a timed out session is only reported as ErrSessionExpired.
+func (s *AuthService) liveSession(ctx context.Context, id uuid.UUID) (*db.Session, error) {
+	session, err := s.sessionStore.GetSession(ctx, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if s.sessionExpired(&session) {
+		return nil, ErrSessionExpired
+	}
+	return &session, nil
+}
+
+// sessionExpired reports whether a session reached its absolute timeout, or its idle timeout if one is set.
+// last_seen_at is only written once a minute, so the idle timeout is that much less precise.
+func (s *AuthService) sessionExpired(session *db.Session) bool {
+	now := time.Now()
+	if now.After(session.ExpiresAt.Time) {
+		return true
+	}
+	return s.sessionIdleTimeout > 0 && now.After(session.LastSeenAt.Time.Add(s.sessionIdleTimeout))
+}
+
+// endSession deletes a session and revokes its refresh tokens.
+func (s *AuthService) endSession(ctx context.Context, id uuid.UUID) error {
+	if err := s.sessionStore.DeleteSession(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	if err := s.refreshTokenStore.RevokeRefreshTokenFamily(ctx, id); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+	return nil
+}
+
+// errSessionMismatch is returned by tokenSession for tokens whose sid names a session of another user.
+var errSessionMismatch = errors.New("session belongs to another user")
+
+// tokenSession makes sure the session an access token of userID belongs to still exists and has not
+// timed out, looking it up with activeSession or liveSession. Tokens without a sid claim, such as
+// impersonation tokens, do not belong to a session. It returns ErrSessionNotFound or ErrSessionExpired
+// if the session has ended and errSessionMismatch if the sid claim does not name a session of userID.
+func (s *AuthService) tokenSession(ctx context.Context, claims *Claims, userID uuid.UUID, lookup func(context.Context, uuid.UUID) (*db.Session, error)) error {
+	if claims.SessionID == "" {
+		return nil
+	}
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return errSessionMismatch
+	}
+
+	session, err := lookup(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return errSessionMismatch
+	}
+	return nil
+}
+
+// checkSession applies tokenSession to a request, which counts as a use of the session.
+// It renders an error and returns false if the token must be rejected.
+func (s *AuthService) checkSession(w http.ResponseWriter, r *http.Request, errorRenderer func(w http.ResponseWriter, r *http.Request, status int, message any), claims *Claims, userID uuid.UUID) bool {
+	err := s.tokenSession(r.Context(), claims, userID, s.activeSession)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, errSessionMismatch):
+		errorRenderer(w, r, http.StatusUnauthorized, "invalid token claims")
+	case errors.Is(err, ErrSessionNotFound):
+		errorRenderer(w, r, http.StatusUnauthorized, "session revoked")
+	case errors.Is(err, ErrSessionExpired):
+		errorRenderer(w, r, http.StatusUnauthorized, "session expired")
+	default:
+		errorRenderer(w, r, http.StatusInternalServerError, "error checking session")
+	}
+	return false
+}
@@ -0,0 +1,393 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+// fakeStore is an in-memory store.Store with the behaviour of the SQL queries the service tests
+// go through. Calling any other method panics on the nil embedded Store.
+type fakeStore struct {
+	store.Store
+
+	mu            sync.Mutex
+	users         map[uuid.UUID]db.User
+	loginAttempts map[throttleKey]db.LoginAttempt
+	sessions      map[uuid.UUID]db.Session
+	refreshTokens map[uuid.UUID]db.RefreshToken
+	revokedTokens map[uuid.UUID]bool
+	oidcLogins    map[string]db.OidcLoginState
+	identities    []db.UserIdentity
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		users:         make(map[uuid.UUID]db.User),
+		loginAttempts: make(map[throttleKey]db.LoginAttempt),
+		sessions:      make(map[uuid.UUID]db.Session),
+		refreshTokens: make(map[uuid.UUID]db.RefreshToken),
+		revokedTokens: make(map[uuid.UUID]bool),
+		oidcLogins:    make(map[string]db.OidcLoginState),
+	}
+}
+
+func timestampNow() pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: time.Now(), Valid: true}
+}
+
+// addUser stores a user with a verified email address and the given password hash.
+func (f *fakeStore) addUser(email, passwordHash string) db.User {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user := db.User{
+		ID:              uuid.New(),
+		Username:        strings.Split(email, "@")[0],
+		Email:           email,
+		PasswordHash:    passwordHash,
+		CreatedAt:       timestampNow(),
+		EmailVerifiedAt: timestampNow(),
+	}
+	f.users[user.ID] = user
+	return user
+}
+
+func (f *fakeStore) user(id uuid.UUID) db.User {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.users[id]
+}
+
+func (f *fakeStore) updateUser(id uuid.UUID, change func(*db.User)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user := f.users[id]
+	change(&user)
+	f.users[id] = user
+}
+
+func (f *fakeStore) loginAttempt(scope, identifier string) (db.LoginAttempt, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	attempt, ok := f.loginAttempts[throttleKey{scope: scope, identifier: identifier}]
+	return attempt, ok
+}
+
+func (f *fakeStore) GetUserByID(ctx context.Context, id uuid.UUID) (db.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user, ok := f.users[id]
+	if !ok {
+		return db.User{}, store.ErrNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeStore) GetUserByEmail(ctx context.Context, email string) (db.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, user := range f.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+	return db.User{}, store.ErrNotFound
+}
+
+func (f *fakeStore) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, user := range f.users {
+		if strings.EqualFold(user.Email, arg.Email) || user.Username == arg.Username {
+			return db.User{}, store.ErrConflict
+		}
+	}
+	user := db.User{
+		ID:           uuid.New(),
+		Username:     arg.Username,
+		Email:        arg.Email,
+		PasswordHash: arg.PasswordHash,
+		CreatedAt:    timestampNow(),
+	}
+	f.users[user.ID] = user
+	return user, nil
+}
+
+func (f *fakeStore) MarkUserEmailVerified(ctx context.Context, arg db.MarkUserEmailVerifiedParams) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user, ok := f.users[arg.ID]
+	if !ok || user.Email != arg.Email {
+		return 0, nil
+	}
+	user.EmailVerifiedAt = timestampNow()
+	f.users[arg.ID] = user
+	return 1, nil
+}
+
+func (f *fakeStore) RehashUserPassword(ctx context.Context, arg db.RehashUserPasswordParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if user, ok := f.users[arg.ID]; ok && user.PasswordHash == arg.OldHash {
+		user.PasswordHash = arg.NewHash
+		f.users[arg.ID] = user
+	}
+	return nil
+}
+
+func (f *fakeStore) ListUserRoles(ctx context.Context, userID uuid.UUID) ([]db.Role, error) {
+	return nil, nil
+}
+
+func (f *fakeStore) GetLoginAttempt(ctx context.Context, arg db.GetLoginAttemptParams) (db.LoginAttempt, error) {
+	attempt, ok := f.loginAttempt(arg.Scope, arg.Identifier)
+	if !ok {
+		return db.LoginAttempt{}, store.ErrNotFound
+	}
+	return attempt, nil
+}
+
+func (f *fakeStore) RecordLoginFailure(ctx context.Context, arg db.RecordLoginFailureParams) (db.LoginAttempt, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := throttleKey{scope: arg.Scope, identifier: arg.Identifier}
+	attempt, ok := f.loginAttempts[key]
+	if !ok || attempt.LastFailedAt.Time.Before(arg.ResetBefore.Time) {
+		attempt = db.LoginAttempt{Scope: arg.Scope, Identifier: arg.Identifier, LockedUntil: attempt.LockedUntil}
+	}
+	attempt.Failures++
+	attempt.LastFailedAt = timestampNow()
+	f.loginAttempts[key] = attempt
+	return attempt, nil
+}
+
+func (f *fakeStore) LockLoginAttempt(ctx context.Context, arg db.LockLoginAttemptParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := throttleKey{scope: arg.Scope, identifier: arg.Identifier}
+	attempt := f.loginAttempts[key]
+	attempt.LockedUntil = arg.LockedUntil
+	f.loginAttempts[key] = attempt
+	return nil
+}
+
+func (f *fakeStore) DeleteLoginAttempt(ctx context.Context, arg db.DeleteLoginAttemptParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.loginAttempts, throttleKey{scope: arg.Scope, identifier: arg.Identifier})
+	return nil
+}
+
+func (f *fakeStore) CreateSession(ctx context.Context, arg db.CreateSessionParams) (db.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	session := db.Session{
+		ID:         arg.ID,
+		UserID:     arg.UserID,
+		UserAgent:  arg.UserAgent,
+		IpAddress:  arg.IpAddress,
+		CreatedAt:  timestampNow(),
+		LastSeenAt: timestampNow(),
+		ExpiresAt:  arg.ExpiresAt,
+	}
+	f.sessions[session.ID] = session
+	return session, nil
+}
+
+func (f *fakeStore) GetSession(ctx context.Context, id uuid.UUID) (db.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	session, ok := f.sessions[id]
+	if !ok {
+		return db.Session{}, store.ErrNotFound
+	}
+	return session, nil
+}
+
+func (f *fakeStore) TouchSession(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if session, ok := f.sessions[id]; ok {
+		session.LastSeenAt = timestampNow()
+		f.sessions[id] = session
+	}
+	return nil
+}
+
+func (f *fakeStore) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.sessions, id)
+	return nil
+}
+
+func (f *fakeStore) CreateRefreshToken(ctx context.Context, arg db.CreateRefreshTokenParams) (db.RefreshToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	token := db.RefreshToken{
+		ID:          uuid.New(),
+		UserID:      arg.UserID,
+		FamilyID:    arg.FamilyID,
+		TokenHash:   arg.TokenHash,
+		ExpiresAt:   arg.ExpiresAt,
+		CreatedAt:   timestampNow(),
+		AuthMethods: arg.AuthMethods,
+	}
+	f.refreshTokens[token.ID] = token
+	return token, nil
+}
+
+func (f *fakeStore) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (db.RefreshToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, token := range f.refreshTokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return db.RefreshToken{}, store.ErrNotFound
+}
+
+func (f *fakeStore) MarkRefreshTokenUsed(ctx context.Context, id uuid.UUID) (db.RefreshToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	token, ok := f.refreshTokens[id]
+	if !ok || token.UsedAt.Valid || token.RevokedAt.Valid {
+		return db.RefreshToken{}, store.ErrNotFound
+	}
+	token.UsedAt = timestampNow()
+	f.refreshTokens[id] = token
+	return token, nil
+}
+
+func (f *fakeStore) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for id, token := range f.refreshTokens {
+		if token.FamilyID == familyID && !token.RevokedAt.Valid {
+			token.RevokedAt = timestampNow()
+			f.refreshTokens[id] = token
+		}
+	}
+	return nil
+}
+
+func (f *fakeStore) IsTokenRevoked(ctx context.Context, jti uuid.UUID) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.revokedTokens[jti], nil
+}
+
+func (f *fakeStore) CreateOIDCLoginState(ctx context.Context, arg db.CreateOIDCLoginStateParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.oidcLogins[arg.StateHash] = db.OidcLoginState{
+		ID:           uuid.New(),
+		Provider:     arg.Provider,
+		StateHash:    arg.StateHash,
+		Nonce:        arg.Nonce,
+		CodeVerifier: arg.CodeVerifier,
+		ExpiresAt:    arg.ExpiresAt,
+		CreatedAt:    timestampNow(),
+	}
+	return nil
+}
+
+func (f *fakeStore) ConsumeOIDCLoginState(ctx context.Context, stateHash string) (db.OidcLoginState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	login, ok := f.oidcLogins[stateHash]
+	if !ok {
+		return db.OidcLoginState{}, store.ErrNotFound
+	}
+	delete(f.oidcLogins, stateHash)
+	return login, nil
+}
+
+func (f *fakeStore) GetUserIdentity(ctx context.Context, arg db.GetUserIdentityParams) (db.UserIdentity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, identity := range f.identities {
+		if identity.Provider == arg.Provider && identity.Subject == arg.Subject {
+			return identity, nil
+		}
+	}
+	return db.UserIdentity{}, store.ErrNotFound
+}
+
+func (f *fakeStore) TouchUserIdentity(ctx context.Context, arg db.TouchUserIdentityParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, identity := range f.identities {
+		if identity.ID == arg.ID {
+			f.identities[i].Email = arg.Email
+			f.identities[i].LastLoginAt = timestampNow()
+		}
+	}
+	return nil
+}
+
+func (f *fakeStore) CreateUserIdentity(ctx context.Context, arg db.CreateUserIdentityParams) (db.UserIdentity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	identity := db.UserIdentity{
+		ID:          uuid.New(),
+		UserID:      arg.UserID,
+		Provider:    arg.Provider,
+		Subject:     arg.Subject,
+		Email:       arg.Email,
+		CreatedAt:   timestampNow(),
+		LastLoginAt: timestampNow(),
+	}
+	f.identities = append(f.identities, identity)
+	return identity, nil
+}
+
+// newTestService creates an AuthService on st with settings suitable for tests.
+// change, if not nil, adjusts the configuration first.
+func newTestService(t *testing.T, st store.Store, change func(*Config)) *AuthService {
+	t.Helper()
+	keys, err := LoadKeySet(nil, "", "test secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{
+		SigningKeys:            keys,
+		AccessTokenExpiry:      15 * time.Minute,
+		RefreshTokenExpiry:     24 * time.Hour,
+		Issuer:                 "test-issuer",
+		Audience:               "test-audience",
+		PasswordHasher:         NewArgon2idHasher(testArgon2Params),
+		SessionAbsoluteTimeout: 24 * time.Hour,
+		Throttle: ThrottleConfig{
+			Threshold:        3,
+			IPThreshold:      10,
+			BaseDelay:        time.Minute,
+			MaxDelay:         time.Hour,
+			LockoutThreshold: 5,
+			LockoutDuration:  15 * time.Minute,
+		},
+	}
+	if change != nil {
+		change(&cfg)
+	}
+	return NewAuthService(st, nil, nil, cfg)
+}
+
+// hashPassword returns a hash of password that newTestService accepts.
+func hashPassword(t *testing.T, password string) string {
+	t.Helper()
+	hash, err := NewArgon2idHasher(testArgon2Params).Hash(password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+var ErrTooManyAttempts = errors.New("too many failed attempts")
+
+// ThrottleConfig controls how failed logins and second factor attempts slow down further attempts.
+// Failures are counted per account and per client IP. Once an identifier reaches its threshold,
+// every further attempt has to wait BaseDelay, doubling with each failure up to MaxDelay.
+type ThrottleConfig struct {
+	Threshold   int // Failures per account before attempts are delayed
+	IPThreshold int // Failures per client IP before attempts are delayed
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// LockoutThreshold is the number of failures that lock an account for LockoutDuration; zero disables lockout.
+	LockoutThreshold int
+	// LockoutDuration is also how long failures are remembered: the count starts over
+	// once the last failure is older than that.
+	LockoutDuration time.Duration
+}
+
+// ThrottledError is returned in place of checking credentials while an account or client IP is
+// throttled or locked. RetryAfter is how long the caller has to wait before trying again.
+// It matches ErrTooManyAttempts with errors.Is.
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string { return ErrTooManyAttempts.Error() }
+
+func (e *ThrottledError) Is(target error) bool { return target == ErrTooManyAttempts }
+
+// throttleKey identifies a login attempt record.
+type throttleKey struct {
+	scope      string
+	identifier string
+}
+
+// loginThrottleKeys returns the records a password login for email from clientIP is counted against.
+func loginThrottleKeys(email, clientIP string) []throttleKey {
+	return []throttleKey{
+		{scope: store.LoginScopeAccount, identifier: strings.ToLower(email)},
+		{scope: store.LoginScopeIP, identifier: clientIP},
+	}
+}
+
+// checkThrottle returns a *ThrottledError if any of keys is locked or still has to wait
+// after its last failure. Keys with an empty identifier are ignored.
+func (s *AuthService) checkThrottle(ctx context.Context, keys ...throttleKey) error {
+	now := time.Now()
+	var wait time.Duration
+
+	for _, key := range keys {
+		if key.identifier == "" {
+			continue
+		}
+		attempt, err := s.loginAttemptStore.GetLoginAttempt(ctx, db.GetLoginAttemptParams{
+			Scope:      key.scope,
+			Identifier: key.identifier,
+		})
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				continue
+			}
+			return fmt.Errorf("failed to get login attempts: %w", err)
+		}
+		if d := s.throttle.retryAfter(&attempt, now); d > wait {
+			wait = d
+		}
+	}
+
+	if wait > 0 {
+		return &ThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+// recordFailure counts a failed attempt against each of keys, locking account
+// and second factor records that reach the lockout threshold.
+func (s *AuthService) recordFailure(ctx context.Context, keys ...throttleKey) error {
+	now := time.Now()
+
+	for _, key := range keys {
+		if key.identifier == "" {
+			continue
+		}
+		attempt, err := s.loginAttemptStore.RecordLoginFailure(ctx, db.RecordLoginFailureParams{
+			Scope:       key.scope,
+			Identifier:  key.identifier,
+			ResetBefore: pgtype.Timestamptz{Time: now.Add(-s.throttle.LockoutDuration), Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to record login failure: %w", err)
+		}
+
+		// Client IPs are only delayed; locking one would lock out everyone behind a shared address.
+		if key.scope == store.LoginScopeIP || s.throttle.LockoutThreshold <= 0 || int(attempt.Failures) < s.throttle.LockoutThreshold {
+			continue
+		}
+		err = s.loginAttemptStore.LockLoginAttempt(ctx, db.LockLoginAttemptParams{
+			Scope:       key.scope,
+			Identifier:  key.identifier,
+			LockedUntil: pgtype.Timestamptz{Time: now.Add(s.throttle.LockoutDuration), Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to lock login attempts: %w", err)
+		}
+	}
+	return nil
+}
+
+// clearFailures forgets the failures counted against key after a successful attempt.
+func (s *AuthService) clearFailures(ctx context.Context, key throttleKey) error {
+	err := s.loginAttemptStore.DeleteLoginAttempt(ctx, db.DeleteLoginAttemptParams{
+		Scope:      key.scope,
+		Identifier: key.identifier,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear login attempts: %w", err)
+	}
+	return nil
+}
+
+// retryAfter returns how long an attempt counted against the record has to wait, or zero if it may proceed.
+func (c ThrottleConfig) retryAfter(attempt *db.LoginAttempt, now time.Time) time.Duration {
+	var until time.Time
+	if attempt.LockedUntil.Valid {
+		until = attempt.LockedUntil.Time
+	}
+
+	threshold := c.Threshold
+	if attempt.Scope == store.LoginScopeIP {
+		threshold = c.IPThreshold
+	}
+	forgotten := now.Sub(attempt.LastFailedAt.Time) > c.LockoutDuration
+	if threshold > 0 && !forgotten && int(attempt.Failures) >= threshold {
+		next := attempt.LastFailedAt.Time.Add(c.delay(int(attempt.Failures) - threshold))
+		if next.After(until) {
+			until = next
+		}
+	}
+
+	if wait := until.Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// delay returns BaseDelay doubled n times, capped at MaxDelay.
+func (c ThrottleConfig) delay(n int) time.Duration {
+	d := c.BaseDelay
+	for range n {
+		if d >= c.MaxDelay {
+			break
+		}
+		d *= 2
+	}
+	return min(d, c.MaxDelay)
+}
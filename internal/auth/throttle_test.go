@@ -0,0 +1,241 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+func TestThrottleDelay(t *testing.T) {
+	c := ThrottleConfig{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	tests := []struct {
+		n    int
+		want time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{4, 10 * time.Second},
+		{1000, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := c.delay(tt.n); got != tt.want {
+			t.Errorf("delay(%d) = %v, want %v", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	c := ThrottleConfig{
+		Threshold:       3,
+		IPThreshold:     10,
+		BaseDelay:       time.Minute,
+		MaxDelay:        time.Hour,
+		LockoutDuration: 15 * time.Minute,
+	}
+	now := time.Now()
+	at := func(d time.Duration) pgtype.Timestamptz {
+		return pgtype.Timestamptz{Time: now.Add(d), Valid: true}
+	}
+
+	tests := []struct {
+		name    string
+		attempt db.LoginAttempt
+		want    time.Duration
+	}{
+		{"below threshold", db.LoginAttempt{Scope: store.LoginScopeAccount, Failures: 2, LastFailedAt: at(0)}, 0},
+		{"at threshold", db.LoginAttempt{Scope: store.LoginScopeAccount, Failures: 3, LastFailedAt: at(0)}, time.Minute},
+		{"doubles per failure", db.LoginAttempt{Scope: store.LoginScopeAccount, Failures: 5, LastFailedAt: at(0)}, 4 * time.Minute},
+		{"delay partly elapsed", db.LoginAttempt{Scope: store.LoginScopeAccount, Failures: 3, LastFailedAt: at(-20 * time.Second)}, 40 * time.Second},
+		{"delay elapsed", db.LoginAttempt{Scope: store.LoginScopeAccount, Failures: 3, LastFailedAt: at(-2 * time.Minute)}, 0},
+		{"failures forgotten", db.LoginAttempt{Scope: store.LoginScopeAccount, Failures: 20, LastFailedAt: at(-16 * time.Minute)}, 0},
+		{"IP below its threshold", db.LoginAttempt{Scope: store.LoginScopeIP, Failures: 9, LastFailedAt: at(0)}, 0},
+		{"IP at its threshold", db.LoginAttempt{Scope: store.LoginScopeIP, Failures: 10, LastFailedAt: at(0)}, time.Minute},
+		{"locked", db.LoginAttempt{Scope: store.LoginScopeAccount, Failures: 1, LastFailedAt: at(-time.Hour), LockedUntil: at(5 * time.Minute)}, 5 * time.Minute},
+		{"lock expired", db.LoginAttempt{Scope: store.LoginScopeAccount, Failures: 1, LastFailedAt: at(-time.Hour), LockedUntil: at(-time.Second)}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.retryAfter(&tt.attempt, now); got != tt.want {
+				t.Errorf("retryAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	disabled := ThrottleConfig{BaseDelay: time.Minute, MaxDelay: time.Hour, LockoutDuration: time.Hour}
+	attempt := db.LoginAttempt{Scope: store.LoginScopeAccount, Failures: 100, LastFailedAt: at(0)}
+	if got := disabled.retryAfter(&attempt, now); got != 0 {
+		t.Errorf("retryAfter() without a threshold = %v, want 0", got)
+	}
+}
+
+func TestLoginThrottle(t *testing.T) {
+	ctx := context.Background()
+	st := newFakeStore()
+	user := st.addUser("jane@example.com", hashPassword(t, "right password"))
+	s := newTestService(t, st, nil)
+	client := ClientInfo{IP: "192.0.2.1"}
+
+	for i := range 3 {
+		if _, _, err := s.Login(ctx, "Jane@example.com", "wrong password", client); !errors.Is(err, ErrInvalidCredentials) {
+			t.Fatalf("attempt %d: Login() error = %v, want ErrInvalidCredentials", i+1, err)
+		}
+	}
+
+	// The right password does not help while the account has to wait.
+	_, _, err := s.Login(ctx, "jane@example.com", "right password", client)
+	var throttled *ThrottledError
+	if !errors.As(err, &throttled) {
+		t.Fatalf("Login() error = %v, want *ThrottledError", err)
+	}
+	if throttled.RetryAfter <= 0 || throttled.RetryAfter > time.Minute {
+		t.Errorf("RetryAfter = %v, want up to a minute", throttled.RetryAfter)
+	}
+	if !errors.Is(err, ErrTooManyAttempts) {
+		t.Error("ThrottledError does not match ErrTooManyAttempts")
+	}
+
+	// Another account from the same address is not affected until the IP reaches its own threshold.
+	other := st.addUser("john@example.com", hashPassword(t, "his password"))
+	if _, got, err := s.Login(ctx, "john@example.com", "his password", client); err != nil || got.ID != other.ID {
+		t.Fatalf("Login() of another account error = %v", err)
+	}
+
+	// Once the delay is over, a successful login clears the account's failures but not the IP's.
+	st.mu.Lock()
+	for key, attempt := range st.loginAttempts {
+		attempt.LastFailedAt.Time = attempt.LastFailedAt.Time.Add(-2 * time.Minute)
+		st.loginAttempts[key] = attempt
+	}
+	st.mu.Unlock()
+	if _, got, err := s.Login(ctx, "jane@example.com", "right password", client); err != nil || got.ID != user.ID {
+		t.Fatalf("Login() after the delay error = %v", err)
+	}
+	if _, ok := st.loginAttempt(store.LoginScopeAccount, "jane@example.com"); ok {
+		t.Error("account failures were not cleared after a successful login")
+	}
+	if attempt, ok := st.loginAttempt(store.LoginScopeIP, client.IP); !ok || attempt.Failures != 3 {
+		t.Errorf("IP failures = %+v, want 3 kept", attempt)
+	}
+}
+
+func TestLoginThrottleUnknownAccount(t *testing.T) {
+	st := newFakeStore()
+	s := newTestService(t, st, nil)
+
+	// Unknown addresses are counted like wrong passwords so lockouts do not reveal which exist.
+	_, _, err := s.Login(context.Background(), "Nobody@Example.com", "password", ClientInfo{IP: "192.0.2.1"})
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Login() error = %v, want ErrInvalidCredentials", err)
+	}
+	if attempt, ok := st.loginAttempt(store.LoginScopeAccount, "nobody@example.com"); !ok || attempt.Failures != 1 {
+		t.Errorf("account failures = %+v, want 1", attempt)
+	}
+}
+
+func TestRecordFailureLockout(t *testing.T) {
+	ctx := context.Background()
+	st := newFakeStore()
+	s := newTestService(t, st, nil)
+	keys := loginThrottleKeys("jane@example.com", "192.0.2.1")
+
+	for range 4 {
+		if err := s.recordFailure(ctx, keys...); err != nil {
+			t.Fatalf("recordFailure() error = %v", err)
+		}
+	}
+	if attempt, _ := st.loginAttempt(store.LoginScopeAccount, "jane@example.com"); attempt.LockedUntil.Valid {
+		t.Fatal("account locked before reaching the lockout threshold")
+	}
+
+	if err := s.recordFailure(ctx, keys...); err != nil {
+		t.Fatalf("recordFailure() error = %v", err)
+	}
+	account, _ := st.loginAttempt(store.LoginScopeAccount, "jane@example.com")
+	if !account.LockedUntil.Valid || time.Until(account.LockedUntil.Time) < 14*time.Minute {
+		t.Errorf("account LockedUntil = %v, want about 15 minutes from now", account.LockedUntil)
+	}
+	if ip, _ := st.loginAttempt(store.LoginScopeIP, "192.0.2.1"); ip.LockedUntil.Valid {
+		t.Error("client IP was locked; IPs must only be delayed")
+	}
+
+	err := s.checkThrottle(ctx, keys...)
+	var throttled *ThrottledError
+	if !errors.As(err, &throttled) || throttled.RetryAfter < 14*time.Minute {
+		t.Errorf("checkThrottle() = %v, want the lockout to apply", err)
+	}
+
+	// Keys without an identifier, e.g. a request without a known client IP, are skipped.
+	if err := s.checkThrottle(ctx, throttleKey{scope: store.LoginScopeIP}); err != nil {
+		t.Errorf("checkThrottle() with an empty identifier = %v", err)
+	}
+}
+
+func TestSecondFactorThrottle(t *testing.T) {
+	tests := []struct {
+		name string
+		// check submits a second factor code for the user.
+		check func(t *testing.T, s *AuthService, user *db.User, code string, client ClientInfo) error
+	}{
+		{"completing a login", func(t *testing.T, s *AuthService, user *db.User, code string, client ClientInfo) error {
+			_, _, err := s.Login(context.Background(), user.Email, "password", client)
+			var challenge *MFAChallengeError
+			if !errors.As(err, &challenge) {
+				t.Fatalf("Login() error = %v, want *MFAChallengeError", err)
+			}
+			_, _, err = s.VerifyMFA(context.Background(), challenge.Token, code, client)
+			return err
+		}},
+		{"disabling TOTP", func(t *testing.T, s *AuthService, user *db.User, code string, client ClientInfo) error {
+			return s.DisableTOTP(context.Background(), user, code, client)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newFakeStore()
+			s := newTestService(t, st, nil)
+			client := ClientInfo{IP: "192.0.2.1"}
+
+			user := st.addUser("jane@example.com", hashPassword(t, "password"))
+			st.updateUser(user.ID, func(u *db.User) {
+				u.TotpSecret = pgtype.Text{String: rfc6238Secret, Valid: true}
+				u.TotpEnabledAt = timestampNow()
+			})
+			user = st.user(user.ID)
+
+			for i := range 3 {
+				if err := tt.check(t, s, &user, "000000", client); !errors.Is(err, ErrInvalidMFACode) {
+					t.Fatalf("attempt %d: error = %v, want ErrInvalidMFACode", i+1, err)
+				}
+			}
+			if attempt, ok := st.loginAttempt(store.LoginScopeMFA, user.ID.String()); !ok || attempt.Failures != 3 {
+				t.Fatalf("second factor failures = %+v, want 3", attempt)
+			}
+
+			// Further codes are not even checked, so the right one does not get through either.
+			code := totpCode([]byte("12345678901234567890"), totpStep(time.Now()))
+			if err := tt.check(t, s, &user, code, client); !errors.Is(err, ErrTooManyAttempts) {
+				t.Fatalf("error = %v, want ErrTooManyAttempts", err)
+			}
+			if got := st.user(user.ID); got.TotpLastUsedStep.Valid || !got.TotpEnabledAt.Valid {
+				t.Error("the code was checked while throttled")
+			}
+		})
+	}
+}
+
+func TestLoginThrottleKeys(t *testing.T) {
+	keys := loginThrottleKeys("Jane@Example.COM", "192.0.2.1")
+	if len(keys) != 2 || keys[0].scope != store.LoginScopeAccount || keys[0].identifier != strings.ToLower("Jane@Example.COM") ||
+		keys[1].scope != store.LoginScopeIP || keys[1].identifier != "192.0.2.1" {
+		t.Errorf("loginThrottleKeys() = %+v", keys)
+	}
+}
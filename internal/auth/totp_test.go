@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 seed of the RFC 6238 appendix B test vectors.
+var rfc6238Secret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode(t *testing.T) {
+	key := []byte("12345678901234567890")
+
+	// RFC 6238 appendix B, truncated to six digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		if got := totpCode(key, totpStep(time.Unix(tt.unix, 0))); got != tt.want {
+			t.Errorf("totpCode at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	key := []byte("12345678901234567890")
+	now := time.Unix(1111111111, 0)
+	current := totpStep(now)
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", rfc6238Secret, totpCode(key, current), current, true},
+		{"previous step", rfc6238Secret, totpCode(key, current-1), current - 1, true},
+		{"next step", rfc6238Secret, totpCode(key, current+1), current + 1, true},
+		{"two steps ago", rfc6238Secret, totpCode(key, current-2), 0, false},
+		{"two steps ahead", rfc6238Secret, totpCode(key, current+2), 0, false},
+		{"wrong code", rfc6238Secret, "000000", 0, false},
+		{"too short", rfc6238Secret, totpCode(key, current)[:5], 0, false},
+		{"too long", rfc6238Secret, totpCode(key, current) + "0", 0, false},
+		{"invalid secret", "not base32!", totpCode(key, current), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := validateTOTP(tt.secret, tt.code, now)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("validateTOTP() = %d, %v, want %d, %v", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+// Package clientcert manages the client certificates that users and service accounts present to
+// the TLS listener instead of a token or API key. The TLS layer checks that a certificate was
+// issued by a trusted CA; this package decides whom it authenticates. Every registration and
+// deletion is recorded in the audit trail.
+package clientcert
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/audit"
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+var (
+	ErrCertificateNotFound = errors.New("client certificate not found")
+	ErrAlreadyRegistered   = errors.New("a client certificate with this fingerprint or subject is already registered")
+	ErrOwnerNotFound       = errors.New("user or service account not found")
+	// ErrUnknownCertificate is returned by Authenticate for certificates matching no registration.
+	ErrUnknownCertificate = errors.New("client certificate is not registered")
+)
+
+// RegisterParams holds the settings for a new client certificate. Exactly one of UserID and
+// ServiceAccountID names the owner, and exactly one of SPKISHA256 and Subject the certificates
+// that authenticate as them.
+type RegisterParams struct {
+	Name             string
+	UserID           *uuid.UUID
+	ServiceAccountID *uuid.UUID
+	// SPKISHA256 is the lowercase hex SHA-256 digest of the certificate's public key, as
+	// returned by Fingerprint. It pins one key pair; renewing with a new key needs a new registration.
+	SPKISHA256 string
+	// Subject is the certificate's distinguished name, as returned by Subject. It matches any
+	// certificate issued for that name by a trusted CA, including renewed ones.
+	Subject string
+	Scopes  []string
+}
+
+// ServiceInterface defines the operations for managing and authenticating client certificates.
+type ServiceInterface interface {
+	Register(ctx context.Context, actor audit.Actor, params RegisterParams) (*db.ClientCertificate, error)
+	List(ctx context.Context) ([]db.ClientCertificate, error)
+	Get(ctx context.Context, id uuid.UUID) (*db.ClientCertificate, error)
+	// Delete deletes a registration; certificates matching it are rejected from then on.
+	Delete(ctx context.Context, actor audit.Actor, id uuid.UUID) error
+	// Authenticate resolves a certificate the TLS layer has already verified to its registration.
+	Authenticate(ctx context.Context, certificate *x509.Certificate) (*db.ClientCertificate, error)
+}
+
+// Service provides client certificate operations.
+type Service struct {
+	certificateStore store.ClientCertificateStore
+	userStore        store.UserStore
+	accountStore     store.ServiceAccountStore
+	recorder         *audit.Recorder
+}
+
+// NewService creates a new client certificate Service.
+func NewService(certificateStore store.ClientCertificateStore, userStore store.UserStore, accountStore store.ServiceAccountStore, recorder *audit.Recorder) *Service {
+	return &Service{
+		certificateStore: certificateStore,
+		userStore:        userStore,
+		accountStore:     accountStore,
+		recorder:         recorder,
+	}
+}
+
+// Register registers the certificates matching params.SPKISHA256 or params.Subject for a user or service account.
+func (s *Service) Register(ctx context.Context, actor audit.Actor, params RegisterParams) (*db.ClientCertificate, error) {
+	arg := db.CreateClientCertificateParams{
+		Name:       params.Name,
+		SpkiSha256: pgtype.Text{String: params.SPKISHA256, Valid: params.SPKISHA256 != ""},
+		Subject:    pgtype.Text{String: params.Subject, Valid: params.Subject != ""},
+		Scopes:     normalizeScopes(params.Scopes),
+		CreatedBy:  pgtype.UUID{Bytes: actor.ID, Valid: actor.IsUser()},
+	}
+
+	var ownerID uuid.UUID
+	var err error
+	switch {
+	case params.UserID != nil:
+		ownerID = *params.UserID
+		arg.UserID = pgtype.UUID{Bytes: ownerID, Valid: true}
+		_, err = s.userStore.GetUserByID(ctx, ownerID)
+	case params.ServiceAccountID != nil:
+		ownerID = *params.ServiceAccountID
+		arg.ServiceAccountID = pgtype.UUID{Bytes: ownerID, Valid: true}
+		_, err = s.accountStore.GetServiceAccount(ctx, ownerID)
+	default:
+		return nil, ErrOwnerNotFound
+	}
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrOwnerNotFound
+		}
+		return nil, fmt.Errorf("failed to get certificate owner: %w", err)
+	}
+
+	certificate, err := s.certificateStore.CreateClientCertificate(ctx, arg)
+	if err != nil {
+		if errors.Is(err, store.ErrConflict) {
+			return nil, ErrAlreadyRegistered
+		}
+		return nil, fmt.Errorf("failed to register client certificate: %w", err)
+	}
+
+	s.recorder.Record(ctx, actor, audit.ActionClientCertificateRegister, ownerID, map[string]any{
+		"client_certificate_id": certificate.ID,
+		"name":                  certificate.Name,
+		"spki_sha256":           params.SPKISHA256,
+		"subject":               params.Subject,
+		"scopes":                certificate.Scopes,
+	})
+	return &certificate, nil
+}
+
+// List returns all registered client certificates, newest first.
+func (s *Service) List(ctx context.Context) ([]db.ClientCertificate, error) {
+	certificates, err := s.certificateStore.ListClientCertificates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list client certificates: %w", err)
+	}
+	return certificates, nil
+}
+
+// Get returns a registered client certificate, or ErrCertificateNotFound.
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*db.ClientCertificate, error) {
+	certificate, err := s.certificateStore.GetClientCertificate(ctx, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrCertificateNotFound
+		}
+		return nil, fmt.Errorf("failed to get client certificate: %w", err)
+	}
+	return &certificate, nil
+}
+
+// Delete deletes a client certificate registration.
+func (s *Service) Delete(ctx context.Context, actor audit.Actor, id uuid.UUID) error {
+	certificate, err := s.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.certificateStore.DeleteClientCertificate(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete client certificate: %w", err)
+	}
+	if deleted == 0 {
+		return ErrCertificateNotFound
+	}
+
+	s.recorder.Record(ctx, actor, audit.ActionClientCertificateDelete, ownerID(certificate), map[string]any{
+		"client_certificate_id": certificate.ID,
+		"name":                  certificate.Name,
+	})
+	return nil
+}
+
+// Authenticate looks up the registration matching the certificate's public key or, failing
+// that, its subject. It does not check the certificate itself; that is up to the TLS layer.
+func (s *Service) Authenticate(ctx context.Context, certificate *x509.Certificate) (*db.ClientCertificate, error) {
+	registered, err := s.certificateStore.GetClientCertificateByIdentity(ctx, db.GetClientCertificateByIdentityParams{
+		SpkiSha256: pgtype.Text{String: Fingerprint(certificate), Valid: true},
+		Subject:    pgtype.Text{String: Subject(certificate), Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrUnknownCertificate
+		}
+		return nil, fmt.Errorf("failed to get client certificate: %w", err)
+	}
+
+	if err := s.certificateStore.TouchClientCertificate(ctx, registered.ID); err != nil {
+		return nil, fmt.Errorf("failed to record client certificate usage: %w", err)
+	}
+	return &registered, nil
+}
+
+// Fingerprint returns the lowercase hex SHA-256 digest of the certificate's DER encoded
+// SubjectPublicKeyInfo. It is the same as
+// openssl x509 -pubkey -noout | openssl pkey -pubin -outform der | openssl dgst -sha256.
+func Fingerprint(certificate *x509.Certificate) string {
+	sum := sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+	return hex.EncodeToString(sum[:])
+}
+
+// Subject returns the certificate's distinguished name in RFC 2253 form, e.g. "CN=billing,O=Acme Corp".
+func Subject(certificate *x509.Certificate) string {
+	return certificate.Subject.String()
+}
+
+// HasScope reports whether the certificate was granted the given scope.
+func HasScope(certificate *db.ClientCertificate, scope string) bool {
+	return slices.Contains(certificate.Scopes, scope)
+}
+
+// ownerID returns the ID of the user or service account a certificate authenticates as.
+func ownerID(certificate *db.ClientCertificate) uuid.UUID {
+	if certificate.ServiceAccountID.Valid {
+		return certificate.ServiceAccountID.Bytes
+	}
+	return certificate.UserID.Bytes
+}
+
+// normalizeScopes sorts scopes and removes duplicates so stored certificates compare predictably.
+func normalizeScopes(scopes []string) []string {
+	normalized := slices.Clone(scopes)
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
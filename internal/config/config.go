@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv" // For loading .env files
@@ -16,9 +17,115 @@ type Config struct {
 	DatabaseDSN       string
 	JWTSecret         string
 	JWTExpiryDuration time.Duration
+	// JWTSigningKeys lists the PEM private keys tokens are signed and verified with.
+	JWTSigningKeys []SigningKeyFile
+	// JWTSigningKeyID selects the key new tokens are signed with; defaults to the first key.
+	JWTSigningKeyID string
+	// JWTIssuer and JWTAudience are written into issued tokens and required on incoming ones.
+	JWTIssuer   string
+	JWTAudience string
+	// JWTLeeway is the clock skew tolerated when validating exp, nbf and iat.
+	JWTLeeway time.Duration
+	// RefreshTokenExpiryDuration is how long a refresh token stays valid after it is issued.
+	RefreshTokenExpiryDuration time.Duration
+	// PurgeInterval is how often expired tokens and revocation entries are deleted.
+	PurgeInterval time.Duration
+	// APIKeyRotationGracePeriod is how long a rotated API key keeps working after its replacement is issued.
+	APIKeyRotationGracePeriod time.Duration
+	// TOTPIssuer is the account issuer authenticator apps display next to the user's email.
+	TOTPIssuer string
+	// AppBaseURL is the front end URL that links in emails point to.
+	AppBaseURL string
+	// MailerDriver selects how emails are sent; "log" only writes them to the log.
+	MailerDriver string
+	MailFrom     string
+	// EmailVerificationExpiry is how long an email verification link stays valid.
+	EmailVerificationExpiry time.Duration
+	// PasswordResetExpiry is how long a password reset link stays valid.
+	PasswordResetExpiry time.Duration
+	// RequireVerifiedEmail makes login refuse accounts that have not verified their email address.
+	RequireVerifiedEmail bool
+	// ConcealExistingAccounts makes registration answer the same way for registered email addresses
+	// and notify their owner instead, so the endpoint cannot be used to find out who has an account.
+	ConcealExistingAccounts bool
+	// LoginThrottleThreshold and LoginThrottleIPThreshold are the failed logins per account and per
+	// client IP after which further attempts are delayed, starting at LoginThrottleBaseDelay and
+	// doubling with every failure up to LoginThrottleMaxDelay.
+	LoginThrottleThreshold   int
+	LoginThrottleIPThreshold int
+	LoginThrottleBaseDelay   time.Duration
+	LoginThrottleMaxDelay    time.Duration
+	// AccountLockoutThreshold is the number of failed logins that lock an account for AccountLockoutDuration; 0 disables lockout.
+	AccountLockoutThreshold int
+	AccountLockoutDuration  time.Duration
+	// PasswordArgon2Memory (KiB), PasswordArgon2Iterations and PasswordArgon2Parallelism are the argon2id
+	// cost parameters of new password hashes. Hashes written with other parameters are upgraded at login.
+	PasswordArgon2Memory      uint32
+	PasswordArgon2Iterations  uint32
+	PasswordArgon2Parallelism uint8
+	// PasswordMinEntropyBits is the minimum estimated strength of new passwords; 0 disables the check.
+	PasswordMinEntropyBits float64
+	// PasswordBreachedHashesFile lists SHA-1 hashes of breached passwords that are rejected; empty disables the check.
+	PasswordBreachedHashesFile string
+	// PasswordRejectAccountSimilar rejects passwords that resemble the username or email address.
+	PasswordRejectAccountSimilar bool
+	// PasswordHistorySize is how many recent passwords, including the current one, cannot be reused; 0 disables the check.
+	PasswordHistorySize int
+	// PermissionCacheTTL is how long a user's permissions are cached; 0 disables caching.
+	PermissionCacheTTL time.Duration
+	// SessionCookies lets browser clients keep their tokens in HttpOnly cookies, protected against CSRF
+	// with a double-submit token.
+	SessionCookies bool
+	// SessionCookieSecure restricts the session cookies to HTTPS; only disable it for local development.
+	SessionCookieSecure bool
+	// SessionCookieSameSite is the SameSite attribute of the session cookies: "strict", "lax" or "none".
+	SessionCookieSameSite string
+	// SessionCookieDomain shares the session cookies with subdomains; empty limits them to the API host.
+	SessionCookieDomain string
+	// CORSAllowedOrigins are the origins browsers may call the API from with credentials (cookies).
+	// When empty, any origin may call it, but without credentials.
+	CORSAllowedOrigins []string
+	// OIDCProviders are the OpenID Connect providers users can sign in with.
+	OIDCProviders []OIDCProvider
+	// OAuthTokenExpiry is the lifetime of access tokens issued to OAuth clients.
+	OAuthTokenExpiry time.Duration
+	// ImpersonationTokenExpiry is the lifetime of the tokens administrators get to act as another user.
+	ImpersonationTokenExpiry time.Duration
+	// SessionIdleTimeout ends sessions that have not been used for this long; zero disables it.
+	SessionIdleTimeout time.Duration
+	// SessionAbsoluteTimeout ends sessions this long after the login, however much they are used.
+	SessionAbsoluteTimeout time.Duration
+	// TLSPort enables a second listener that serves the API over HTTPS; empty disables it.
+	// TLSCertFile and TLSKeyFile are the PEM server certificate and key it uses.
+	TLSPort     string
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSClientCAFile is a PEM bundle of the CAs client certificates presented to the TLS listener
+	// must be issued by. Without it, the listener does not ask for client certificates.
+	TLSClientCAFile string
 	// Add other configuration fields as needed
 }
 
+// OIDCProvider is a client registered at an OpenID Connect provider such as Google, Azure AD or Keycloak.
+type OIDCProvider struct {
+	// Name identifies the provider in URLs, e.g. /api/v1/auth/oidc/google/authorize.
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the front end page the provider sends users back to.
+	RedirectURL string
+	Scopes      []string
+	// TrustEmail treats the provider's email addresses as verified without an email_verified claim.
+	TrustEmail bool
+}
+
+// SigningKeyFile points at a PEM encoded private key identified by a key ID (kid).
+type SigningKeyFile struct {
+	ID   string
+	Path string
+}
+
 // Load reads configuration from environment variables.
 // It uses the provided getenv function, which makes it testable.
 // If a .env file exists, it will be loaded first.
@@ -32,6 +139,7 @@ func Load(getenv func(key string) string) (*Config, error) {
 	}
 
 	cfg := &Config{}
+	var err error
 
 	cfg.AppEnv = getenv("APP_ENV")
 	if cfg.AppEnv == "" {
@@ -43,14 +151,34 @@ func Load(getenv func(key string) string) (*Config, error) {
 		cfg.HTTPPort = "8080" // Default port
 	}
 
+	cfg.TLSPort = getenv("TLS_PORT")
+	cfg.TLSCertFile = getenv("TLS_CERT_FILE")
+	cfg.TLSKeyFile = getenv("TLS_KEY_FILE")
+	cfg.TLSClientCAFile = getenv("TLS_CLIENT_CA_FILE")
+	if cfg.TLSPort != "" && (cfg.TLSCertFile == "" || cfg.TLSKeyFile == "") {
+		return nil, fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE are required when TLS_PORT is set")
+	}
+	if cfg.TLSPort == cfg.HTTPPort {
+		return nil, fmt.Errorf("TLS_PORT must differ from HTTP_PORT")
+	}
+
 	cfg.DatabaseDSN = getenv("DATABASE_DSN")
 	if cfg.DatabaseDSN == "" {
 		return nil, fmt.Errorf("DATABASE_DSN environment variable is required")
 	}
 
+	// JWT_SIGNING_KEYS is a comma separated list of kid=path pairs, e.g. "2025-01=/keys/a.pem,2025-06=/keys/b.pem".
+	cfg.JWTSigningKeys, err = parseSigningKeyFiles(getenv("JWT_SIGNING_KEYS"))
+	if err != nil {
+		return nil, err
+	}
+	cfg.JWTSigningKeyID = getenv("JWT_SIGNING_KEY_ID")
+
+	// JWT_SECRET is only required when no asymmetric signing keys are configured.
+	// When both are set, the secret is kept to verify legacy HS256 tokens.
 	cfg.JWTSecret = getenv("JWT_SECRET")
-	if cfg.JWTSecret == "" {
-		return nil, fmt.Errorf("JWT_SECRET environment variable is required")
+	if cfg.JWTSecret == "" && len(cfg.JWTSigningKeys) == 0 {
+		return nil, fmt.Errorf("JWT_SECRET or JWT_SIGNING_KEYS environment variable is required")
 	}
 
 	jwtExpiryMinutesStr := getenv("JWT_EXPIRY_MINUTES")
@@ -63,7 +191,367 @@ func Load(getenv func(key string) string) (*Config, error) {
 	}
 	cfg.JWTExpiryDuration = time.Duration(jwtExpiryMinutes) * time.Minute
 
+	cfg.JWTIssuer = getenv("JWT_ISSUER")
+	if cfg.JWTIssuer == "" {
+		cfg.JWTIssuer = "go-api-structure"
+	}
+
+	cfg.JWTAudience = getenv("JWT_AUDIENCE")
+	if cfg.JWTAudience == "" {
+		cfg.JWTAudience = "go-api-structure"
+	}
+
+	jwtLeewaySeconds, err := intFromEnv(getenv, "JWT_LEEWAY_SECONDS", 30)
+	if err != nil {
+		return nil, err
+	}
+	if jwtLeewaySeconds < 0 {
+		return nil, fmt.Errorf("JWT_LEEWAY_SECONDS must not be negative")
+	}
+	cfg.JWTLeeway = time.Duration(jwtLeewaySeconds) * time.Second
+
+	refreshExpiryHours, err := intFromEnv(getenv, "REFRESH_TOKEN_EXPIRY_HOURS", 720) // Default to 30 days
+	if err != nil {
+		return nil, err
+	}
+	cfg.RefreshTokenExpiryDuration = time.Duration(refreshExpiryHours) * time.Hour
+
+	purgeIntervalMinutes, err := intFromEnv(getenv, "PURGE_INTERVAL_MINUTES", 60)
+	if err != nil {
+		return nil, err
+	}
+	if purgeIntervalMinutes <= 0 {
+		return nil, fmt.Errorf("PURGE_INTERVAL_MINUTES must be positive")
+	}
+	cfg.PurgeInterval = time.Duration(purgeIntervalMinutes) * time.Minute
+
+	rotationGraceMinutes, err := intFromEnv(getenv, "API_KEY_ROTATION_GRACE_MINUTES", 1440) // Default to 24 hours
+	if err != nil {
+		return nil, err
+	}
+	if rotationGraceMinutes < 0 {
+		return nil, fmt.Errorf("API_KEY_ROTATION_GRACE_MINUTES must not be negative")
+	}
+	cfg.APIKeyRotationGracePeriod = time.Duration(rotationGraceMinutes) * time.Minute
+
+	cfg.TOTPIssuer = getenv("TOTP_ISSUER")
+	if cfg.TOTPIssuer == "" {
+		cfg.TOTPIssuer = "go-api-structure"
+	}
+
+	cfg.AppBaseURL = getenv("APP_BASE_URL")
+	if cfg.AppBaseURL == "" {
+		cfg.AppBaseURL = "http://localhost:" + cfg.HTTPPort
+	}
+
+	cfg.MailerDriver = getenv("MAILER_DRIVER")
+	if cfg.MailerDriver == "" {
+		cfg.MailerDriver = "log"
+	}
+	cfg.MailFrom = getenv("MAIL_FROM")
+	if cfg.MailFrom == "" {
+		cfg.MailFrom = "no-reply@localhost"
+	}
+
+	verificationExpiryHours, err := intFromEnv(getenv, "EMAIL_VERIFICATION_EXPIRY_HOURS", 24)
+	if err != nil {
+		return nil, err
+	}
+	if verificationExpiryHours <= 0 {
+		return nil, fmt.Errorf("EMAIL_VERIFICATION_EXPIRY_HOURS must be positive")
+	}
+	cfg.EmailVerificationExpiry = time.Duration(verificationExpiryHours) * time.Hour
+
+	passwordResetExpiryMinutes, err := intFromEnv(getenv, "PASSWORD_RESET_EXPIRY_MINUTES", 30)
+	if err != nil {
+		return nil, err
+	}
+	if passwordResetExpiryMinutes <= 0 {
+		return nil, fmt.Errorf("PASSWORD_RESET_EXPIRY_MINUTES must be positive")
+	}
+	cfg.PasswordResetExpiry = time.Duration(passwordResetExpiryMinutes) * time.Minute
+
+	cfg.RequireVerifiedEmail, err = boolFromEnv(getenv, "REQUIRE_VERIFIED_EMAIL", false)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.ConcealExistingAccounts, err = boolFromEnv(getenv, "REGISTRATION_CONCEAL_EXISTING_ACCOUNTS", false)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.LoginThrottleThreshold, err = intFromEnv(getenv, "LOGIN_THROTTLE_THRESHOLD", 5)
+	if err != nil {
+		return nil, err
+	}
+	cfg.LoginThrottleIPThreshold, err = intFromEnv(getenv, "LOGIN_THROTTLE_IP_THRESHOLD", 50)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.LoginThrottleThreshold < 0 || cfg.LoginThrottleIPThreshold < 0 {
+		return nil, fmt.Errorf("LOGIN_THROTTLE_THRESHOLD and LOGIN_THROTTLE_IP_THRESHOLD must not be negative")
+	}
+
+	throttleBaseDelaySeconds, err := intFromEnv(getenv, "LOGIN_THROTTLE_BASE_DELAY_SECONDS", 1)
+	if err != nil {
+		return nil, err
+	}
+	throttleMaxDelaySeconds, err := intFromEnv(getenv, "LOGIN_THROTTLE_MAX_DELAY_SECONDS", 300)
+	if err != nil {
+		return nil, err
+	}
+	if throttleBaseDelaySeconds <= 0 || throttleMaxDelaySeconds < throttleBaseDelaySeconds {
+		return nil, fmt.Errorf("LOGIN_THROTTLE_BASE_DELAY_SECONDS must be positive and not exceed LOGIN_THROTTLE_MAX_DELAY_SECONDS")
+	}
+	cfg.LoginThrottleBaseDelay = time.Duration(throttleBaseDelaySeconds) * time.Second
+	cfg.LoginThrottleMaxDelay = time.Duration(throttleMaxDelaySeconds) * time.Second
+
+	cfg.AccountLockoutThreshold, err = intFromEnv(getenv, "ACCOUNT_LOCKOUT_THRESHOLD", 10)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.AccountLockoutThreshold < 0 {
+		return nil, fmt.Errorf("ACCOUNT_LOCKOUT_THRESHOLD must not be negative")
+	}
+
+	lockoutMinutes, err := intFromEnv(getenv, "ACCOUNT_LOCKOUT_MINUTES", 15)
+	if err != nil {
+		return nil, err
+	}
+	if lockoutMinutes <= 0 {
+		return nil, fmt.Errorf("ACCOUNT_LOCKOUT_MINUTES must be positive")
+	}
+	cfg.AccountLockoutDuration = time.Duration(lockoutMinutes) * time.Minute
+
+	argon2Memory, err := intFromEnv(getenv, "PASSWORD_ARGON2_MEMORY_KIB", 19456)
+	if err != nil {
+		return nil, err
+	}
+	if argon2Memory < 8 || argon2Memory > 4*1024*1024 {
+		return nil, fmt.Errorf("PASSWORD_ARGON2_MEMORY_KIB must be between 8 and 4194304")
+	}
+	cfg.PasswordArgon2Memory = uint32(argon2Memory)
+
+	argon2Iterations, err := intFromEnv(getenv, "PASSWORD_ARGON2_ITERATIONS", 2)
+	if err != nil {
+		return nil, err
+	}
+	if argon2Iterations < 1 || argon2Iterations > 100 {
+		return nil, fmt.Errorf("PASSWORD_ARGON2_ITERATIONS must be between 1 and 100")
+	}
+	cfg.PasswordArgon2Iterations = uint32(argon2Iterations)
+
+	argon2Parallelism, err := intFromEnv(getenv, "PASSWORD_ARGON2_PARALLELISM", 1)
+	if err != nil {
+		return nil, err
+	}
+	if argon2Parallelism < 1 || argon2Parallelism > 255 {
+		return nil, fmt.Errorf("PASSWORD_ARGON2_PARALLELISM must be between 1 and 255")
+	}
+	cfg.PasswordArgon2Parallelism = uint8(argon2Parallelism)
+
+	minEntropyBits, err := intFromEnv(getenv, "PASSWORD_MIN_ENTROPY_BITS", 30)
+	if err != nil {
+		return nil, err
+	}
+	if minEntropyBits < 0 {
+		return nil, fmt.Errorf("PASSWORD_MIN_ENTROPY_BITS must not be negative")
+	}
+	cfg.PasswordMinEntropyBits = float64(minEntropyBits)
+
+	cfg.PasswordBreachedHashesFile = getenv("PASSWORD_BREACHED_HASHES_FILE")
+
+	cfg.PasswordRejectAccountSimilar, err = boolFromEnv(getenv, "PASSWORD_REJECT_ACCOUNT_SIMILAR", true)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.PasswordHistorySize, err = intFromEnv(getenv, "PASSWORD_HISTORY_SIZE", 0)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.PasswordHistorySize < 0 {
+		return nil, fmt.Errorf("PASSWORD_HISTORY_SIZE must not be negative")
+	}
+
+	permissionCacheSeconds, err := intFromEnv(getenv, "PERMISSION_CACHE_TTL_SECONDS", 60)
+	if err != nil {
+		return nil, err
+	}
+	if permissionCacheSeconds < 0 {
+		return nil, fmt.Errorf("PERMISSION_CACHE_TTL_SECONDS must not be negative")
+	}
+	cfg.PermissionCacheTTL = time.Duration(permissionCacheSeconds) * time.Second
+
+	cfg.SessionCookies, err = boolFromEnv(getenv, "SESSION_COOKIES", false)
+	if err != nil {
+		return nil, err
+	}
+	cfg.SessionCookieSecure, err = boolFromEnv(getenv, "SESSION_COOKIE_SECURE", true)
+	if err != nil {
+		return nil, err
+	}
+	cfg.SessionCookieSameSite = strings.ToLower(getenv("SESSION_COOKIE_SAMESITE"))
+	switch cfg.SessionCookieSameSite {
+	case "":
+		cfg.SessionCookieSameSite = "strict"
+	case "strict", "lax":
+	case "none":
+		if !cfg.SessionCookieSecure {
+			return nil, fmt.Errorf("SESSION_COOKIE_SAMESITE=none requires SESSION_COOKIE_SECURE")
+		}
+	default:
+		return nil, fmt.Errorf("invalid SESSION_COOKIE_SAMESITE %q, expected strict, lax or none", cfg.SessionCookieSameSite)
+	}
+	cfg.SessionCookieDomain = getenv("SESSION_COOKIE_DOMAIN")
+
+	// CORS_ALLOWED_ORIGINS is a comma separated list, e.g. "https://app.example.com,https://admin.example.com".
+	for _, origin := range strings.Split(getenv("CORS_ALLOWED_ORIGINS"), ",") {
+		origin = strings.TrimSpace(origin)
+		if origin == "" {
+			continue
+		}
+		if origin == "*" || !strings.Contains(origin, "://") {
+			return nil, fmt.Errorf("invalid CORS_ALLOWED_ORIGINS entry %q, expected an origin like https://app.example.com", origin)
+		}
+		cfg.CORSAllowedOrigins = append(cfg.CORSAllowedOrigins, strings.TrimSuffix(origin, "/"))
+	}
+
+	// OIDC_PROVIDERS is a comma separated list of provider names, e.g. "google,keycloak".
+	// Each one is configured with OIDC_<NAME>_* variables; see parseOIDCProvider.
+	for _, name := range strings.Split(getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		provider, err := parseOIDCProvider(getenv, name, cfg.AppBaseURL)
+		if err != nil {
+			return nil, err
+		}
+		cfg.OIDCProviders = append(cfg.OIDCProviders, provider)
+	}
+
+	oauthTokenExpiryMinutes, err := intFromEnv(getenv, "OAUTH_TOKEN_EXPIRY_MINUTES", 60)
+	if err != nil {
+		return nil, err
+	}
+	if oauthTokenExpiryMinutes <= 0 {
+		return nil, fmt.Errorf("OAUTH_TOKEN_EXPIRY_MINUTES must be positive")
+	}
+	cfg.OAuthTokenExpiry = time.Duration(oauthTokenExpiryMinutes) * time.Minute
+
+	impersonationExpiryMinutes, err := intFromEnv(getenv, "IMPERSONATION_TOKEN_EXPIRY_MINUTES", 15)
+	if err != nil {
+		return nil, err
+	}
+	if impersonationExpiryMinutes <= 0 {
+		return nil, fmt.Errorf("IMPERSONATION_TOKEN_EXPIRY_MINUTES must be positive")
+	}
+	cfg.ImpersonationTokenExpiry = time.Duration(impersonationExpiryMinutes) * time.Minute
+
+	sessionIdleMinutes, err := intFromEnv(getenv, "SESSION_IDLE_TIMEOUT_MINUTES", 0)
+	if err != nil {
+		return nil, err
+	}
+	if sessionIdleMinutes < 0 {
+		return nil, fmt.Errorf("SESSION_IDLE_TIMEOUT_MINUTES must not be negative")
+	}
+	cfg.SessionIdleTimeout = time.Duration(sessionIdleMinutes) * time.Minute
+
+	sessionAbsoluteHours, err := intFromEnv(getenv, "SESSION_ABSOLUTE_TIMEOUT_HOURS", 720) // Default to 30 days
+	if err != nil {
+		return nil, err
+	}
+	if sessionAbsoluteHours <= 0 {
+		return nil, fmt.Errorf("SESSION_ABSOLUTE_TIMEOUT_HOURS must be positive")
+	}
+	cfg.SessionAbsoluteTimeout = time.Duration(sessionAbsoluteHours) * time.Hour
+
 	// Add loading for other config fields here
 
 	return cfg, nil
 }
+
+// intFromEnv reads an integer environment variable, falling back to def when it is unset.
+func intFromEnv(getenv func(key string) string, key string, def int) (int, error) {
+	value := getenv(key)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return n, nil
+}
+
+// boolFromEnv reads a boolean environment variable, falling back to def when it is unset.
+func boolFromEnv(getenv func(key string) string, key string, def bool) (bool, error) {
+	value := getenv(key)
+	if value == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return b, nil
+}
+
+// parseSigningKeyFiles parses a comma separated list of kid=path pairs.
+func parseSigningKeyFiles(value string) ([]SigningKeyFile, error) {
+	var files []SigningKeyFile
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, path, ok := strings.Cut(entry, "=")
+		if !ok || id == "" || path == "" {
+			return nil, fmt.Errorf("invalid JWT_SIGNING_KEYS entry %q, expected kid=path", entry)
+		}
+		files = append(files, SigningKeyFile{ID: strings.TrimSpace(id), Path: strings.TrimSpace(path)})
+	}
+	return files, nil
+}
+
+// parseOIDCProvider reads the settings of the named OpenID Connect provider from
+// OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL, _SCOPES and _TRUST_EMAIL,
+// where NAME is the upper cased name with dashes replaced by underscores.
+func parseOIDCProvider(getenv func(key string) string, name, appBaseURL string) (OIDCProvider, error) {
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+			return OIDCProvider{}, fmt.Errorf("invalid OIDC_PROVIDERS entry %q, expected lowercase letters, digits and dashes", name)
+		}
+	}
+	prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+
+	provider := OIDCProvider{
+		Name:         name,
+		Issuer:       getenv(prefix + "ISSUER"),
+		ClientID:     getenv(prefix + "CLIENT_ID"),
+		ClientSecret: getenv(prefix + "CLIENT_SECRET"),
+		RedirectURL:  getenv(prefix + "REDIRECT_URL"),
+		Scopes:       strings.Fields(getenv(prefix + "SCOPES")),
+	}
+	if provider.Issuer == "" || provider.ClientID == "" {
+		return OIDCProvider{}, fmt.Errorf("%sISSUER and %sCLIENT_ID are required", prefix, prefix)
+	}
+	if !strings.HasPrefix(provider.Issuer, "https://") && !strings.HasPrefix(provider.Issuer, "http://") {
+		return OIDCProvider{}, fmt.Errorf("invalid %sISSUER %q, expected a URL", prefix, provider.Issuer)
+	}
+	if provider.RedirectURL == "" {
+		provider.RedirectURL = strings.TrimSuffix(appBaseURL, "/") + "/auth/oidc/" + name + "/callback"
+	}
+	if len(provider.Scopes) == 0 {
+		provider.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	var err error
+	provider.TrustEmail, err = boolFromEnv(getenv, prefix+"TRUST_EMAIL", false)
+	if err != nil {
+		return OIDCProvider{}, err
+	}
+	return provider, nil
+}
@@ -0,0 +1,82 @@
+package janitor
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Store defines the purge operations the janitor runs periodically.
+type Store interface {
+	DeleteExpiredRevokedTokens(ctx context.Context) (int64, error)
+	DeleteExpiredRefreshTokens(ctx context.Context) (int64, error)
+	DeleteExpiredPasswordResetTokens(ctx context.Context) (int64, error)
+	DeleteExpiredEmailChangeRequests(ctx context.Context) (int64, error)
+	DeleteStaleLoginAttempts(ctx context.Context) (int64, error)
+	DeleteExpiredOIDCLoginStates(ctx context.Context) (int64, error)
+	DeleteExpiredSessions(ctx context.Context) (int64, error)
+}
+
+// Janitor periodically removes rows that are no longer needed, such as
+// revocation list entries for tokens that have expired anyway.
+type Janitor struct {
+	store    Store
+	logger   *slog.Logger
+	interval time.Duration
+}
+
+// New creates a new Janitor that runs every interval.
+func New(store Store, logger *slog.Logger, interval time.Duration) *Janitor {
+	return &Janitor{
+		store:    store,
+		logger:   logger,
+		interval: interval,
+	}
+}
+
+// Run purges expired rows immediately and then on every tick until ctx is cancelled.
+// It is meant to be started in its own goroutine.
+func (j *Janitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.purge(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purge runs every purge task once, logging failures without stopping the others.
+func (j *Janitor) purge(ctx context.Context) {
+	tasks := []struct {
+		name string
+		fn   func(ctx context.Context) (int64, error)
+	}{
+		{"revoked_tokens", j.store.DeleteExpiredRevokedTokens},
+		{"refresh_tokens", j.store.DeleteExpiredRefreshTokens},
+		{"password_reset_tokens", j.store.DeleteExpiredPasswordResetTokens},
+		{"email_change_requests", j.store.DeleteExpiredEmailChangeRequests},
+		{"login_attempts", j.store.DeleteStaleLoginAttempts},
+		{"oidc_login_states", j.store.DeleteExpiredOIDCLoginStates},
+		{"sessions", j.store.DeleteExpiredSessions},
+	}
+
+	for _, task := range tasks {
+		deleted, err := task.fn(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return // Shutting down
+			}
+			j.logger.Error("Failed to purge expired rows", "table", task.name, "error", err)
+			continue
+		}
+		if deleted > 0 {
+			j.logger.Info("Purged expired rows", "table", task.name, "count", deleted)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Message is a plain text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the Mailer selected by driver.
+// Only "log" is built in; other drivers can be added here.
+func New(driver, from string, logger *slog.Logger) (Mailer, error) {
+	switch driver {
+	case "", "log":
+		return NewLogMailer(from, logger), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer driver %q", driver)
+	}
+}
+
+// LogMailer writes emails to the log instead of sending them.
+// It is meant for local development, where links in emails can be copied from the log.
+type LogMailer struct {
+	from   string
+	logger *slog.Logger
+}
+
+// NewLogMailer creates a new LogMailer.
+func NewLogMailer(from string, logger *slog.Logger) *LogMailer {
+	return &LogMailer{
+		from:   from,
+		logger: logger,
+	}
+}
+
+// Send logs the message.
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	m.logger.InfoContext(ctx, "Email sent",
+		"from", m.from,
+		"to", msg.To,
+		"subject", msg.Subject,
+		"body", msg.Body,
+	)
+	return nil
+}
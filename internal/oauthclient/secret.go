@@ -0,0 +1,35 @@
+package oauthclient
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// SecretPrefix marks every client secret issued by this service so leaked secrets are easy
+// to recognise, e.g. by secret scanners. A full secret looks like gas_client_<secret>.
+const SecretPrefix = "gas_client_"
+
+const secretBytes = 32 // 64 hex characters of entropy
+
+// generateSecret returns a new raw client secret.
+func generateSecret() (string, error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return SecretPrefix + hex.EncodeToString(b), nil
+}
+
+// hashSecret returns the hex encoded SHA-256 digest of a raw secret.
+// Secrets carry enough entropy that a fast hash is sufficient.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// secretMatches reports whether secret hashes to the stored digest, in constant time.
+func secretMatches(secret, secretHash string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(secretHash)) == 1
+}
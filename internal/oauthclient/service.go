@@ -0,0 +1,214 @@
+// Package oauthclient manages the OAuth clients machines use to obtain access tokens with the
+// client credentials grant, instead of borrowing a person's API key. Every change is recorded
+// in the audit trail with the administrator who made it.
+package oauthclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/audit"
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+var (
+	ErrClientNotFound = errors.New("OAuth client not found")
+	ErrClientRevoked  = errors.New("OAuth client has been revoked")
+	// ErrInvalidClient is returned by Authenticate for unknown clients, wrong secrets and revoked clients alike.
+	ErrInvalidClient = errors.New("invalid client credentials")
+)
+
+// CreateParams holds the settings for a new client.
+type CreateParams struct {
+	Name   string
+	Scopes []string
+}
+
+// UpdateParams holds the fields of a client that can be changed. Nil fields are left unchanged.
+type UpdateParams struct {
+	Name   *string
+	Scopes []string
+}
+
+// ServiceInterface defines the operations for managing and authenticating OAuth clients.
+type ServiceInterface interface {
+	// Create registers a new client and returns it together with its raw secret.
+	// Only a digest of the secret is stored, so this is the only time the raw secret is available.
+	Create(ctx context.Context, actor audit.Actor, params CreateParams) (*db.OauthClient, string, error)
+	List(ctx context.Context) ([]db.OauthClient, error)
+	Get(ctx context.Context, id uuid.UUID) (*db.OauthClient, error)
+	// Update renames a client or replaces its scopes. Tokens already issued keep their scopes until they expire.
+	Update(ctx context.Context, actor audit.Actor, id uuid.UUID, params UpdateParams) (*db.OauthClient, error)
+	// RotateSecret replaces a client's secret; the old one stops working at once.
+	RotateSecret(ctx context.Context, actor audit.Actor, id uuid.UUID) (*db.OauthClient, string, error)
+	// Revoke permanently disables a client and every token issued to it.
+	Revoke(ctx context.Context, actor audit.Actor, id uuid.UUID) (*db.OauthClient, error)
+	// Authenticate checks a client's credentials, rejecting unknown and revoked clients.
+	Authenticate(ctx context.Context, clientID, secret string) (*db.OauthClient, error)
+}
+
+// Service provides OAuth client operations.
+type Service struct {
+	clientStore store.OAuthClientStore
+	recorder    *audit.Recorder
+}
+
+// NewService creates a new OAuth client Service.
+func NewService(clientStore store.OAuthClientStore, recorder *audit.Recorder) *Service {
+	return &Service{
+		clientStore: clientStore,
+		recorder:    recorder,
+	}
+}
+
+// Create registers a new client.
+func (s *Service) Create(ctx context.Context, actor audit.Actor, params CreateParams) (*db.OauthClient, string, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate client secret: %w", err)
+	}
+
+	client, err := s.clientStore.CreateOAuthClient(ctx, db.CreateOAuthClientParams{
+		Name:       params.Name,
+		SecretHash: hashSecret(secret),
+		Scopes:     normalizeScopes(params.Scopes),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create OAuth client: %w", err)
+	}
+
+	s.recorder.Record(ctx, actor, audit.ActionOAuthClientCreate, client.ID, map[string]any{
+		"name":   client.Name,
+		"scopes": client.Scopes,
+	})
+	return &client, secret, nil
+}
+
+// List returns all clients, newest first, including revoked ones.
+func (s *Service) List(ctx context.Context) ([]db.OauthClient, error) {
+	clients, err := s.clientStore.ListOAuthClients(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list OAuth clients: %w", err)
+	}
+	return clients, nil
+}
+
+// Get returns a client, or ErrClientNotFound.
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*db.OauthClient, error) {
+	client, err := s.clientStore.GetOAuthClient(ctx, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, fmt.Errorf("failed to get OAuth client: %w", err)
+	}
+	return &client, nil
+}
+
+// Update changes a client's name or scopes. Revoked clients cannot be changed.
+func (s *Service) Update(ctx context.Context, actor audit.Actor, id uuid.UUID, params UpdateParams) (*db.OauthClient, error) {
+	arg := db.UpdateOAuthClientParams{ID: id}
+	if params.Name != nil {
+		arg.Name = pgtype.Text{String: *params.Name, Valid: true}
+	}
+	if params.Scopes != nil {
+		arg.Scopes = normalizeScopes(params.Scopes)
+	}
+
+	client, err := s.clientStore.UpdateOAuthClient(ctx, arg)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, s.notFoundOrRevoked(ctx, id)
+		}
+		return nil, fmt.Errorf("failed to update OAuth client: %w", err)
+	}
+
+	s.recorder.Record(ctx, actor, audit.ActionOAuthClientUpdate, client.ID, map[string]any{
+		"name":   client.Name,
+		"scopes": client.Scopes,
+	})
+	return &client, nil
+}
+
+// RotateSecret issues a new secret for a client. Tokens issued with the old secret
+// keep working until they expire; revoke the client to stop them too.
+func (s *Service) RotateSecret(ctx context.Context, actor audit.Actor, id uuid.UUID) (*db.OauthClient, string, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate client secret: %w", err)
+	}
+
+	client, err := s.clientStore.SetOAuthClientSecret(ctx, db.SetOAuthClientSecretParams{
+		ID:         id,
+		SecretHash: hashSecret(secret),
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, "", s.notFoundOrRevoked(ctx, id)
+		}
+		return nil, "", fmt.Errorf("failed to rotate client secret: %w", err)
+	}
+
+	s.recorder.Record(ctx, actor, audit.ActionOAuthClientRotateSecret, client.ID, nil)
+	return &client, secret, nil
+}
+
+// Revoke disables a client. The record is kept so it still shows up in listings.
+func (s *Service) Revoke(ctx context.Context, actor audit.Actor, id uuid.UUID) (*db.OauthClient, error) {
+	client, err := s.clientStore.RevokeOAuthClient(ctx, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, fmt.Errorf("failed to revoke OAuth client: %w", err)
+	}
+
+	s.recorder.Record(ctx, actor, audit.ActionOAuthClientRevoke, client.ID, nil)
+	return &client, nil
+}
+
+// Authenticate looks up a client by its client ID, which is the client's UUID, and verifies the secret.
+func (s *Service) Authenticate(ctx context.Context, clientID, secret string) (*db.OauthClient, error) {
+	id, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, ErrInvalidClient
+	}
+	client, err := s.clientStore.GetOAuthClient(ctx, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrInvalidClient
+		}
+		return nil, fmt.Errorf("failed to get OAuth client: %w", err)
+	}
+
+	if !secretMatches(secret, client.SecretHash) || client.RevokedAt.Valid {
+		return nil, ErrInvalidClient
+	}
+
+	if err := s.clientStore.TouchOAuthClient(ctx, client.ID); err != nil {
+		return nil, fmt.Errorf("failed to record OAuth client usage: %w", err)
+	}
+
+	return &client, nil
+}
+
+// notFoundOrRevoked tells apart why a client could not be changed.
+func (s *Service) notFoundOrRevoked(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	return ErrClientRevoked
+}
+
+// normalizeScopes sorts scopes and removes duplicates so stored clients compare predictably.
+func normalizeScopes(scopes []string) []string {
+	normalized := slices.Clone(scopes)
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
@@ -0,0 +1,93 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+)
+
+func TestJWKPublicKey(t *testing.T) {
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaJWK := jwk{KeyType: "RSA", N: b64(rsaKey.N.Bytes()), E: "AQAB"}
+	ecJWK := jwk{KeyType: "EC", Curve: "P-256", X: b64(ecKey.X.FillBytes(make([]byte, 32))), Y: b64(ecKey.Y.FillBytes(make([]byte, 32)))}
+	edJWK := jwk{KeyType: "OKP", Curve: "Ed25519", X: b64(edKey)}
+
+	with := func(k jwk, change func(*jwk)) jwk {
+		change(&k)
+		return k
+	}
+
+	tests := []struct {
+		name    string
+		jwk     jwk
+		wantErr bool
+	}{
+		{"RSA", rsaJWK, false},
+		{"EC P-256", ecJWK, false},
+		{"Ed25519", edJWK, false},
+		{"RSA without modulus", with(rsaJWK, func(k *jwk) { k.N = "" }), true},
+		{"RSA with invalid exponent", with(rsaJWK, func(k *jwk) { k.E = "not base64!" }), true},
+		{"RSA with huge exponent", with(rsaJWK, func(k *jwk) { k.E = b64([]byte{1, 0, 0, 0, 1}) }), true},
+		{"EC on another curve", with(ecJWK, func(k *jwk) { k.Curve = "P-384" }), true},
+		{"EC with unknown curve", with(ecJWK, func(k *jwk) { k.Curve = "secp256k1" }), true},
+		{"EC point not on the curve", with(ecJWK, func(k *jwk) { k.Y = k.X }), true},
+		{"Ed25519 key too short", with(edJWK, func(k *jwk) { k.X = b64(edKey[:16]) }), true},
+		{"X25519", with(edJWK, func(k *jwk) { k.Curve = "X25519" }), true},
+		{"symmetric key", jwk{KeyType: "oct"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.jwk.publicKey()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("publicKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBoolUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		json    string
+		want    Bool
+		wantErr bool
+	}{
+		{`true`, true, false},
+		{`false`, false, false},
+		{`"true"`, true, false},
+		{`"false"`, false, false},
+		{`"yes"`, false, false},
+		{`null`, false, false},
+		{`1`, false, true},
+	}
+	for _, tt := range tests {
+		var claims struct {
+			EmailVerified Bool `json:"email_verified"`
+		}
+		err := json.Unmarshal([]byte(`{"email_verified":`+tt.json+`}`), &claims)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, wantErr %v", tt.json, err, tt.wantErr)
+			continue
+		}
+		if claims.EmailVerified != tt.want {
+			t.Errorf("Unmarshal(%s) = %v, want %v", tt.json, claims.EmailVerified, tt.want)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+// Package oidctest runs an in-process OpenID Connect provider for tests. It serves discovery,
+// a JWKS and a token endpoint that enforces client authentication and PKCE, and signs ID tokens
+// with an RSA key it generates itself.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Identity is the account a user signs in with at the provider.
+type Identity struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// grant is an authorization code waiting to be redeemed.
+type grant struct {
+	identity      Identity
+	nonce         string
+	redirectURI   string
+	codeChallenge string
+}
+
+// Server is a running provider. Its URL is the issuer.
+type Server struct {
+	*httptest.Server
+
+	ClientID     string
+	ClientSecret string
+	// TokenEndpointAuthMethods is advertised in the discovery document; client_secret_basic
+	// and client_secret_post are both accepted regardless.
+	TokenEndpointAuthMethods []string
+
+	mu           sync.Mutex
+	key          *rsa.PrivateKey
+	keyID        string
+	retiredKeys  map[string]*rsa.PrivateKey
+	grants       map[string]grant
+	jwksRequests int
+	idTokenHook  func(jwt.MapClaims)
+}
+
+// NewServer starts a provider with a registered client and stops it when the test ends.
+func NewServer(t testing.TB, clientID, clientSecret string) *Server {
+	t.Helper()
+	s := &Server{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		retiredKeys:  make(map[string]*rsa.PrivateKey),
+		grants:       make(map[string]grant),
+	}
+	if err := s.RotateKey(); err != nil {
+		t.Fatalf("oidctest: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.handleDiscovery)
+	mux.HandleFunc("GET /jwks", s.handleJWKS)
+	mux.HandleFunc("POST /token", s.handleToken)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// RotateKey signs further ID tokens with a new key. The old key is no longer published.
+func (s *Server) RotateKey() error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.key != nil {
+		s.retiredKeys[s.keyID] = s.key
+	}
+	s.key = key
+	s.keyID = fmt.Sprintf("key-%d", len(s.retiredKeys)+1)
+	return nil
+}
+
+// KeyID returns the kid of the key ID tokens are currently signed with.
+func (s *Server) KeyID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keyID
+}
+
+// JWKSRequests returns how often the key set has been fetched.
+func (s *Server) JWKSRequests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jwksRequests
+}
+
+// ModifyIDTokens calls hook with the claims of every ID token the token endpoint issues
+// from now on, before it is signed.
+func (s *Server) ModifyIDTokens(hook func(jwt.MapClaims)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.idTokenHook = hook
+}
+
+// Login plays the user signing in at the authorization endpoint: it checks the authorization
+// request in authURL and returns the code and state the provider redirects back with.
+func (s *Server) Login(authURL string, identity Identity) (code, state string, err error) {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return "", "", err
+	}
+	q := u.Query()
+	switch {
+	case u.Scheme+"://"+u.Host+u.Path != s.URL+"/authorize":
+		return "", "", fmt.Errorf("authorization request sent to %s", u.Path)
+	case q.Get("response_type") != "code":
+		return "", "", fmt.Errorf("unsupported response_type %q", q.Get("response_type"))
+	case q.Get("client_id") != s.ClientID:
+		return "", "", fmt.Errorf("unknown client_id %q", q.Get("client_id"))
+	case q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "":
+		return "", "", fmt.Errorf("missing S256 code challenge")
+	case q.Get("state") == "" || q.Get("nonce") == "":
+		return "", "", fmt.Errorf("missing state or nonce")
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	code = base64.RawURLEncoding.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.grants[code] = grant{
+		identity:      identity,
+		nonce:         q.Get("nonce"),
+		redirectURI:   q.Get("redirect_uri"),
+		codeChallenge: q.Get("code_challenge"),
+	}
+	return code, q.Get("state"), nil
+}
+
+// SignIDToken signs claims with the current key, or with a retired one given its kid.
+// Issuer, audience and lifetime are filled in unless claims sets them.
+func (s *Server) SignIDToken(claims jwt.MapClaims, kid string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.signIDToken(claims, kid)
+}
+
+func (s *Server) signIDToken(claims jwt.MapClaims, kid string) (string, error) {
+	key := s.key
+	if kid == "" {
+		kid = s.keyID
+	} else if retired, ok := s.retiredKeys[kid]; ok {
+		key = retired
+	}
+
+	now := time.Now()
+	defaults := jwt.MapClaims{
+		"iss": s.URL,
+		"aud": s.ClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+	}
+	for name, value := range defaults {
+		if _, ok := claims[name]; !ok {
+			claims[name] = value
+		}
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	return token.SignedString(key)
+}
+
+func (s *Server) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/jwks",
+		"token_endpoint_auth_methods_supported": s.TokenEndpointAuthMethods,
+	})
+}
+
+func (s *Server) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jwksRequests++
+
+	encode := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": s.keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   encode(s.key.N),
+			"e":   encode(big.NewInt(int64(s.key.E))),
+		}},
+	})
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != s.ClientID || clientSecret != s.ClientSecret {
+		tokenError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Codes are single use, even when the request is rejected.
+	code := r.PostForm.Get("code")
+	g, ok := s.grants[code]
+	delete(s.grants, code)
+	if !ok || g.redirectURI != r.PostForm.Get("redirect_uri") {
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != g.codeChallenge {
+		tokenError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"sub":            g.identity.Subject,
+		"nonce":          g.nonce,
+		"email":          g.identity.Email,
+		"email_verified": g.identity.EmailVerified,
+	}
+	if g.identity.Name != "" {
+		claims["name"] = g.identity.Name
+	}
+	if g.identity.PreferredUsername != "" {
+		claims["preferred_username"] = g.identity.PreferredUsername
+	}
+	if s.idTokenHook != nil {
+		s.idTokenHook(claims)
+	}
+
+	idToken, err := s.signIDToken(claims, "")
+	if err != nil {
+		tokenError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": "unused",
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func tokenError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
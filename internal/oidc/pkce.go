@@ -0,0 +1,34 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// randomBytes is the entropy in generated code verifiers and nonces.
+const randomBytes = 32
+
+// NewCodeVerifier returns a random PKCE code verifier of 43 characters.
+func NewCodeVerifier() (string, error) {
+	return randomString()
+}
+
+// NewNonce returns a random value to bind an ID token to the login it was requested for.
+func NewNonce() (string, error) {
+	return randomString()
+}
+
+// CodeChallenge derives the S256 code challenge sent along with the authorization request.
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomString() (string, error) {
+	b := make([]byte, randomBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
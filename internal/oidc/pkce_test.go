@@ -0,0 +1,43 @@
+package oidc
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestCodeChallenge(t *testing.T) {
+	// RFC 7636 appendix B.
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	const want = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	if got := CodeChallenge(verifier); got != want {
+		t.Errorf("CodeChallenge() = %q, want %q", got, want)
+	}
+}
+
+func TestRandomValues(t *testing.T) {
+	// RFC 7636 section 4.1: 43 to 128 unreserved characters.
+	unreserved := regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+
+	for name, generate := range map[string]func() (string, error){
+		"NewCodeVerifier": NewCodeVerifier,
+		"NewNonce":        NewNonce,
+	} {
+		t.Run(name, func(t *testing.T) {
+			first, err := generate()
+			if err != nil {
+				t.Fatalf("%s() error = %v", name, err)
+			}
+			second, err := generate()
+			if err != nil {
+				t.Fatalf("%s() error = %v", name, err)
+			}
+			if !unreserved.MatchString(first) {
+				t.Errorf("%s() = %q, want 43 to 128 unreserved characters", name, first)
+			}
+			if first == second {
+				t.Errorf("%s() returned %q twice", name, first)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// Package oidc signs users in through external OpenID Connect providers such as Google,
+// Azure AD or Keycloak, using the authorization code flow with PKCE (RFC 7636).
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrCodeRejected is returned by Exchange when the provider refuses the authorization code,
+	// e.g. because it expired, was already used or does not match the code verifier.
+	ErrCodeRejected = errors.New("authorization code rejected by the provider")
+	// ErrInvalidIDToken is returned by VerifyIDToken for ID tokens that must not be trusted.
+	ErrInvalidIDToken = errors.New("invalid ID token")
+)
+
+// maxResponseBytes bounds what is read from a provider, which is enough for any sane response.
+const maxResponseBytes = 1 << 20
+
+// Config describes a client registered at an OpenID Connect provider.
+type Config struct {
+	// Name identifies the provider in URLs and in stored identities, e.g. "google".
+	Name string
+	// Issuer is the provider's issuer URL; its metadata is discovered from
+	// Issuer + "/.well-known/openid-configuration".
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is where the provider sends the user back to with the authorization code.
+	// It must be registered with the provider exactly as given.
+	RedirectURL string
+	// Scopes are requested in addition to "openid".
+	Scopes []string
+	// TrustEmail treats email addresses as verified even when the ID token does not say so,
+	// for providers that only hand out addresses they control, like most enterprise directories.
+	TrustEmail bool
+	// Leeway is the clock skew tolerated when validating ID tokens.
+	Leeway time.Duration
+}
+
+// Metadata is the part of the provider's discovery document the relying party needs.
+type Metadata struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+// Provider is an OpenID Connect provider. Its metadata and signing keys are fetched on first use
+// and cached; keys are fetched again when an ID token is signed with an unknown one.
+// A Provider is safe for concurrent use.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu            sync.Mutex
+	metadata      *Metadata
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+// NewProvider creates a Provider that talks to the provider over client.
+// Nothing is fetched until the provider is first used.
+func NewProvider(cfg Config, client *http.Client) *Provider {
+	cfg.Issuer = strings.TrimSuffix(cfg.Issuer, "/")
+	return &Provider{config: cfg, client: client}
+}
+
+// Name returns the name the provider was configured with.
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+// TrustsEmail reports whether email addresses from this provider count as verified
+// without an email_verified claim.
+func (p *Provider) TrustsEmail() bool {
+	return p.config.TrustEmail
+}
+
+// AuthCodeURL returns the URL to send the user to in order to sign in at the provider.
+// state and nonce must be unguessable and are checked when the user comes back;
+// codeVerifier is the PKCE secret later passed to Exchange.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	metadata, err := p.Metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	scopes := []string{"openid"}
+	for _, scope := range p.config.Scopes {
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {CodeChallenge(codeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// tokenResponse is the token endpoint's answer, successful or not (RFC 6749 section 5).
+type tokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange redeems an authorization code at the provider's token endpoint and returns the raw ID token.
+// It returns an error matching ErrCodeRejected if the provider refuses the code.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (string, error) {
+	metadata, err := p.Metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+
+	// client_secret_basic is the default every provider must support, unless it only lists client_secret_post.
+	useBasicAuth := p.config.ClientSecret != "" &&
+		(len(metadata.TokenEndpointAuthMethodsSupported) == 0 ||
+			slices.Contains(metadata.TokenEndpointAuthMethodsSupported, "client_secret_basic") ||
+			!slices.Contains(metadata.TokenEndpointAuthMethodsSupported, "client_secret_post"))
+	if !useBasicAuth {
+		form.Set("client_id", p.config.ClientID)
+		if p.config.ClientSecret != "" {
+			form.Set("client_secret", p.config.ClientSecret)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if useBasicAuth {
+		// RFC 6749 section 2.3.1 wants both parts form encoded before they are joined.
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call token endpoint of %s: %w", p.config.Name, err)
+	}
+	defer resp.Body.Close()
+
+	var body tokenResponse
+	decodeErr := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&body)
+	switch {
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized || body.Error != "":
+		return "", fmt.Errorf("%w: %s %s", ErrCodeRejected, body.Error, body.ErrorDescription)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("token endpoint of %s answered with status %d", p.config.Name, resp.StatusCode)
+	case decodeErr != nil:
+		return "", fmt.Errorf("failed to decode token response of %s: %w", p.config.Name, decodeErr)
+	}
+	if body.IDToken == "" {
+		return "", fmt.Errorf("%w: no ID token in the token response", ErrInvalidIDToken)
+	}
+	return body.IDToken, nil
+}
+
+// Metadata returns the provider's discovery document, fetching it on first use.
+// Failures are not cached, so a provider that was down at startup is picked up once it is back.
+func (p *Provider) Metadata(ctx context.Context) (*Metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var metadata Metadata
+	if err := p.getJSON(ctx, p.config.Issuer+"/.well-known/openid-configuration", &metadata); err != nil {
+		return nil, fmt.Errorf("failed to discover %s: %w", p.config.Name, err)
+	}
+	// OpenID Connect Discovery section 4.3: the document must be about the issuer it was fetched from.
+	if strings.TrimSuffix(metadata.Issuer, "/") != p.config.Issuer {
+		return nil, fmt.Errorf("discovery document of %s is for issuer %q", p.config.Name, metadata.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document of %s lacks required endpoints", p.config.Name)
+	}
+
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+// getJSON fetches url and decodes its JSON body into v.
+func (p *Provider) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(v)
+}
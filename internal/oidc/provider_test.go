@@ -0,0 +1,311 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"go-api-structure/internal/oidc"
+	"go-api-structure/internal/oidc/oidctest"
+)
+
+const redirectURL = "https://app.example.com/oidc/callback"
+
+func newProvider(t *testing.T) (*oidctest.Server, *oidc.Provider) {
+	t.Helper()
+	server := oidctest.NewServer(t, "client", "client secret")
+	provider := oidc.NewProvider(oidc.Config{
+		Name:         "test",
+		Issuer:       server.URL + "/",
+		ClientID:     server.ClientID,
+		ClientSecret: server.ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"email", "openid", "profile"},
+	}, server.Client())
+	return server, provider
+}
+
+// login runs the authorization code flow up to the ID token.
+func login(t *testing.T, server *oidctest.Server, provider *oidc.Provider, identity oidctest.Identity, nonce string) string {
+	t.Helper()
+	ctx := context.Background()
+
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := provider.AuthCodeURL(ctx, "state", nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	code, _, err := server.Login(authURL, identity)
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	rawIDToken, err := provider.Exchange(ctx, code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	return rawIDToken
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	_, provider := newProvider(t)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "the state", "the nonce", "the verifier")
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() = %q: %v", authURL, err)
+	}
+
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "client",
+		"redirect_uri":          redirectURL,
+		"scope":                 "openid email profile",
+		"state":                 "the state",
+		"nonce":                 "the nonce",
+		"code_challenge":        oidc.CodeChallenge("the verifier"),
+		"code_challenge_method": "S256",
+	}
+	for name, value := range want {
+		if got := u.Query().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	if u.Query().Has("code_verifier") {
+		t.Error("the code verifier must not be sent to the authorization endpoint")
+	}
+}
+
+func TestLogin(t *testing.T) {
+	for _, methods := range [][]string{nil, {"client_secret_basic"}, {"client_secret_post"}} {
+		t.Run(fmtMethods(methods), func(t *testing.T) {
+			server, provider := newProvider(t)
+			server.TokenEndpointAuthMethods = methods
+
+			identity := oidctest.Identity{Subject: "1234", Email: "jane@example.com", EmailVerified: true, PreferredUsername: "jane"}
+			rawIDToken := login(t, server, provider, identity, "nonce")
+
+			claims, err := provider.VerifyIDToken(context.Background(), rawIDToken, "nonce")
+			if err != nil {
+				t.Fatalf("VerifyIDToken() error = %v", err)
+			}
+			if claims.Subject != "1234" || claims.Email != "jane@example.com" || !bool(claims.EmailVerified) || claims.PreferredUsername != "jane" {
+				t.Errorf("VerifyIDToken() = %+v", claims)
+			}
+		})
+	}
+}
+
+func fmtMethods(methods []string) string {
+	if len(methods) == 0 {
+		return "default"
+	}
+	return methods[0]
+}
+
+func TestExchangeRejected(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		exchange func(t *testing.T, server *oidctest.Server, provider *oidc.Provider, code, verifier string) error
+	}{
+		{"wrong code verifier", func(t *testing.T, _ *oidctest.Server, provider *oidc.Provider, code, _ string) error {
+			other, _ := oidc.NewCodeVerifier()
+			_, err := provider.Exchange(ctx, code, other)
+			return err
+		}},
+		{"unknown code", func(t *testing.T, _ *oidctest.Server, provider *oidc.Provider, _, verifier string) error {
+			_, err := provider.Exchange(ctx, "made up", verifier)
+			return err
+		}},
+		{"code used twice", func(t *testing.T, _ *oidctest.Server, provider *oidc.Provider, code, verifier string) error {
+			if _, err := provider.Exchange(ctx, code, verifier); err != nil {
+				t.Fatalf("first Exchange() error = %v", err)
+			}
+			_, err := provider.Exchange(ctx, code, verifier)
+			return err
+		}},
+		{"wrong client secret", func(t *testing.T, server *oidctest.Server, _ *oidc.Provider, code, verifier string) error {
+			provider := oidc.NewProvider(oidc.Config{
+				Name:         "test",
+				Issuer:       server.URL,
+				ClientID:     server.ClientID,
+				ClientSecret: "wrong",
+				RedirectURL:  redirectURL,
+			}, server.Client())
+			_, err := provider.Exchange(ctx, code, verifier)
+			return err
+		}},
+		{"other redirect URL", func(t *testing.T, server *oidctest.Server, _ *oidc.Provider, code, verifier string) error {
+			provider := oidc.NewProvider(oidc.Config{
+				Name:         "test",
+				Issuer:       server.URL,
+				ClientID:     server.ClientID,
+				ClientSecret: server.ClientSecret,
+				RedirectURL:  "https://evil.example.com/callback",
+			}, server.Client())
+			_, err := provider.Exchange(ctx, code, verifier)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, provider := newProvider(t)
+			verifier, _ := oidc.NewCodeVerifier()
+			authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", verifier)
+			if err != nil {
+				t.Fatalf("AuthCodeURL() error = %v", err)
+			}
+			code, _, err := server.Login(authURL, oidctest.Identity{Subject: "1234"})
+			if err != nil {
+				t.Fatalf("Login() error = %v", err)
+			}
+
+			if err := tt.exchange(t, server, provider, code, verifier); !errors.Is(err, oidc.ErrCodeRejected) {
+				t.Errorf("Exchange() error = %v, want ErrCodeRejected", err)
+			}
+		})
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	server, provider := newProvider(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	// Rotate so there is a retired key that is no longer published.
+	retiredKeyID := server.KeyID()
+	if err := server.RotateKey(); err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(claims jwt.MapClaims, kid string) string {
+		t.Helper()
+		token, err := server.SignIDToken(claims, kid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	valid := func(change func(jwt.MapClaims)) jwt.MapClaims {
+		claims := jwt.MapClaims{"sub": "1234", "nonce": "nonce"}
+		if change != nil {
+			change(claims)
+		}
+		return claims
+	}
+	hmacToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, valid(func(c jwt.MapClaims) {
+		c["iss"], c["aud"], c["exp"], c["iat"] = server.URL, "client", now.Add(time.Minute).Unix(), now.Unix()
+	})).SignedString([]byte("client secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	noneToken, err := jwt.NewWithClaims(jwt.SigningMethodNone, valid(func(c jwt.MapClaims) {
+		c["iss"], c["aud"], c["exp"], c["iat"] = server.URL, "client", now.Add(time.Minute).Unix(), now.Unix()
+	})).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"valid", sign(valid(nil), ""), false},
+		{"several audiences with azp", sign(valid(func(c jwt.MapClaims) { c["aud"], c["azp"] = []string{"client", "other"}, "client" }), ""), false},
+		{"wrong nonce", sign(valid(func(c jwt.MapClaims) { c["nonce"] = "other" }), ""), true},
+		{"no nonce", sign(valid(func(c jwt.MapClaims) { delete(c, "nonce") }), ""), true},
+		{"other audience", sign(valid(func(c jwt.MapClaims) { c["aud"] = "other" }), ""), true},
+		{"several audiences without azp", sign(valid(func(c jwt.MapClaims) { c["aud"] = []string{"client", "other"} }), ""), true},
+		{"several audiences for another party", sign(valid(func(c jwt.MapClaims) { c["aud"], c["azp"] = []string{"client", "other"}, "other" }), ""), true},
+		{"other issuer", sign(valid(func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }), ""), true},
+		{"expired", sign(valid(func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Minute).Unix() }), ""), true},
+		{"issued in the future", sign(valid(func(c jwt.MapClaims) { c["iat"] = now.Add(time.Hour).Unix() }), ""), true},
+		{"no subject", sign(valid(func(c jwt.MapClaims) { delete(c, "sub") }), ""), true},
+		{"retired key", sign(valid(nil), retiredKeyID), true},
+		{"HMAC with the client secret", hmacToken, true},
+		{"unsigned", noneToken, true},
+		{"not a JWT", "not a token", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := provider.VerifyIDToken(ctx, tt.token, "nonce")
+			if tt.wantErr && !errors.Is(err, oidc.ErrInvalidIDToken) {
+				t.Errorf("VerifyIDToken() error = %v, want ErrInvalidIDToken", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("VerifyIDToken() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenKeyRefresh(t *testing.T) {
+	server, provider := newProvider(t)
+	ctx := context.Background()
+
+	if _, err := provider.VerifyIDToken(ctx, login(t, server, provider, oidctest.Identity{Subject: "1234"}, "n"), "n"); err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+	if got := server.JWKSRequests(); got != 1 {
+		t.Fatalf("keys fetched %d times, want 1", got)
+	}
+
+	// Known keys are served from the cache.
+	if _, err := provider.VerifyIDToken(ctx, login(t, server, provider, oidctest.Identity{Subject: "1234"}, "n"), "n"); err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+	if got := server.JWKSRequests(); got != 1 {
+		t.Errorf("keys fetched %d times for a known key, want 1", got)
+	}
+
+	// Unknown key IDs do not trigger a refresh while the keys are fresh, so made up ones cannot make us hammer the provider.
+	if err := server.RotateKey(); err != nil {
+		t.Fatal(err)
+	}
+	_, err := provider.VerifyIDToken(ctx, login(t, server, provider, oidctest.Identity{Subject: "1234"}, "n"), "n")
+	if !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Errorf("VerifyIDToken() with a key published just now: error = %v, want ErrInvalidIDToken", err)
+	}
+	if got := server.JWKSRequests(); got != 1 {
+		t.Errorf("keys fetched %d times within a minute, want 1", got)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	document := `{"issuer":"https://other.example.com","authorization_endpoint":"a","token_endpoint":"t","jwks_uri":"j"}`
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"issuer mismatch", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte(document)) }},
+		{"missing endpoints", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"issuer":"http://` + r.Host + `"}`))
+		}},
+		{"server error", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusInternalServerError) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			provider := oidc.NewProvider(oidc.Config{Name: "test", Issuer: server.URL}, server.Client())
+			if _, err := provider.Metadata(context.Background()); err == nil {
+				t.Error("Metadata() succeeded")
+			}
+		})
+	}
+}
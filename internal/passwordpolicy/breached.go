@@ -0,0 +1,59 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// BreachedList holds SHA-1 hashes of passwords known from data breaches.
+// Lookups happen in memory; no password or hash ever leaves the process.
+type BreachedList struct {
+	hashes [][sha1.Size]byte // sorted
+}
+
+// LoadBreachedList reads a file with one hex encoded SHA-1 hash per line. Anything after
+// a colon is ignored, so the "HASH:COUNT" files published by Have I Been Pwned can be used
+// as they are. Empty lines and lines starting with # are skipped. The whole list is kept
+// in memory (about 20 MB per million hashes), so use a subset of the most common passwords.
+func LoadBreachedList(path string) (*BreachedList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var hashes [][sha1.Size]byte
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text, _, _ = strings.Cut(text, ":")
+
+		var hash [sha1.Size]byte
+		if n, err := hex.Decode(hash[:], []byte(text)); err != nil || n != sha1.Size || len(text) != 2*sha1.Size {
+			return nil, fmt.Errorf("%s:%d: not a SHA-1 hash", path, line)
+		}
+		hashes = append(hashes, hash)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(hashes, func(a, b [sha1.Size]byte) int { return bytes.Compare(a[:], b[:]) })
+	return &BreachedList{hashes: slices.Compact(hashes)}, nil
+}
+
+// Contains reports whether password is on the list.
+func (l *BreachedList) Contains(password string) bool {
+	hash := sha1.Sum([]byte(password))
+	_, found := slices.BinarySearchFunc(l.hashes, hash, func(a, b [sha1.Size]byte) int { return bytes.Compare(a[:], b[:]) })
+	return found
+}
@@ -0,0 +1,144 @@
+package passwordpolicy
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// commonWords are frequent building blocks of human chosen passwords. A password
+// made of one of them with a few characters appended scores as the guess it is.
+var commonWords = []string{
+	"password", "qwerty", "azerty", "letmein", "welcome", "admin", "administrator",
+	"login", "master", "monkey", "dragon", "shadow", "sunshine", "princess", "football",
+	"baseball", "soccer", "hockey", "iloveyou", "trustno", "superman", "batman", "starwars",
+	"freedom", "whatever", "secret", "hello", "charlie", "michael", "jordan", "jennifer",
+	"hunter", "ranger", "buster", "thomas", "robert", "daniel", "andrew", "joshua",
+	"matthew", "summer", "winter", "spring", "autumn", "january", "february", "march",
+	"april", "august", "september", "october", "november", "december", "monday", "friday",
+	"flower", "cookie", "cheese", "pepper", "ginger", "orange", "banana", "chocolate",
+	"computer", "internet", "google", "samsung", "apple", "microsoft", "killer", "pokemon",
+	"naruto", "mustang", "ferrari", "corvette", "harley", "yankees", "lakers", "chelsea",
+	"liverpool", "arsenal", "london", "berlin", "paris", "america", "access", "default",
+	"changeme", "guest", "root", "test", "user", "love", "lovely", "angel", "baby", "family",
+	"forever", "blessed", "jesus", "god", "heaven", "money", "happy", "lucky", "magic",
+	"abc", "qwe", "asd", "zxc", "pass", "temp", "demo", "company",
+}
+
+// keyboardRows are runs of adjacent keys, checked in both directions.
+var keyboardRows = []string{
+	"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm", "qwertzuiop", "yxcvbnm", "azertyuiop",
+}
+
+// leetReplacer undoes common character substitutions before dictionary matching.
+var leetReplacer = strings.NewReplacer(
+	"0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "8", "b", "@", "a", "$", "s", "!", "i",
+)
+
+// Entropy estimates the strength of password in bits. Each character counts for the
+// size of the character classes the password draws from, except characters that are
+// predictable from the previous one (repeats, alphabetical or numeric sequences and
+// keyboard runs) and characters of common words, which count for next to nothing.
+// It is a rough, deliberately conservative estimate, not a substitute for a breach list.
+func Entropy(password string) float64 {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return 0
+	}
+
+	bitsPerChar := math.Log2(float64(poolSize(runes)))
+	predictable := make([]bool, len(runes))
+
+	for i := 1; i < len(runes); i++ {
+		if followsPattern(runes[i-1], runes[i]) {
+			predictable[i] = true
+		}
+	}
+
+	// Mark characters covered by common words. The word choice itself is
+	// worth about log2(len(commonWords)) bits and is credited to its first character.
+	wordBits := math.Log2(float64(len(commonWords)))
+	folded := []rune(leetReplacer.Replace(strings.ToLower(password)))
+	wordStart := make([]bool, len(runes))
+	if len(folded) == len(runes) {
+		for _, word := range commonWords {
+			w := []rune(word)
+			for start := 0; start+len(w) <= len(folded); start++ {
+				if string(folded[start:start+len(w)]) != word {
+					continue
+				}
+				wordStart[start] = true
+				for j := start + 1; j < start+len(w); j++ {
+					predictable[j] = true
+				}
+			}
+		}
+	}
+
+	var bits float64
+	for i := range runes {
+		switch {
+		case wordStart[i]:
+			bits += min(wordBits, bitsPerChar)
+		case predictable[i]:
+			bits++
+		default:
+			bits += bitsPerChar
+		}
+	}
+	return bits
+}
+
+// poolSize returns the number of characters an attacker has to try per position,
+// based on the character classes that occur in the password.
+func poolSize(runes []rune) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII && unicode.IsPrint(r):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	pool := 0
+	if lower {
+		pool += 26
+	}
+	if upper {
+		pool += 26
+	}
+	if digit {
+		pool += 10
+	}
+	if symbol {
+		pool += 33
+	}
+	if other {
+		pool += 100
+	}
+	return pool
+}
+
+// followsPattern reports whether cur is predictable from the character before it:
+// a repeat, the next or previous character as in abc or 987, or a neighbouring key.
+func followsPattern(prev, cur rune) bool {
+	if delta := cur - prev; delta >= -1 && delta <= 1 {
+		return true
+	}
+
+	p, c := unicode.ToLower(prev), unicode.ToLower(cur)
+	for _, row := range keyboardRows {
+		if strings.Contains(row, string([]rune{p, c})) || strings.Contains(row, string([]rune{c, p})) {
+			return true
+		}
+	}
+	return false
+}
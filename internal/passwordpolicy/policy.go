@@ -0,0 +1,81 @@
+// Package passwordpolicy decides whether a password is strong enough to be set on an account.
+package passwordpolicy
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrRejected is matched by every *Error returned by a Policy.
+var ErrRejected = errors.New("password rejected by policy")
+
+// Error explains why a password was rejected. Reason is meant to be shown to the user.
+// It matches ErrRejected with errors.Is.
+type Error struct {
+	Reason string
+}
+
+func (e *Error) Error() string { return "password rejected: " + e.Reason }
+
+func (e *Error) Is(target error) bool { return target == ErrRejected }
+
+// Account holds what a policy may compare a password against.
+type Account struct {
+	Username string
+	Email    string
+}
+
+// Policy checks a new password before it is hashed and stored.
+type Policy interface {
+	// Check returns an *Error if password must not be used for account, or nil if it may.
+	Check(password string, account Account) error
+}
+
+// Config configures the checks a Standard policy runs. Zero values disable a check.
+type Config struct {
+	// MinEntropyBits is the minimum strength estimated by Entropy.
+	MinEntropyBits float64
+	// BreachedHashesFile is a file of SHA-1 hashes of breached passwords, one per line.
+	BreachedHashesFile string
+	// RejectAccountSimilar rejects passwords that resemble the username or the email address.
+	RejectAccountSimilar bool
+}
+
+// Standard is the built-in Policy. Checks run from cheapest to most expensive.
+type Standard struct {
+	minEntropyBits       float64
+	breached             *BreachedList
+	rejectAccountSimilar bool
+}
+
+// New creates a Standard policy, loading the breached password list if one is configured.
+func New(cfg Config) (*Standard, error) {
+	p := &Standard{
+		minEntropyBits:       cfg.MinEntropyBits,
+		rejectAccountSimilar: cfg.RejectAccountSimilar,
+	}
+
+	if cfg.BreachedHashesFile != "" {
+		breached, err := LoadBreachedList(cfg.BreachedHashesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load breached password list: %w", err)
+		}
+		p.breached = breached
+	}
+
+	return p, nil
+}
+
+// Check implements Policy.
+func (p *Standard) Check(password string, account Account) error {
+	if p.rejectAccountSimilar && similarToAccount(password, account) {
+		return &Error{Reason: "password must not resemble the username or email address"}
+	}
+	if p.minEntropyBits > 0 && Entropy(password) < p.minEntropyBits {
+		return &Error{Reason: "password is too easy to guess; use a longer password or avoid common words and patterns"}
+	}
+	if p.breached != nil && p.breached.Contains(password) {
+		return &Error{Reason: "password has appeared in a data breach; choose a different one"}
+	}
+	return nil
+}
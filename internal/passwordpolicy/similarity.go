@@ -0,0 +1,72 @@
+package passwordpolicy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// minSimilarLength is the shortest account detail worth comparing a password against.
+const minSimilarLength = 3
+
+// similarToAccount reports whether password contains, or is a slight variation of,
+// the username or the local part of the email address. Case, digits and punctuation
+// are ignored, so "Alice1990!" resembles the username "alice".
+func similarToAccount(password string, account Account) bool {
+	p := lettersOnly(password)
+	if p == "" {
+		return false
+	}
+
+	localPart, _, _ := strings.Cut(account.Email, "@")
+	for _, detail := range []string{account.Username, localPart} {
+		d := lettersOnly(detail)
+		if len(d) < minSimilarLength {
+			continue
+		}
+		if strings.Contains(p, d) {
+			return true
+		}
+		// A password that is mostly a piece of the detail, e.g. "lice" for "alice".
+		if 2*len(p) >= len(d) && strings.Contains(d, p) {
+			return true
+		}
+		// Allow for a typo or a swapped letter per four characters.
+		if levenshtein(p, d) <= max(1, len(d)/4) {
+			return true
+		}
+	}
+	return false
+}
+
+// lettersOnly lowercases s and drops everything but letters.
+func lettersOnly(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	cur := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		cur[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(br)]
+}
@@ -0,0 +1,44 @@
+package permission
+
+import "slices"
+
+// Permissions that can be granted to roles.
+// Like API key scopes, each permission names a resource and the action allowed on it.
+// Every permission is also seeded into the permissions table by a migration.
+const (
+	UsersRead  = "users:read"
+	UsersWrite = "users:write"
+	RolesRead  = "roles:read"
+	RolesWrite = "roles:write"
+	// UsersImpersonate allows signing in as another user to see what they see.
+	UsersImpersonate = "users:impersonate"
+	// ClientsRead and ClientsWrite cover the OAuth clients machines authenticate as.
+	ClientsRead  = "clients:read"
+	ClientsWrite = "clients:write"
+	// ServiceAccountsRead and ServiceAccountsWrite cover service accounts and their API keys.
+	ServiceAccountsRead  = "service_accounts:read"
+	ServiceAccountsWrite = "service_accounts:write"
+	// ClientCertificatesRead and ClientCertificatesWrite cover the certificates accepted for mutual TLS.
+	ClientCertificatesRead  = "client_certificates:read"
+	ClientCertificatesWrite = "client_certificates:write"
+)
+
+// All lists every permission that can be granted.
+var All = []string{
+	UsersRead,
+	UsersWrite,
+	RolesRead,
+	RolesWrite,
+	UsersImpersonate,
+	ClientsRead,
+	ClientsWrite,
+	ServiceAccountsRead,
+	ServiceAccountsWrite,
+	ClientCertificatesRead,
+	ClientCertificatesWrite,
+}
+
+// IsValid reports whether p is a known permission.
+func IsValid(p string) bool {
+	return slices.Contains(All, p)
+}
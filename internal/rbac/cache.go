@@ -0,0 +1,96 @@
+package rbac
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// permissionCache keeps the permissions of recently seen users so that permission checks
+// do not cost a query per request.
+type permissionCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[uuid.UUID]cacheEntry
+	// gen is bumped on every invalidation. A lookup that started before an invalidation
+	// may have read stale permissions, so its result is only stored if gen is unchanged.
+	gen uint64
+}
+
+type cacheEntry struct {
+	permissions []string
+	expiresAt   time.Time
+}
+
+// newPermissionCache creates a cache that keeps entries for ttl; zero disables caching.
+func newPermissionCache(ttl time.Duration) *permissionCache {
+	return &permissionCache{
+		ttl:     ttl,
+		entries: make(map[uuid.UUID]cacheEntry),
+	}
+}
+
+// get returns the cached permissions of a user, if there are any that have not expired.
+func (c *permissionCache) get(userID uuid.UUID) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[userID]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, userID)
+		return nil, false
+	}
+	return entry.permissions, true
+}
+
+// generation returns the current generation, to be passed to put after loading permissions.
+func (c *permissionCache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// put caches the permissions of a user that were loaded at generation gen.
+func (c *permissionCache) put(userID uuid.UUID, permissions []string, gen uint64) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if gen != c.gen {
+		return
+	}
+	now := time.Now()
+	// Drop expired entries now and then so users that stopped making requests do not pile up.
+	if len(c.entries) >= 1024 {
+		for id, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+	}
+	c.entries[userID] = cacheEntry{permissions: permissions, expiresAt: now.Add(c.ttl)}
+}
+
+// invalidate forgets the cached permissions of a user.
+func (c *permissionCache) invalidate(userID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, userID)
+	c.gen++
+}
+
+// invalidateAll forgets the cached permissions of every user.
+func (c *permissionCache) invalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+	c.gen++
+}
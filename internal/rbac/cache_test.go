@@ -0,0 +1,85 @@
+package rbac
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPermissionCache(t *testing.T) {
+	user, other := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		run  func(c *permissionCache)
+		want []string // Cached permissions of user, nil for a miss
+	}{
+		{"miss", time.Minute, func(c *permissionCache) {}, nil},
+		{"hit", time.Minute, func(c *permissionCache) {
+			c.put(user, []string{"users:read"}, c.generation())
+		}, []string{"users:read"}},
+		{"caching disabled", 0, func(c *permissionCache) {
+			c.put(user, []string{"users:read"}, c.generation())
+		}, nil},
+		{"expired", time.Minute, func(c *permissionCache) {
+			c.put(user, []string{"users:read"}, c.generation())
+			entry := c.entries[user]
+			entry.expiresAt = time.Now().Add(-time.Second)
+			c.entries[user] = entry
+		}, nil},
+		{"invalidated", time.Minute, func(c *permissionCache) {
+			c.put(user, []string{"users:read"}, c.generation())
+			c.invalidate(user)
+		}, nil},
+		{"other user invalidated", time.Minute, func(c *permissionCache) {
+			c.put(user, []string{"users:read"}, c.generation())
+			c.invalidate(other)
+		}, []string{"users:read"}},
+		{"all invalidated", time.Minute, func(c *permissionCache) {
+			c.put(user, []string{"users:read"}, c.generation())
+			c.invalidateAll()
+		}, nil},
+		{"loaded before an invalidation", time.Minute, func(c *permissionCache) {
+			gen := c.generation()
+			c.invalidate(other) // Any invalidation may have changed what was loaded
+			c.put(user, []string{"users:read"}, gen)
+		}, nil},
+		{"loaded after an invalidation", time.Minute, func(c *permissionCache) {
+			c.invalidateAll()
+			c.put(user, []string{"users:read"}, c.generation())
+		}, []string{"users:read"}},
+		{"no permissions", time.Minute, func(c *permissionCache) {
+			c.put(user, []string{}, c.generation())
+		}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newPermissionCache(tt.ttl)
+			tt.run(c)
+
+			got, ok := c.get(user)
+			if ok != (tt.want != nil) || !slices.Equal(got, tt.want) {
+				t.Errorf("get() = %v, %v, want %v", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermissionCacheDropsExpiredEntries(t *testing.T) {
+	c := newPermissionCache(time.Minute)
+	for range 1024 {
+		c.put(uuid.New(), nil, c.generation())
+	}
+	for id, entry := range c.entries {
+		entry.expiresAt = time.Now().Add(-time.Second)
+		c.entries[id] = entry
+	}
+
+	c.put(uuid.New(), nil, c.generation())
+	if len(c.entries) != 1 {
+		t.Errorf("cache holds %d entries, want the expired ones dropped", len(c.entries))
+	}
+}
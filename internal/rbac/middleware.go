@@ -0,0 +1,72 @@
+package rbac
+
+import (
+	"net/http"
+	"slices"
+
+	"go-api-structure/internal/apikey"
+	"go-api-structure/internal/auth"
+	"go-api-structure/internal/clientcert"
+)
+
+// RequirePermission creates a middleware that only lets requests through if the authenticated
+// user holds every one of permissions through their roles. It has to run after JWTMiddleware,
+// APIKeyMiddleware or ClientCertMiddleware: requests without an authenticated user are rejected
+// with 401, and users lacking a permission with 403. For API keys and client certificates, the
+// credential's scopes and its owner's permissions are checked independently, so a credential
+// can never do more than its owner.
+// Service accounts and OAuth clients have no roles; the scopes of their API key, client
+// certificate or token must include every permission instead.
+func (s *Service) RequirePermission(errorFunc func(w http.ResponseWriter, r *http.Request, statusCode int, message any), permissions ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if client := auth.GetClientFromContext(r.Context()); client != nil {
+				claims := auth.GetClaimsFromContext(r.Context())
+				for _, required := range permissions {
+					if claims == nil || !claims.HasScope(required) {
+						errorFunc(w, r, http.StatusForbidden, "missing required permission: "+required)
+						return
+					}
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if account := auth.GetServiceAccountFromContext(r.Context()); account != nil {
+				apiKey := auth.GetAPIKeyFromContext(r.Context())
+				certificate := auth.GetClientCertificateFromContext(r.Context())
+				for _, required := range permissions {
+					granted := apiKey != nil && apikey.HasScope(apiKey, required) ||
+						certificate != nil && clientcert.HasScope(certificate, required)
+					if !granted {
+						errorFunc(w, r, http.StatusForbidden, "missing required permission: "+required)
+						return
+					}
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user := auth.GetUserFromContext(r.Context())
+			if user == nil {
+				errorFunc(w, r, http.StatusUnauthorized, "authentication required")
+				return
+			}
+
+			granted, err := s.userPermissions(r.Context(), user.ID)
+			if err != nil {
+				errorFunc(w, r, http.StatusInternalServerError, "Failed to check permissions")
+				return
+			}
+
+			for _, required := range permissions {
+				if !slices.Contains(granted, required) {
+					errorFunc(w, r, http.StatusForbidden, "missing required permission: "+required)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
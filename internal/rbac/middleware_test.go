@@ -0,0 +1,81 @@
+package rbac
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-api-structure/internal/auth"
+	"go-api-structure/internal/store/db"
+)
+
+func TestRequirePermission(t *testing.T) {
+	st := newFakeStore()
+	s := NewService(st, time.Minute)
+	reader := st.addRole("reader", false, "users:read")
+	userID := st.addUser()
+	if err := s.AssignRole(context.Background(), userID, reader.ID); err != nil {
+		t.Fatal(err)
+	}
+	user := &db.User{ID: userID}
+
+	withUser := func(ctx context.Context) context.Context { return auth.ContextSetUser(ctx, user) }
+	withClient := func(scope string) func(context.Context) context.Context {
+		return func(ctx context.Context) context.Context {
+			ctx = auth.ContextSetClient(ctx, &db.OauthClient{})
+			return auth.ContextSetClaims(ctx, &auth.Claims{TokenType: auth.TokenTypeClientAccess, Scope: scope})
+		}
+	}
+	withServiceAccount := func(scopes ...string) func(context.Context) context.Context {
+		return func(ctx context.Context) context.Context {
+			ctx = auth.ContextSetServiceAccount(ctx, &db.ServiceAccount{})
+			return auth.ContextSetAPIKey(ctx, &db.ApiKey{Scopes: scopes})
+		}
+	}
+
+	withServiceAccountCertificate := func(scopes ...string) func(context.Context) context.Context {
+		return func(ctx context.Context) context.Context {
+			ctx = auth.ContextSetServiceAccount(ctx, &db.ServiceAccount{})
+			return auth.ContextSetClientCertificate(ctx, &db.ClientCertificate{Scopes: scopes})
+		}
+	}
+
+	tests := []struct {
+		name         string
+		permissions  []string
+		authenticate func(context.Context) context.Context
+		wantStatus   int
+	}{
+		{"unauthenticated", []string{"users:read"}, func(ctx context.Context) context.Context { return ctx }, http.StatusUnauthorized},
+		{"granted by role", []string{"users:read"}, withUser, http.StatusOK},
+		{"not granted", []string{"users:write"}, withUser, http.StatusForbidden},
+		{"one of two granted", []string{"users:read", "users:write"}, withUser, http.StatusForbidden},
+		{"client scope granted", []string{"users:read"}, withClient("users:read users:write"), http.StatusOK},
+		{"client scope missing", []string{"users:write"}, withClient("users:read"), http.StatusForbidden},
+		{"client without scopes", []string{"users:read"}, withClient(""), http.StatusForbidden},
+		{"service account scope granted", []string{"users:read"}, withServiceAccount("users:read"), http.StatusOK},
+		{"service account scope missing", []string{"users:write"}, withServiceAccount("users:read"), http.StatusForbidden},
+		{"service account certificate scope granted", []string{"users:read"}, withServiceAccountCertificate("users:read"), http.StatusOK},
+		{"service account certificate scope missing", []string{"users:write"}, withServiceAccountCertificate("users:read"), http.StatusForbidden},
+		{"service account without key", []string{"users:read"}, func(ctx context.Context) context.Context {
+			return auth.ContextSetServiceAccount(ctx, &db.ServiceAccount{})
+		}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := s.RequirePermission(func(w http.ResponseWriter, r *http.Request, status int, message any) {
+				w.WriteHeader(status)
+			}, tt.permissions...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+			r = r.WithContext(tt.authenticate(r.Context()))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
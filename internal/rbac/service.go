@@ -0,0 +1,371 @@
+// Package rbac manages roles, the permissions they grant and the users they are assigned to,
+// and checks the permissions of authenticated users.
+package rbac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+// AdminRole is the name of the built-in role that is seeded with every permission.
+const AdminRole = "admin"
+
+var (
+	ErrRoleNotFound = errors.New("role not found")
+	ErrUserNotFound = errors.New("user not found")
+	ErrRoleExists   = errors.New("a role with this name already exists")
+	ErrBuiltinRole  = errors.New("built-in roles cannot be renamed, deleted or have their permissions changed")
+	ErrLastAdmin    = errors.New("the last user with the admin role cannot lose it")
+)
+
+// Role is a role together with the permissions it grants.
+type Role struct {
+	db.Role
+	Permissions []string
+}
+
+// CreateRoleParams holds the settings for a new role.
+type CreateRoleParams struct {
+	Name        string
+	Description string
+	Permissions []string
+}
+
+// UpdateRoleParams holds the fields of a role that can be changed. Nil fields are left unchanged.
+type UpdateRoleParams struct {
+	Name        *string
+	Description *string
+	Permissions []string
+}
+
+// ServiceInterface defines the operations for managing roles and checking permissions.
+type ServiceInterface interface {
+	ListPermissions(ctx context.Context) ([]db.Permission, error)
+	ListRoles(ctx context.Context) ([]Role, error)
+	GetRole(ctx context.Context, id uuid.UUID) (*Role, error)
+	CreateRole(ctx context.Context, params CreateRoleParams) (*Role, error)
+	UpdateRole(ctx context.Context, id uuid.UUID, params UpdateRoleParams) (*Role, error)
+	DeleteRole(ctx context.Context, id uuid.UUID) error
+	ListUserRoles(ctx context.Context, userID uuid.UUID) ([]db.Role, error)
+	AssignRole(ctx context.Context, userID, roleID uuid.UUID) error
+	// UnassignRole takes a role away from a user. It refuses to take the admin role from its last holder.
+	UnassignRole(ctx context.Context, userID, roleID uuid.UUID) error
+	// CheckNotLastAdmin returns ErrLastAdmin if the user is the only one with the admin role,
+	// before they are suspended or deleted.
+	CheckNotLastAdmin(ctx context.Context, userID uuid.UUID) error
+	// HasPermissions reports whether the user holds every one of permissions through their roles.
+	HasPermissions(ctx context.Context, userID uuid.UUID, permissions ...string) (bool, error)
+	// UserPermissions returns every permission the user holds through their roles.
+	UserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error)
+}
+
+// Service provides role management and permission checks.
+// The permissions of each user are cached for the configured TTL; changes made through
+// the Service take effect immediately, changes made elsewhere once the cache entry expires.
+type Service struct {
+	roleStore store.RoleStore
+	userStore store.UserStore
+	cache     *permissionCache
+}
+
+// NewService creates a new rbac Service.
+// cacheTTL is how long a user's permissions are cached; zero disables caching.
+func NewService(store store.Store, cacheTTL time.Duration) *Service {
+	return &Service{
+		roleStore: store,
+		userStore: store,
+		cache:     newPermissionCache(cacheTTL),
+	}
+}
+
+// ListPermissions returns every permission that can be granted to a role.
+func (s *Service) ListPermissions(ctx context.Context) ([]db.Permission, error) {
+	permissions, err := s.roleStore.ListPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+// ListRoles returns all roles with their permissions.
+func (s *Service) ListRoles(ctx context.Context) ([]Role, error) {
+	roles, err := s.roleStore.ListRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	grants, err := s.roleStore.ListAllRolePermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role permissions: %w", err)
+	}
+
+	permissions := make(map[uuid.UUID][]string, len(roles))
+	for _, grant := range grants {
+		permissions[grant.RoleID] = append(permissions[grant.RoleID], grant.Permission)
+	}
+
+	result := make([]Role, 0, len(roles))
+	for _, role := range roles {
+		result = append(result, Role{Role: role, Permissions: permissions[role.ID]})
+	}
+	return result, nil
+}
+
+// GetRole returns a role with its permissions.
+func (s *Service) GetRole(ctx context.Context, id uuid.UUID) (*Role, error) {
+	role, err := s.roleStore.GetRoleByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return s.withPermissions(ctx, role)
+}
+
+// CreateRole creates a role granting the given permissions.
+func (s *Service) CreateRole(ctx context.Context, params CreateRoleParams) (*Role, error) {
+	role, err := s.roleStore.CreateRole(ctx, db.CreateRoleParams{
+		Name:        params.Name,
+		Description: params.Description,
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrConflict) {
+			return nil, ErrRoleExists
+		}
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+
+	if len(params.Permissions) > 0 {
+		if err := s.setPermissions(ctx, role.ID, params.Permissions); err != nil {
+			return nil, err
+		}
+	}
+	return s.withPermissions(ctx, role)
+}
+
+// UpdateRole renames a role, changes its description and/or replaces its permissions.
+// Built-in roles only allow changing the description.
+func (s *Service) UpdateRole(ctx context.Context, id uuid.UUID, params UpdateRoleParams) (*Role, error) {
+	role, err := s.roleStore.GetRoleByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	if role.Builtin && (params.Name != nil && *params.Name != role.Name || params.Permissions != nil) {
+		return nil, ErrBuiltinRole
+	}
+
+	if params.Name != nil || params.Description != nil {
+		updateParams := db.UpdateRoleParams{ID: id}
+		if params.Name != nil {
+			updateParams.Name = pgtype.Text{String: *params.Name, Valid: true}
+		}
+		if params.Description != nil {
+			updateParams.Description = pgtype.Text{String: *params.Description, Valid: true}
+		}
+		role, err = s.roleStore.UpdateRole(ctx, updateParams)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				return nil, ErrRoleNotFound
+			case errors.Is(err, store.ErrConflict):
+				return nil, ErrRoleExists
+			default:
+				return nil, fmt.Errorf("failed to update role: %w", err)
+			}
+		}
+	}
+
+	if params.Permissions != nil {
+		if err := s.setPermissions(ctx, id, params.Permissions); err != nil {
+			return nil, err
+		}
+	}
+	return s.withPermissions(ctx, role)
+}
+
+// DeleteRole deletes a role, taking it away from every user that has it.
+func (s *Service) DeleteRole(ctx context.Context, id uuid.UUID) error {
+	role, err := s.roleStore.GetRoleByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return ErrRoleNotFound
+		}
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+	if role.Builtin {
+		return ErrBuiltinRole
+	}
+
+	if _, err := s.roleStore.DeleteRole(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+	s.cache.invalidateAll()
+	return nil
+}
+
+// ListUserRoles returns the roles assigned to a user.
+func (s *Service) ListUserRoles(ctx context.Context, userID uuid.UUID) ([]db.Role, error) {
+	if err := s.checkUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+	roles, err := s.roleStore.ListUserRoles(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user roles: %w", err)
+	}
+	return roles, nil
+}
+
+// AssignRole gives a user a role. Assigning a role the user already has is not an error.
+func (s *Service) AssignRole(ctx context.Context, userID, roleID uuid.UUID) error {
+	if err := s.checkUserExists(ctx, userID); err != nil {
+		return err
+	}
+	if _, err := s.roleStore.GetRoleByID(ctx, roleID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return ErrRoleNotFound
+		}
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+
+	if err := s.roleStore.AssignUserRole(ctx, db.AssignUserRoleParams{UserID: userID, RoleID: roleID}); err != nil {
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+	s.cache.invalidate(userID)
+	return nil
+}
+
+// UnassignRole takes a role away from a user. It returns ErrRoleNotFound if the user does not have the role.
+func (s *Service) UnassignRole(ctx context.Context, userID, roleID uuid.UUID) error {
+	role, err := s.roleStore.GetRoleByID(ctx, roleID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return ErrRoleNotFound
+		}
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+
+	if role.Name == AdminRole && role.Builtin {
+		if err := s.CheckNotLastAdmin(ctx, userID); err != nil {
+			return err
+		}
+	}
+
+	removed, err := s.roleStore.UnassignUserRole(ctx, db.UnassignUserRoleParams{UserID: userID, RoleID: roleID})
+	if err != nil {
+		return fmt.Errorf("failed to unassign role: %w", err)
+	}
+	if removed == 0 {
+		return ErrRoleNotFound
+	}
+	s.cache.invalidate(userID)
+	return nil
+}
+
+// CheckNotLastAdmin returns ErrLastAdmin if the user is the only one with the admin role.
+// Without an admin nobody could manage roles anymore, short of editing the database.
+func (s *Service) CheckNotLastAdmin(ctx context.Context, userID uuid.UUID) error {
+	admin, err := s.roleStore.GetRoleByName(ctx, AdminRole)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get admin role: %w", err)
+	}
+
+	holders, err := s.roleStore.CountRoleUsers(ctx, admin.ID)
+	if err != nil {
+		return fmt.Errorf("failed to count role users: %w", err)
+	}
+	if holders > 1 {
+		return nil
+	}
+
+	roles, err := s.roleStore.ListUserRoles(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to list user roles: %w", err)
+	}
+	if slices.ContainsFunc(roles, func(r db.Role) bool { return r.ID == admin.ID }) {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// HasPermissions reports whether the user holds every one of permissions through their roles.
+func (s *Service) HasPermissions(ctx context.Context, userID uuid.UUID, permissions ...string) (bool, error) {
+	granted, err := s.userPermissions(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range permissions {
+		if !slices.Contains(granted, p) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// UserPermissions returns every permission the user holds through their roles.
+func (s *Service) UserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	return s.userPermissions(ctx, userID)
+}
+
+// userPermissions returns the permissions the user holds, from the cache if possible.
+func (s *Service) userPermissions(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	if permissions, ok := s.cache.get(userID); ok {
+		return permissions, nil
+	}
+
+	generation := s.cache.generation()
+	permissions, err := s.roleStore.ListUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user permissions: %w", err)
+	}
+	s.cache.put(userID, permissions, generation)
+	return permissions, nil
+}
+
+// setPermissions replaces the permissions a role grants. As any number of users may
+// hold the role, the whole cache is dropped.
+func (s *Service) setPermissions(ctx context.Context, roleID uuid.UUID, permissions []string) error {
+	err := s.roleStore.SetRolePermissions(ctx, db.SetRolePermissionsParams{
+		RoleID:      roleID,
+		Permissions: permissions,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set role permissions: %w", err)
+	}
+	s.cache.invalidateAll()
+	return nil
+}
+
+// withPermissions loads the permissions a role grants.
+func (s *Service) withPermissions(ctx context.Context, role db.Role) (*Role, error) {
+	permissions, err := s.roleStore.ListRolePermissions(ctx, role.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role permissions: %w", err)
+	}
+	return &Role{Role: role, Permissions: permissions}, nil
+}
+
+// checkUserExists returns ErrUserNotFound if there is no user with the given ID.
+func (s *Service) checkUserExists(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.userStore.GetUserByID(ctx, userID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	return nil
+}
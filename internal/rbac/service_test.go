@@ -0,0 +1,336 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+// fakeStore keeps roles, their permissions and assignments in memory.
+// Calling any other method panics on the nil embedded Store.
+type fakeStore struct {
+	store.Store
+
+	mu                sync.Mutex
+	users             map[uuid.UUID]bool
+	roles             map[uuid.UUID]db.Role
+	grants            map[uuid.UUID][]string    // Permissions by role
+	assignments       map[uuid.UUID][]uuid.UUID // Roles by user
+	permissionQueries int
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		users:       make(map[uuid.UUID]bool),
+		roles:       make(map[uuid.UUID]db.Role),
+		grants:      make(map[uuid.UUID][]string),
+		assignments: make(map[uuid.UUID][]uuid.UUID),
+	}
+}
+
+func (f *fakeStore) addUser() uuid.UUID {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := uuid.New()
+	f.users[id] = true
+	return id
+}
+
+func (f *fakeStore) addRole(name string, builtin bool, permissions ...string) db.Role {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	role := db.Role{ID: uuid.New(), Name: name, Builtin: builtin}
+	f.roles[role.ID] = role
+	f.grants[role.ID] = permissions
+	return role
+}
+
+func (f *fakeStore) queries() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.permissionQueries
+}
+
+func (f *fakeStore) GetUserByID(ctx context.Context, id uuid.UUID) (db.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.users[id] {
+		return db.User{}, store.ErrNotFound
+	}
+	return db.User{ID: id}, nil
+}
+
+func (f *fakeStore) GetRoleByID(ctx context.Context, id uuid.UUID) (db.Role, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	role, ok := f.roles[id]
+	if !ok {
+		return db.Role{}, store.ErrNotFound
+	}
+	return role, nil
+}
+
+func (f *fakeStore) GetRoleByName(ctx context.Context, name string) (db.Role, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, role := range f.roles {
+		if role.Name == name {
+			return role, nil
+		}
+	}
+	return db.Role{}, store.ErrNotFound
+}
+
+func (f *fakeStore) ListRolePermissions(ctx context.Context, roleID uuid.UUID) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.grants[roleID]), nil
+}
+
+func (f *fakeStore) SetRolePermissions(ctx context.Context, arg db.SetRolePermissionsParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.grants[arg.RoleID] = slices.Clone(arg.Permissions)
+	return nil
+}
+
+func (f *fakeStore) DeleteRole(ctx context.Context, id uuid.UUID) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.roles[id]; !ok {
+		return 0, nil
+	}
+	delete(f.roles, id)
+	delete(f.grants, id)
+	for user, roles := range f.assignments {
+		f.assignments[user] = slices.DeleteFunc(roles, func(r uuid.UUID) bool { return r == id })
+	}
+	return 1, nil
+}
+
+func (f *fakeStore) ListUserRoles(ctx context.Context, userID uuid.UUID) ([]db.Role, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var roles []db.Role
+	for _, id := range f.assignments[userID] {
+		roles = append(roles, f.roles[id])
+	}
+	return roles, nil
+}
+
+func (f *fakeStore) ListUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.permissionQueries++
+	permissions := []string{}
+	for _, id := range f.assignments[userID] {
+		for _, p := range f.grants[id] {
+			if !slices.Contains(permissions, p) {
+				permissions = append(permissions, p)
+			}
+		}
+	}
+	sort.Strings(permissions)
+	return permissions, nil
+}
+
+func (f *fakeStore) AssignUserRole(ctx context.Context, arg db.AssignUserRoleParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !slices.Contains(f.assignments[arg.UserID], arg.RoleID) {
+		f.assignments[arg.UserID] = append(f.assignments[arg.UserID], arg.RoleID)
+	}
+	return nil
+}
+
+func (f *fakeStore) UnassignUserRole(ctx context.Context, arg db.UnassignUserRoleParams) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	roles := f.assignments[arg.UserID]
+	i := slices.Index(roles, arg.RoleID)
+	if i < 0 {
+		return 0, nil
+	}
+	f.assignments[arg.UserID] = slices.Delete(roles, i, i+1)
+	return 1, nil
+}
+
+func (f *fakeStore) CountRoleUsers(ctx context.Context, roleID uuid.UUID) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var n int64
+	for _, roles := range f.assignments {
+		if slices.Contains(roles, roleID) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func TestUserPermissionsCached(t *testing.T) {
+	ctx := context.Background()
+	st := newFakeStore()
+	s := NewService(st, time.Minute)
+	user := st.addUser()
+	editor := st.addRole("editor", false, "users:read", "users:write")
+	if err := s.AssignRole(ctx, user, editor.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 3 {
+		ok, err := s.HasPermissions(ctx, user, "users:read", "users:write")
+		if err != nil || !ok {
+			t.Fatalf("HasPermissions() = %v, %v, want true", ok, err)
+		}
+	}
+	if got := st.queries(); got != 1 {
+		t.Errorf("permissions loaded %d times, want once", got)
+	}
+
+	uncached := NewService(st, 0)
+	for range 3 {
+		if _, err := uncached.userPermissions(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := st.queries(); got != 4 {
+		t.Errorf("permissions loaded %d times with caching disabled, want every time", got-1)
+	}
+}
+
+func TestPermissionChangesTakeEffect(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		change func(t *testing.T, s *Service, st *fakeStore, user uuid.UUID, role db.Role)
+		want   bool // Whether the user holds users:write afterwards
+	}{
+		{"role assigned", func(t *testing.T, s *Service, st *fakeStore, user uuid.UUID, _ db.Role) {
+			writer := st.addRole("writer", false, "users:write")
+			if err := s.AssignRole(ctx, user, writer.ID); err != nil {
+				t.Fatal(err)
+			}
+		}, true},
+		{"role unassigned", func(t *testing.T, s *Service, st *fakeStore, user uuid.UUID, role db.Role) {
+			st.SetRolePermissions(ctx, db.SetRolePermissionsParams{RoleID: role.ID, Permissions: []string{"users:read", "users:write"}})
+			s.cache.invalidateAll()
+			if ok, _ := s.HasPermissions(ctx, user, "users:write"); !ok {
+				t.Fatal("setup: user lacks users:write")
+			}
+			if err := s.UnassignRole(ctx, user, role.ID); err != nil {
+				t.Fatal(err)
+			}
+		}, false},
+		{"permissions of a role replaced", func(t *testing.T, s *Service, _ *fakeStore, _ uuid.UUID, role db.Role) {
+			if _, err := s.UpdateRole(ctx, role.ID, UpdateRoleParams{Permissions: []string{"users:read", "users:write"}}); err != nil {
+				t.Fatal(err)
+			}
+		}, true},
+		{"role deleted", func(t *testing.T, s *Service, st *fakeStore, user uuid.UUID, _ db.Role) {
+			writer := st.addRole("writer", false, "users:write")
+			if err := s.AssignRole(ctx, user, writer.ID); err != nil {
+				t.Fatal(err)
+			}
+			if ok, _ := s.HasPermissions(ctx, user, "users:write"); !ok {
+				t.Fatal("setup: user lacks users:write")
+			}
+			if err := s.DeleteRole(ctx, writer.ID); err != nil {
+				t.Fatal(err)
+			}
+		}, false},
+		{"changed behind the service's back", func(t *testing.T, _ *Service, st *fakeStore, _ uuid.UUID, role db.Role) {
+			st.SetRolePermissions(ctx, db.SetRolePermissionsParams{RoleID: role.ID, Permissions: []string{"users:read", "users:write"}})
+		}, false}, // Until the cache entry expires
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := newFakeStore()
+			s := NewService(st, time.Minute)
+			user := st.addUser()
+			reader := st.addRole("reader", false, "users:read")
+			if err := s.AssignRole(ctx, user, reader.ID); err != nil {
+				t.Fatal(err)
+			}
+			// Warm the cache.
+			if ok, err := s.HasPermissions(ctx, user, "users:read"); err != nil || !ok {
+				t.Fatalf("HasPermissions() = %v, %v", ok, err)
+			}
+
+			tt.change(t, s, st, user, reader)
+
+			ok, err := s.HasPermissions(ctx, user, "users:write")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.want {
+				t.Errorf("HasPermissions(users:write) = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuiltinRoles(t *testing.T) {
+	ctx := context.Background()
+	st := newFakeStore()
+	s := NewService(st, time.Minute)
+	admin := st.addRole(AdminRole, true, "users:read", "users:write")
+	first, second := st.addUser(), st.addUser()
+	if err := s.AssignRole(ctx, first, admin.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	rename := "root"
+	if _, err := s.UpdateRole(ctx, admin.ID, UpdateRoleParams{Name: &rename}); !errors.Is(err, ErrBuiltinRole) {
+		t.Errorf("renaming the admin role: error = %v, want ErrBuiltinRole", err)
+	}
+	if _, err := s.UpdateRole(ctx, admin.ID, UpdateRoleParams{Permissions: []string{}}); !errors.Is(err, ErrBuiltinRole) {
+		t.Errorf("changing the admin role's permissions: error = %v, want ErrBuiltinRole", err)
+	}
+	if err := s.DeleteRole(ctx, admin.ID); !errors.Is(err, ErrBuiltinRole) {
+		t.Errorf("deleting the admin role: error = %v, want ErrBuiltinRole", err)
+	}
+
+	if err := s.UnassignRole(ctx, first, admin.ID); !errors.Is(err, ErrLastAdmin) {
+		t.Errorf("unassigning the last admin: error = %v, want ErrLastAdmin", err)
+	}
+	if err := s.AssignRole(ctx, second, admin.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UnassignRole(ctx, first, admin.ID); err != nil {
+		t.Errorf("unassigning one of two admins: error = %v", err)
+	}
+	if err := s.CheckNotLastAdmin(ctx, second); !errors.Is(err, ErrLastAdmin) {
+		t.Errorf("CheckNotLastAdmin() = %v, want ErrLastAdmin", err)
+	}
+	if err := s.CheckNotLastAdmin(ctx, first); err != nil {
+		t.Errorf("CheckNotLastAdmin() for a non-admin = %v", err)
+	}
+}
+
+func TestAssignRoleErrors(t *testing.T) {
+	ctx := context.Background()
+	st := newFakeStore()
+	s := NewService(st, time.Minute)
+	user := st.addUser()
+	role := st.addRole("reader", false, "users:read")
+
+	if err := s.AssignRole(ctx, uuid.New(), role.ID); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("AssignRole() to an unknown user: error = %v, want ErrUserNotFound", err)
+	}
+	if err := s.AssignRole(ctx, user, uuid.New()); !errors.Is(err, ErrRoleNotFound) {
+		t.Errorf("AssignRole() of an unknown role: error = %v, want ErrRoleNotFound", err)
+	}
+	if err := s.UnassignRole(ctx, user, role.ID); !errors.Is(err, ErrRoleNotFound) {
+		t.Errorf("UnassignRole() of a role the user lacks: error = %v, want ErrRoleNotFound", err)
+	}
+}
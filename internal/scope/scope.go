@@ -0,0 +1,19 @@
+package scope
+
+import "slices"
+
+// Scopes that can be granted to API keys.
+// Each scope names a resource and the action allowed on it.
+const (
+	UsersRead = "users:read"
+)
+
+// All lists every scope that can be granted.
+var All = []string{
+	UsersRead,
+}
+
+// IsValid reports whether s is a known scope.
+func IsValid(s string) bool {
+	return slices.Contains(All, s)
+}
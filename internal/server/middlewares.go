@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"go-api-structure/internal/api"
+	"go-api-structure/internal/auth"
 	"log/slog"
 	"net/http"
 	"time"
@@ -39,13 +40,21 @@ func createSlogMiddleware(logger *slog.Logger) func(next http.Handler) http.Hand
 	}
 }
 
-func createCorsMiddleware() func(next http.Handler) http.Handler {
-	return cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"https://*", "http://*"}, // Allow all for now, tighten in production
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: true,
+// createCorsMiddleware lets browsers on allowedOrigins call the API with credentials, so that
+// cookie sessions work from those front ends. Without allowed origins, any origin may call the API,
+// but only with tokens in the Authorization header: browsers will not send or accept cookies.
+func createCorsMiddleware(allowedOrigins []string) func(next http.Handler) http.Handler {
+	options := cors.Options{
+		AllowedOrigins:   []string{"*"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", auth.CSRFHeader},
+		ExposedHeaders:   []string{"Link", "Retry-After", auth.ImpersonatedByHeader},
+		AllowCredentials: false,
 		MaxAge:           300, // Maximum value not ignored by any major browsers
-	})
+	}
+	if len(allowedOrigins) > 0 {
+		options.AllowedOrigins = allowedOrigins
+		options.AllowCredentials = true
+	}
+	return cors.Handler(options)
 }
@@ -2,6 +2,8 @@ package server
 
 import (
 	"go-api-structure/internal/api"
+	"go-api-structure/internal/permission"
+	"go-api-structure/internal/scope"
 
 	"github.com/go-chi/chi/v5"
 	httpSwagger "github.com/swaggo/http-swagger" // Swagger UI handler
@@ -12,6 +14,12 @@ func (s *Server) addRoutes() {
 	// Health check endpoint
 	s.router.Get("/health", s.handleHealthCheck())
 
+	// Public keys for verifying access tokens
+	s.router.Get("/.well-known/jwks.json", s.authHandler.GetJWKS)
+
+	// OAuth 2.0 endpoints for registered clients (e.g., /oauth/token)
+	s.router.Route("/oauth", s.oauthRoutes)
+
 	// Swagger UI endpoint
 	s.router.Get("/swagger/*", httpSwagger.WrapHandler)
 
@@ -19,17 +27,48 @@ func (s *Server) addRoutes() {
 	s.router.Route("/api/v1", s.apiRoutes)
 }
 
+func (s *Server) oauthRoutes(r chi.Router) {
+	r.Post("/token", s.oauthHandler.Token)
+	r.Post("/introspect", s.oauthHandler.Introspect)
+	r.Post("/revoke", s.oauthHandler.Revoke)
+}
+
 func (s *Server) apiRoutes(r chi.Router) {
 	// Authentication routes (e.g., /api/v1/auth/register, /api/v1/auth/login)
 	r.Route("/auth", s.apiAuthRoutes)
 
 	// User routes (e.g., /api/v1/users/me)
 	r.Route("/users", s.apiUserRoutes)
+
+	// Service account routes (e.g., /api/v1/service-accounts/me)
+	r.Route("/service-accounts", s.apiServiceAccountRoutes)
+
+	// Administration routes (e.g., /api/v1/admin/roles), each guarded by a permission
+	r.Route("/admin", s.apiAdminRoutes)
 }
 
 func (s *Server) apiAuthRoutes(r chi.Router) {
 	r.Post("/register", s.authHandler.RegisterUser)
 	r.Post("/login", s.authHandler.LoginUser)
+	r.Post("/refresh", s.authHandler.RefreshToken)
+	r.Post("/mfa/verify", s.authHandler.VerifyMFA)
+	r.Post("/verify-email", s.authHandler.VerifyEmail)
+	r.Post("/verify-email/resend", s.authHandler.ResendVerificationEmail)
+	r.Post("/password/forgot", s.authHandler.ForgotPassword)
+	r.Post("/password/reset", s.authHandler.ResetPassword)
+	r.Post("/email-change/confirm", s.authHandler.ConfirmEmailChange)
+	r.Post("/email-change/cancel", s.authHandler.CancelEmailChange)
+
+	// Sign in through OpenID Connect providers (e.g., /api/v1/auth/oidc/google/authorize)
+	r.Get("/oidc/providers", s.authHandler.ListOIDCProviders)
+	r.Post("/oidc/{provider}/authorize", s.authHandler.StartOIDCLogin)
+	r.Post("/oidc/{provider}/callback", s.authHandler.CompleteOIDCLogin)
+
+	// Protected routes - require JWT authentication
+	r.Group(func(r chi.Router) {
+		r.Use(s.authService.JWTMiddleware(api.ErrorResponse))
+		r.Post("/logout", s.authHandler.LogoutUser)
+	})
 }
 
 func (s *Server) apiUserRoutes(r chi.Router) {
@@ -37,11 +76,101 @@ func (s *Server) apiUserRoutes(r chi.Router) {
 	r.Group(func(r chi.Router) {
 		r.Use(s.authService.JWTMiddleware(api.ErrorResponse))
 		r.Get("/me", s.userHandler.GetMe)
+		r.Patch("/me", s.userHandler.UpdateMe)
+		r.Put("/me/password", s.authHandler.ChangePassword)
+		r.Post("/me/email", s.authHandler.RequestEmailChange)
+		r.Get("/me/sessions", s.authHandler.ListSessions)
+		r.Delete("/me/sessions/{id}", s.authHandler.DeleteSession)
+		r.Post("/me/sessions/revoke-all", s.authHandler.RevokeAllSessions)
+
+		// Two-factor authentication
+		r.Post("/me/mfa/totp", s.authHandler.EnrollTOTP)
+		r.Post("/me/mfa/totp/confirm", s.authHandler.ConfirmTOTP)
+		r.Post("/me/mfa/totp/disable", s.authHandler.DisableTOTP)
+
+		// API key management (e.g., /api/v1/users/me/api-keys/{id})
+		r.Get("/me/api-keys", s.apiKeyHandler.ListAPIKeys)
+		r.Post("/me/api-keys", s.apiKeyHandler.CreateAPIKey)
+		r.Get("/me/api-keys/{id}", s.apiKeyHandler.GetAPIKey)
+		r.Patch("/me/api-keys/{id}", s.apiKeyHandler.UpdateAPIKey)
+		r.Delete("/me/api-keys/{id}", s.apiKeyHandler.RevokeAPIKey)
+		r.Post("/me/api-keys/{id}/rotate", s.apiKeyHandler.RotateAPIKey)
+		r.Post("/me/api-key/rotate", s.apiKeyHandler.RotateDefaultAPIKey)
 	})
 
-	// Route protected by API Key
+	// Route protected by API Key, or client certificate on the TLS listener
 	r.Group(func(r chi.Router) {
-		r.Use(s.authService.APIKeyMiddleware(api.ErrorResponse))
+		r.Use(s.authService.APIKeyOrClientCertMiddleware(api.ErrorResponse, scope.UsersRead))
+		r.Use(s.rbacService.RequirePermission(api.ErrorResponse, permission.UsersRead))
 		r.Get("/{id}", s.userHandler.GetUser) // GET /api/v1/users/{id}
 	})
 }
+
+func (s *Server) apiServiceAccountRoutes(r chi.Router) {
+	// Route protected by API Key, or client certificate on the TLS listener
+	r.Group(func(r chi.Router) {
+		r.Use(s.authService.APIKeyOrClientCertMiddleware(api.ErrorResponse))
+		r.Get("/me", s.serviceAccountHandler.GetMe)
+	})
+}
+
+func (s *Server) apiAdminRoutes(r chi.Router) {
+	r.Use(s.authService.JWTMiddleware(api.ErrorResponse))
+
+	canReadRoles := s.rbacService.RequirePermission(api.ErrorResponse, permission.RolesRead)
+	canWriteRoles := s.rbacService.RequirePermission(api.ErrorResponse, permission.RolesWrite)
+	canReadUsers := s.rbacService.RequirePermission(api.ErrorResponse, permission.UsersRead)
+	canWriteUsers := s.rbacService.RequirePermission(api.ErrorResponse, permission.UsersWrite)
+	canImpersonateUsers := s.rbacService.RequirePermission(api.ErrorResponse, permission.UsersImpersonate)
+	canReadClients := s.rbacService.RequirePermission(api.ErrorResponse, permission.ClientsRead)
+	canWriteClients := s.rbacService.RequirePermission(api.ErrorResponse, permission.ClientsWrite)
+	canReadServiceAccounts := s.rbacService.RequirePermission(api.ErrorResponse, permission.ServiceAccountsRead)
+	canWriteServiceAccounts := s.rbacService.RequirePermission(api.ErrorResponse, permission.ServiceAccountsWrite)
+	canReadClientCerts := s.rbacService.RequirePermission(api.ErrorResponse, permission.ClientCertificatesRead)
+	canWriteClientCerts := s.rbacService.RequirePermission(api.ErrorResponse, permission.ClientCertificatesWrite)
+
+	// User management
+	r.With(canReadUsers).Get("/users", s.adminUserHandler.ListUsers)
+	r.With(canReadUsers).Get("/users/{id}", s.adminUserHandler.GetUser)
+	r.With(canWriteUsers).Delete("/users/{id}", s.adminUserHandler.DeleteUser)
+	r.With(canWriteUsers).Post("/users/{id}/suspend", s.adminUserHandler.SuspendUser)
+	r.With(canWriteUsers).Post("/users/{id}/unsuspend", s.adminUserHandler.UnsuspendUser)
+	r.With(canWriteUsers).Post("/users/{id}/password-reset", s.adminUserHandler.RequirePasswordReset)
+	r.With(canWriteUsers).Post("/users/{id}/unlock", s.adminUserHandler.UnlockUser)
+	r.With(canWriteUsers).Post("/users/{id}/api-keys/{keyID}/regenerate", s.adminUserHandler.RegenerateAPIKey)
+	r.With(canImpersonateUsers).Post("/users/{id}/impersonate", s.adminUserHandler.ImpersonateUser)
+
+	// Role management
+	r.With(canReadRoles).Get("/permissions", s.roleHandler.ListPermissions)
+	r.With(canReadRoles).Get("/roles", s.roleHandler.ListRoles)
+	r.With(canWriteRoles).Post("/roles", s.roleHandler.CreateRole)
+	r.With(canReadRoles).Get("/roles/{id}", s.roleHandler.GetRole)
+	r.With(canWriteRoles).Patch("/roles/{id}", s.roleHandler.UpdateRole)
+	r.With(canWriteRoles).Delete("/roles/{id}", s.roleHandler.DeleteRole)
+	r.With(canReadRoles).Get("/users/{id}/roles", s.roleHandler.ListUserRoles)
+	r.With(canWriteRoles).Put("/users/{id}/roles/{roleID}", s.roleHandler.AssignRole)
+	r.With(canWriteRoles).Delete("/users/{id}/roles/{roleID}", s.roleHandler.UnassignRole)
+
+	// OAuth client management
+	r.With(canReadClients).Get("/oauth-clients", s.oauthClientHandler.ListOAuthClients)
+	r.With(canWriteClients).Post("/oauth-clients", s.oauthClientHandler.CreateOAuthClient)
+	r.With(canReadClients).Get("/oauth-clients/{id}", s.oauthClientHandler.GetOAuthClient)
+	r.With(canWriteClients).Patch("/oauth-clients/{id}", s.oauthClientHandler.UpdateOAuthClient)
+	r.With(canWriteClients).Delete("/oauth-clients/{id}", s.oauthClientHandler.RevokeOAuthClient)
+	r.With(canWriteClients).Post("/oauth-clients/{id}/secret", s.oauthClientHandler.RotateOAuthClientSecret)
+
+	// Service account management
+	r.With(canReadServiceAccounts).Get("/service-accounts", s.serviceAccountHandler.ListServiceAccounts)
+	r.With(canWriteServiceAccounts).Post("/service-accounts", s.serviceAccountHandler.CreateServiceAccount)
+	r.With(canReadServiceAccounts).Get("/service-accounts/{id}", s.serviceAccountHandler.GetServiceAccount)
+	r.With(canWriteServiceAccounts).Patch("/service-accounts/{id}", s.serviceAccountHandler.UpdateServiceAccount)
+	r.With(canWriteServiceAccounts).Delete("/service-accounts/{id}", s.serviceAccountHandler.DeleteServiceAccount)
+	r.With(canWriteServiceAccounts).Post("/service-accounts/{id}/api-keys", s.serviceAccountHandler.CreateServiceAccountAPIKey)
+	r.With(canWriteServiceAccounts).Delete("/service-accounts/{id}/api-keys/{keyID}", s.serviceAccountHandler.RevokeServiceAccountAPIKey)
+
+	// Client certificates accepted for mutual TLS
+	r.With(canReadClientCerts).Get("/client-certificates", s.clientCertHandler.ListClientCertificates)
+	r.With(canWriteClientCerts).Post("/client-certificates", s.clientCertHandler.RegisterClientCertificate)
+	r.With(canReadClientCerts).Get("/client-certificates/{id}", s.clientCertHandler.GetClientCertificate)
+	r.With(canWriteClientCerts).Delete("/client-certificates/{id}", s.clientCertHandler.DeleteClientCertificate)
+}
@@ -3,12 +3,23 @@ package server
 import (
 	"log/slog"
 	"net/http"
+	"time"
 
 	_ "go-api-structure/internal/docs" // Import for swagger docs generation
 
+	"go-api-structure/internal/admin"
 	"go-api-structure/internal/api"
+	"go-api-structure/internal/apikey"
+	"go-api-structure/internal/audit"
 	"go-api-structure/internal/auth"
+	"go-api-structure/internal/clientcert"
 	"go-api-structure/internal/config"
+	"go-api-structure/internal/mailer"
+	"go-api-structure/internal/oauthclient"
+	"go-api-structure/internal/oidc"
+	"go-api-structure/internal/passwordpolicy"
+	"go-api-structure/internal/rbac"
+	"go-api-structure/internal/serviceaccount"
 	"go-api-structure/internal/store"
 	"go-api-structure/internal/user" // Added for UserService
 
@@ -20,26 +31,49 @@ import (
 // This typically includes the application configuration, logger,
 // data stores, and the router itself.
 type Server struct {
-	config      *config.Config
-	logger      *slog.Logger
-	store       store.Store
-	router      *chi.Mux
-	authService *auth.AuthService
-	userService user.ServiceInterface // Added UserService
-	authHandler *api.AuthHandler
-	userHandler *api.UserHandler
+	config        *config.Config
+	logger        *slog.Logger
+	store         store.Store
+	router        *chi.Mux
+	signingKeys   *auth.KeySet
+	mailer        mailer.Mailer
+	passwords     passwordpolicy.Policy
+	authService   *auth.AuthService
+	userService   user.ServiceInterface // Added UserService
+	apiKeyService apikey.ServiceInterface
+	rbacService   *rbac.Service
+	adminService  admin.ServiceInterface
+
+	oauthClientService    oauthclient.ServiceInterface
+	serviceAccountService serviceaccount.ServiceInterface
+	clientCertService     clientcert.ServiceInterface
+
+	authHandler   *api.AuthHandler
+	userHandler   *api.UserHandler
+	apiKeyHandler *api.APIKeyHandler
+	roleHandler   *api.RoleHandler
+
+	adminUserHandler   *api.AdminUserHandler
+	oauthHandler       *api.OAuthHandler
+	oauthClientHandler *api.OAuthClientHandler
+
+	serviceAccountHandler *api.ServiceAccountHandler
+	clientCertHandler     *api.ClientCertificateHandler
 }
 
 // NewServer creates and configures a new Server instance.
 // It initializes the router, sets up dependencies, and prepares the server
 // to handle requests. It returns an http.Handler (the configured router)
 // which can be used with http.ListenAndServe.
-func NewServer(cfg *config.Config, logger *slog.Logger, store store.Store) http.Handler {
+func NewServer(cfg *config.Config, logger *slog.Logger, store store.Store, signingKeys *auth.KeySet, mailer mailer.Mailer, passwords passwordpolicy.Policy) http.Handler {
 	s := &Server{
-		config: cfg,
-		logger: logger,
-		store:  store,
-		router: chi.NewRouter(), // Initialize the chi router
+		config:      cfg,
+		logger:      logger,
+		store:       store,
+		signingKeys: signingKeys,
+		mailer:      mailer,
+		passwords:   passwords,
+		router:      chi.NewRouter(), // Initialize the chi router
 	}
 
 	// Initialize services and handlers
@@ -65,17 +99,108 @@ func (s *Server) handleHealthCheck() http.HandlerFunc {
 }
 
 func (s *Server) initDependencies() {
+	argon2Params := auth.DefaultArgon2Params
+	argon2Params.Memory = s.config.PasswordArgon2Memory
+	argon2Params.Iterations = s.config.PasswordArgon2Iterations
+	argon2Params.Parallelism = s.config.PasswordArgon2Parallelism
+
 	// Initialize UserService first as AuthService might depend on it
-	s.userService = user.NewService(s.store) 
-	s.authService = auth.NewAuthService(s.store, s.userService, s.config.JWTSecret, s.config.JWTExpiryDuration) // Pass userService
+	s.userService = user.NewService(s.store)
+	s.apiKeyService = apikey.NewService(s.store, s.config.APIKeyRotationGracePeriod)
+	s.rbacService = rbac.NewService(s.store, s.config.PermissionCacheTTL)
+	recorder := audit.NewRecorder(s.store, s.logger)
+	s.clientCertService = clientcert.NewService(s.store, s.store, s.store, recorder)
+	s.authService = auth.NewAuthService(s.store, s.apiKeyService, s.mailer, auth.Config{
+		SigningKeys:        s.signingKeys,
+		AccessTokenExpiry:  s.config.JWTExpiryDuration,
+		RefreshTokenExpiry: s.config.RefreshTokenExpiryDuration,
+		Issuer:             s.config.JWTIssuer,
+		Audience:           s.config.JWTAudience,
+		Leeway:             s.config.JWTLeeway,
+		TOTPIssuer:         s.config.TOTPIssuer,
+
+		AppBaseURL:              s.config.AppBaseURL,
+		EmailVerificationExpiry: s.config.EmailVerificationExpiry,
+		PasswordResetExpiry:     s.config.PasswordResetExpiry,
+		RequireVerifiedEmail:    s.config.RequireVerifiedEmail,
+		ConcealExistingAccounts: s.config.ConcealExistingAccounts,
+
+		Throttle: auth.ThrottleConfig{
+			Threshold:        s.config.LoginThrottleThreshold,
+			IPThreshold:      s.config.LoginThrottleIPThreshold,
+			BaseDelay:        s.config.LoginThrottleBaseDelay,
+			MaxDelay:         s.config.LoginThrottleMaxDelay,
+			LockoutThreshold: s.config.AccountLockoutThreshold,
+			LockoutDuration:  s.config.AccountLockoutDuration,
+		},
+		PasswordHasher:  auth.NewArgon2idHasher(argon2Params),
+		PasswordPolicy:  s.passwords,
+		PasswordHistory: s.config.PasswordHistorySize,
+		Cookies: auth.CookieConfig{
+			Enabled:     s.config.SessionCookies,
+			Secure:      s.config.SessionCookieSecure,
+			SameSite:    sameSiteMode(s.config.SessionCookieSameSite),
+			Domain:      s.config.SessionCookieDomain,
+			RefreshPath: "/api/v1/auth",
+		},
+		OIDCProviders:            s.oidcProviders(),
+		ClientTokenExpiry:        s.config.OAuthTokenExpiry,
+		ImpersonationTokenExpiry: s.config.ImpersonationTokenExpiry,
+		AuditRecorder:            recorder,
+		SessionIdleTimeout:       s.config.SessionIdleTimeout,
+		SessionAbsoluteTimeout:   s.config.SessionAbsoluteTimeout,
+		ClientCertificates:       s.clientCertService,
+	})
+	s.adminService = admin.NewService(s.store, s.authService, s.apiKeyService, s.rbacService, recorder)
+	s.oauthClientService = oauthclient.NewService(s.store, recorder)
+	s.serviceAccountService = serviceaccount.NewService(s.store, s.apiKeyService, recorder)
 	s.authHandler = api.NewAuthHandler(s.authService)
 	s.userHandler = api.NewUserHandler(s.userService) // Pass userService
+	s.apiKeyHandler = api.NewAPIKeyHandler(s.apiKeyService)
+	s.roleHandler = api.NewRoleHandler(s.rbacService)
+	s.adminUserHandler = api.NewAdminUserHandler(s.adminService)
+	s.oauthHandler = api.NewOAuthHandler(s.authService, s.oauthClientService)
+	s.oauthClientHandler = api.NewOAuthClientHandler(s.oauthClientService)
+	s.serviceAccountHandler = api.NewServiceAccountHandler(s.serviceAccountService)
+	s.clientCertHandler = api.NewClientCertificateHandler(s.clientCertService)
+}
+
+// oidcProviders sets up the configured OpenID Connect providers. Their metadata and keys
+// are fetched when they are first used, so a provider that is down does not stop the server from starting.
+func (s *Server) oidcProviders() []*oidc.Provider {
+	client := &http.Client{Timeout: 10 * time.Second}
+	providers := make([]*oidc.Provider, 0, len(s.config.OIDCProviders))
+	for _, p := range s.config.OIDCProviders {
+		providers = append(providers, oidc.NewProvider(oidc.Config{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+			TrustEmail:   p.TrustEmail,
+			Leeway:       s.config.JWTLeeway,
+		}, client))
+	}
+	return providers
+}
+
+// sameSiteMode converts the SameSite setting from the configuration, which has already been validated.
+func sameSiteMode(value string) http.SameSite {
+	switch value {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteStrictMode
+	}
 }
 
 func (s *Server) addMiddlewares() {
-	s.router.Use(middleware.RequestID)           // Injects a request ID into the context
-	s.router.Use(middleware.RealIP)              // Sets X-Real-IP and X-Forwarded-For
-	s.router.Use(createSlogMiddleware(s.logger)) // Custom slog logging middleware
-	s.router.Use(middleware.Recoverer)           // Recovers from panics
-	s.router.Use(createCorsMiddleware())         // CORS configuration
+	s.router.Use(middleware.RequestID)                              // Injects a request ID into the context
+	s.router.Use(middleware.RealIP)                                 // Sets X-Real-IP and X-Forwarded-For
+	s.router.Use(createSlogMiddleware(s.logger))                    // Custom slog logging middleware
+	s.router.Use(middleware.Recoverer)                              // Recovers from panics
+	s.router.Use(createCorsMiddleware(s.config.CORSAllowedOrigins)) // CORS configuration
 }
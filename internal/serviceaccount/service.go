@@ -0,0 +1,213 @@
+// Package serviceaccount manages service accounts: non-human principals for bots and integrations.
+// They have no email address or password, so they cannot log in, and authenticate only with
+// API keys that administrators issue for them. Every change is recorded in the audit trail.
+package serviceaccount
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"go-api-structure/internal/apikey"
+	"go-api-structure/internal/audit"
+	"go-api-structure/internal/store"
+	"go-api-structure/internal/store/db"
+)
+
+var (
+	ErrServiceAccountNotFound = errors.New("service account not found")
+	ErrNameTaken              = errors.New("a service account with this name already exists")
+	ErrAPIKeyNotFound         = errors.New("API key not found")
+)
+
+// CreateParams holds the settings for a new service account.
+type CreateParams struct {
+	Name        string
+	Description string
+}
+
+// UpdateParams holds the fields of a service account that can be changed. Nil fields are left unchanged.
+type UpdateParams struct {
+	Name        *string
+	Description *string
+}
+
+// Details is a service account together with the metadata of its API keys.
+type Details struct {
+	ServiceAccount db.ServiceAccount
+	APIKeys        []db.ApiKey
+}
+
+// ServiceInterface defines the operations for managing service accounts.
+type ServiceInterface interface {
+	Create(ctx context.Context, actor audit.Actor, params CreateParams) (*db.ServiceAccount, error)
+	List(ctx context.Context) ([]db.ServiceAccount, error)
+	Get(ctx context.Context, id uuid.UUID) (*Details, error)
+	Update(ctx context.Context, actor audit.Actor, id uuid.UUID, params UpdateParams) (*db.ServiceAccount, error)
+	// Delete deletes a service account; its API keys stop working at once.
+	Delete(ctx context.Context, actor audit.Actor, id uuid.UUID) error
+	// CreateAPIKey issues an API key for a service account and returns it together with the raw key,
+	// which is not stored and cannot be retrieved again.
+	CreateAPIKey(ctx context.Context, actor audit.Actor, id uuid.UUID, params apikey.CreateParams) (*db.ApiKey, string, error)
+	RevokeAPIKey(ctx context.Context, actor audit.Actor, id, keyID uuid.UUID) (*db.ApiKey, error)
+}
+
+// Service provides service account operations.
+type Service struct {
+	accountStore  store.ServiceAccountStore
+	apiKeyService apikey.ServiceInterface
+	recorder      *audit.Recorder
+}
+
+// NewService creates a new service account Service.
+func NewService(accountStore store.ServiceAccountStore, apiKeyService apikey.ServiceInterface, recorder *audit.Recorder) *Service {
+	return &Service{
+		accountStore:  accountStore,
+		apiKeyService: apiKeyService,
+		recorder:      recorder,
+	}
+}
+
+// Create creates a service account. It has no API keys until one is issued with CreateAPIKey.
+func (s *Service) Create(ctx context.Context, actor audit.Actor, params CreateParams) (*db.ServiceAccount, error) {
+	account, err := s.accountStore.CreateServiceAccount(ctx, db.CreateServiceAccountParams{
+		Name:        params.Name,
+		Description: params.Description,
+		CreatedBy:   pgtype.UUID{Bytes: actor.ID, Valid: actor.IsUser()},
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrConflict) {
+			return nil, ErrNameTaken
+		}
+		return nil, fmt.Errorf("failed to create service account: %w", err)
+	}
+
+	s.recorder.Record(ctx, actor, audit.ActionServiceAccountCreate, account.ID, map[string]any{
+		"name": account.Name,
+	})
+	return &account, nil
+}
+
+// List returns all service accounts, sorted by name.
+func (s *Service) List(ctx context.Context) ([]db.ServiceAccount, error) {
+	accounts, err := s.accountStore.ListServiceAccounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+	return accounts, nil
+}
+
+// Get returns a service account with its API keys, or ErrServiceAccountNotFound.
+func (s *Service) Get(ctx context.Context, id uuid.UUID) (*Details, error) {
+	account, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	apiKeys, err := s.apiKeyService.ListForServiceAccount(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return &Details{ServiceAccount: *account, APIKeys: apiKeys}, nil
+}
+
+// Update renames a service account or changes its description.
+func (s *Service) Update(ctx context.Context, actor audit.Actor, id uuid.UUID, params UpdateParams) (*db.ServiceAccount, error) {
+	arg := db.UpdateServiceAccountParams{ID: id}
+	if params.Name != nil {
+		arg.Name = pgtype.Text{String: *params.Name, Valid: true}
+	}
+	if params.Description != nil {
+		arg.Description = pgtype.Text{String: *params.Description, Valid: true}
+	}
+
+	account, err := s.accountStore.UpdateServiceAccount(ctx, arg)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			return nil, ErrServiceAccountNotFound
+		case errors.Is(err, store.ErrConflict):
+			return nil, ErrNameTaken
+		}
+		return nil, fmt.Errorf("failed to update service account: %w", err)
+	}
+
+	s.recorder.Record(ctx, actor, audit.ActionServiceAccountUpdate, account.ID, map[string]any{
+		"name": account.Name,
+	})
+	return &account, nil
+}
+
+// Delete deletes a service account together with its API keys.
+func (s *Service) Delete(ctx context.Context, actor audit.Actor, id uuid.UUID) error {
+	account, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := s.accountStore.DeleteServiceAccount(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete service account: %w", err)
+	}
+	if deleted == 0 {
+		return ErrServiceAccountNotFound
+	}
+
+	s.recorder.Record(ctx, actor, audit.ActionServiceAccountDelete, id, map[string]any{
+		"name": account.Name,
+	})
+	return nil
+}
+
+// CreateAPIKey issues an API key for a service account.
+func (s *Service) CreateAPIKey(ctx context.Context, actor audit.Actor, id uuid.UUID, params apikey.CreateParams) (*db.ApiKey, string, error) {
+	if _, err := s.get(ctx, id); err != nil {
+		return nil, "", err
+	}
+
+	apiKey, rawKey, err := s.apiKeyService.CreateForServiceAccount(ctx, id, params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.recorder.Record(ctx, actor, audit.ActionServiceAccountCreateAPIKey, id, map[string]any{
+		"api_key_id": apiKey.ID,
+		"name":       apiKey.Name,
+		"scopes":     apiKey.Scopes,
+	})
+	return apiKey, rawKey, nil
+}
+
+// RevokeAPIKey revokes one of a service account's API keys. Revoking a revoked key is not an error.
+func (s *Service) RevokeAPIKey(ctx context.Context, actor audit.Actor, id, keyID uuid.UUID) (*db.ApiKey, error) {
+	if _, err := s.get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	apiKey, err := s.apiKeyService.RevokeForServiceAccount(ctx, id, keyID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	s.recorder.Record(ctx, actor, audit.ActionServiceAccountRevokeAPIKey, id, map[string]any{
+		"api_key_id": apiKey.ID,
+	})
+	return apiKey, nil
+}
+
+// get returns a service account, or ErrServiceAccountNotFound.
+func (s *Service) get(ctx context.Context, id uuid.UUID) (*db.ServiceAccount, error) {
+	account, err := s.accountStore.GetServiceAccount(ctx, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, ErrServiceAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to get service account: %w", err)
+	}
+	return &account, nil
+}
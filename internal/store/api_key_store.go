@@ -0,0 +1,131 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"go-api-structure/internal/store/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// APIKeyStore defines the interface for API key persistence.
+// Lookups by ID are always scoped to the owning user or service account so one owner can never reach another's keys.
+type APIKeyStore interface {
+	CreateAPIKey(ctx context.Context, arg db.CreateAPIKeyParams) (db.ApiKey, error)
+	GetAPIKeyByKeyID(ctx context.Context, keyID pgtype.Text) (db.ApiKey, error)
+	// GetAPIKeyByHash finds keys issued before the gas_live_ format, which have no key ID.
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (db.ApiKey, error)
+	GetAPIKeyForUser(ctx context.Context, arg db.GetAPIKeyForUserParams) (db.ApiKey, error)
+	ListAPIKeysForUser(ctx context.Context, userID pgtype.UUID) ([]db.ApiKey, error)
+	ListAPIKeysForServiceAccount(ctx context.Context, serviceAccountID pgtype.UUID) ([]db.ApiKey, error)
+	UpdateAPIKey(ctx context.Context, arg db.UpdateAPIKeyParams) (db.ApiKey, error)
+	RevokeAPIKey(ctx context.Context, arg db.RevokeAPIKeyParams) (db.ApiKey, error)
+	RevokeServiceAccountAPIKey(ctx context.Context, arg db.RevokeServiceAccountAPIKeyParams) (db.ApiKey, error)
+	// RevokeUserAPIKeys revokes every key the user owns.
+	RevokeUserAPIKeys(ctx context.Context, userID pgtype.UUID) error
+	// MarkAPIKeyRotated links a key to its replacement and shortens its lifetime to the grace period.
+	// It returns ErrNotFound if the key was already rotated or revoked.
+	MarkAPIKeyRotated(ctx context.Context, arg db.MarkAPIKeyRotatedParams) (db.ApiKey, error)
+	// TouchAPIKey records that the key was used. Writes are throttled to one per minute per key.
+	TouchAPIKey(ctx context.Context, id uuid.UUID) error
+}
+
+// APIKeyStore implementation
+func (s *SQLStore) CreateAPIKey(ctx context.Context, arg db.CreateAPIKeyParams) (db.ApiKey, error) {
+	return s.Queries.CreateAPIKey(ctx, arg)
+}
+
+func (s *SQLStore) GetAPIKeyByKeyID(ctx context.Context, keyID pgtype.Text) (db.ApiKey, error) {
+	apiKey, err := s.Queries.GetAPIKeyByKeyID(ctx, keyID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ApiKey{}, ErrNotFound
+		}
+		return db.ApiKey{}, err
+	}
+	return apiKey, nil
+}
+
+func (s *SQLStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (db.ApiKey, error) {
+	apiKey, err := s.Queries.GetAPIKeyByHash(ctx, keyHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ApiKey{}, ErrNotFound
+		}
+		return db.ApiKey{}, err
+	}
+	return apiKey, nil
+}
+
+func (s *SQLStore) GetAPIKeyForUser(ctx context.Context, arg db.GetAPIKeyForUserParams) (db.ApiKey, error) {
+	apiKey, err := s.Queries.GetAPIKeyForUser(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ApiKey{}, ErrNotFound
+		}
+		return db.ApiKey{}, err
+	}
+	return apiKey, nil
+}
+
+func (s *SQLStore) ListAPIKeysForUser(ctx context.Context, userID pgtype.UUID) ([]db.ApiKey, error) {
+	return s.Queries.ListAPIKeysForUser(ctx, userID)
+}
+
+func (s *SQLStore) ListAPIKeysForServiceAccount(ctx context.Context, serviceAccountID pgtype.UUID) ([]db.ApiKey, error) {
+	return s.Queries.ListAPIKeysForServiceAccount(ctx, serviceAccountID)
+}
+
+func (s *SQLStore) UpdateAPIKey(ctx context.Context, arg db.UpdateAPIKeyParams) (db.ApiKey, error) {
+	apiKey, err := s.Queries.UpdateAPIKey(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ApiKey{}, ErrNotFound
+		}
+		return db.ApiKey{}, err
+	}
+	return apiKey, nil
+}
+
+func (s *SQLStore) RevokeAPIKey(ctx context.Context, arg db.RevokeAPIKeyParams) (db.ApiKey, error) {
+	apiKey, err := s.Queries.RevokeAPIKey(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ApiKey{}, ErrNotFound
+		}
+		return db.ApiKey{}, err
+	}
+	return apiKey, nil
+}
+
+func (s *SQLStore) RevokeServiceAccountAPIKey(ctx context.Context, arg db.RevokeServiceAccountAPIKeyParams) (db.ApiKey, error) {
+	apiKey, err := s.Queries.RevokeServiceAccountAPIKey(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ApiKey{}, ErrNotFound
+		}
+		return db.ApiKey{}, err
+	}
+	return apiKey, nil
+}
+
+func (s *SQLStore) RevokeUserAPIKeys(ctx context.Context, userID pgtype.UUID) error {
+	return s.Queries.RevokeUserAPIKeys(ctx, userID)
+}
+
+func (s *SQLStore) MarkAPIKeyRotated(ctx context.Context, arg db.MarkAPIKeyRotatedParams) (db.ApiKey, error) {
+	apiKey, err := s.Queries.MarkAPIKeyRotated(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ApiKey{}, ErrNotFound
+		}
+		return db.ApiKey{}, err
+	}
+	return apiKey, nil
+}
+
+func (s *SQLStore) TouchAPIKey(ctx context.Context, id uuid.UUID) error {
+	return s.Queries.TouchAPIKey(ctx, id)
+}
@@ -0,0 +1,12 @@
+package store
+
+import (
+	"context"
+	"go-api-structure/internal/store/db"
+)
+
+// AuditEventStore defines the interface for the audit trail of actions taken on other users' accounts.
+// Events are only ever added.
+type AuditEventStore interface {
+	CreateAuditEvent(ctx context.Context, arg db.CreateAuditEventParams) error
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"go-api-structure/internal/store/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ClientCertificateStore defines the interface for the client certificates that authenticate
+// users and service accounts on the TLS listener.
+type ClientCertificateStore interface {
+	// CreateClientCertificate returns ErrConflict if the fingerprint or subject is already registered.
+	CreateClientCertificate(ctx context.Context, arg db.CreateClientCertificateParams) (db.ClientCertificate, error)
+	GetClientCertificate(ctx context.Context, id uuid.UUID) (db.ClientCertificate, error)
+	GetClientCertificateByIdentity(ctx context.Context, arg db.GetClientCertificateByIdentityParams) (db.ClientCertificate, error)
+	ListClientCertificates(ctx context.Context) ([]db.ClientCertificate, error)
+	TouchClientCertificate(ctx context.Context, id uuid.UUID) error
+	// DeleteClientCertificate returns the number of certificates deleted.
+	DeleteClientCertificate(ctx context.Context, id uuid.UUID) (int64, error)
+}
+
+// ClientCertificateStore implementation
+func (s *SQLStore) CreateClientCertificate(ctx context.Context, arg db.CreateClientCertificateParams) (db.ClientCertificate, error) {
+	certificate, err := s.Queries.CreateClientCertificate(ctx, arg)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.ClientCertificate{}, ErrConflict
+		}
+		return db.ClientCertificate{}, err
+	}
+	return certificate, nil
+}
+
+func (s *SQLStore) GetClientCertificate(ctx context.Context, id uuid.UUID) (db.ClientCertificate, error) {
+	certificate, err := s.Queries.GetClientCertificate(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ClientCertificate{}, ErrNotFound
+		}
+		return db.ClientCertificate{}, err
+	}
+	return certificate, nil
+}
+
+func (s *SQLStore) GetClientCertificateByIdentity(ctx context.Context, arg db.GetClientCertificateByIdentityParams) (db.ClientCertificate, error) {
+	certificate, err := s.Queries.GetClientCertificateByIdentity(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ClientCertificate{}, ErrNotFound
+		}
+		return db.ClientCertificate{}, err
+	}
+	return certificate, nil
+}
+
+func (s *SQLStore) ListClientCertificates(ctx context.Context) ([]db.ClientCertificate, error) {
+	return s.Queries.ListClientCertificates(ctx)
+}
+
+func (s *SQLStore) TouchClientCertificate(ctx context.Context, id uuid.UUID) error {
+	return s.Queries.TouchClientCertificate(ctx, id)
+}
+
+func (s *SQLStore) DeleteClientCertificate(ctx context.Context, id uuid.UUID) (int64, error) {
+	return s.Queries.DeleteClientCertificate(ctx, id)
+}
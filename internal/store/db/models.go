@@ -9,12 +9,199 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type User struct {
+type ApiKey struct {
+	ID               uuid.UUID          `json:"id"`
+	UserID           pgtype.UUID        `json:"user_id"`
+	Name             string             `json:"name"`
+	Scopes           []string           `json:"scopes"`
+	ExpiresAt        pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt       pgtype.Timestamptz `json:"last_used_at"`
+	Revoked          bool               `json:"revoked"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	KeyID            pgtype.Text        `json:"key_id"`
+	KeyHash          string             `json:"key_hash"`
+	ReplacedBy       pgtype.UUID        `json:"replaced_by"`
+	ServiceAccountID pgtype.UUID        `json:"service_account_id"`
+}
+
+type AuditEvent struct {
+	ID        uuid.UUID          `json:"id"`
+	ActorID   uuid.UUID          `json:"actor_id"`
+	Action    string             `json:"action"`
+	TargetID  pgtype.UUID        `json:"target_id"`
+	Details   []byte             `json:"details"`
+	ClientIp  string             `json:"client_ip"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	ActorKind string             `json:"actor_kind"`
+}
+
+type ClientCertificate struct {
+	ID               uuid.UUID          `json:"id"`
+	Name             string             `json:"name"`
+	UserID           pgtype.UUID        `json:"user_id"`
+	ServiceAccountID pgtype.UUID        `json:"service_account_id"`
+	SpkiSha256       pgtype.Text        `json:"spki_sha256"`
+	Subject          pgtype.Text        `json:"subject"`
+	Scopes           []string           `json:"scopes"`
+	CreatedBy        pgtype.UUID        `json:"created_by"`
+	LastUsedAt       pgtype.Timestamptz `json:"last_used_at"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+}
+
+type EmailChangeRequest struct {
+	ID              uuid.UUID          `json:"id"`
+	UserID          uuid.UUID          `json:"user_id"`
+	OldEmail        string             `json:"old_email"`
+	NewEmail        string             `json:"new_email"`
+	TokenHash       string             `json:"token_hash"`
+	CancelTokenHash string             `json:"cancel_token_hash"`
+	ExpiresAt       pgtype.Timestamptz `json:"expires_at"`
+	ConfirmedAt     pgtype.Timestamptz `json:"confirmed_at"`
+	CancelledAt     pgtype.Timestamptz `json:"cancelled_at"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+}
+
+type LoginAttempt struct {
+	Scope        string             `json:"scope"`
+	Identifier   string             `json:"identifier"`
+	Failures     int32              `json:"failures"`
+	LastFailedAt pgtype.Timestamptz `json:"last_failed_at"`
+	LockedUntil  pgtype.Timestamptz `json:"locked_until"`
+}
+
+type MfaRecoveryCode struct {
+	ID        uuid.UUID          `json:"id"`
+	UserID    uuid.UUID          `json:"user_id"`
+	CodeHash  string             `json:"code_hash"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type OauthClient struct {
+	ID         uuid.UUID          `json:"id"`
+	Name       string             `json:"name"`
+	SecretHash string             `json:"secret_hash"`
+	Scopes     []string           `json:"scopes"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	RevokedAt  pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+type OidcLoginState struct {
+	ID           uuid.UUID          `json:"id"`
+	Provider     string             `json:"provider"`
+	StateHash    string             `json:"state_hash"`
+	Nonce        string             `json:"nonce"`
+	CodeVerifier string             `json:"code_verifier"`
+	ExpiresAt    pgtype.Timestamptz `json:"expires_at"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type PasswordHistory struct {
 	ID           uuid.UUID          `json:"id"`
-	Username     string             `json:"username"`
-	Email        string             `json:"email"`
+	UserID       uuid.UUID          `json:"user_id"`
 	PasswordHash string             `json:"password_hash"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
-	ApiKey       string             `json:"api_key"`
+}
+
+type PasswordResetToken struct {
+	ID        uuid.UUID          `json:"id"`
+	UserID    uuid.UUID          `json:"user_id"`
+	TokenHash string             `json:"token_hash"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	UsedAt    pgtype.Timestamptz `json:"used_at"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type RefreshToken struct {
+	ID          uuid.UUID          `json:"id"`
+	UserID      uuid.UUID          `json:"user_id"`
+	FamilyID    uuid.UUID          `json:"family_id"`
+	TokenHash   string             `json:"token_hash"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	UsedAt      pgtype.Timestamptz `json:"used_at"`
+	RevokedAt   pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	AuthMethods []string           `json:"auth_methods"`
+}
+
+type RevokedToken struct {
+	Jti       uuid.UUID          `json:"jti"`
+	UserID    pgtype.UUID        `json:"user_id"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	RevokedAt pgtype.Timestamptz `json:"revoked_at"`
+	ClientID  pgtype.UUID        `json:"client_id"`
+}
+
+type Role struct {
+	ID          uuid.UUID          `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Builtin     bool               `json:"builtin"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type RolePermission struct {
+	RoleID     uuid.UUID `json:"role_id"`
+	Permission string    `json:"permission"`
+}
+
+type ServiceAccount struct {
+	ID          uuid.UUID          `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	CreatedBy   pgtype.UUID        `json:"created_by"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+type Session struct {
+	ID         uuid.UUID          `json:"id"`
+	UserID     uuid.UUID          `json:"user_id"`
+	UserAgent  string             `json:"user_agent"`
+	IpAddress  string             `json:"ip_address"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	LastSeenAt pgtype.Timestamptz `json:"last_seen_at"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+}
+
+type User struct {
+	ID                      uuid.UUID          `json:"id"`
+	Username                string             `json:"username"`
+	Email                   string             `json:"email"`
+	PasswordHash            string             `json:"password_hash"`
+	CreatedAt               pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt               pgtype.Timestamptz `json:"updated_at"`
+	TokensRevokedBefore     pgtype.Timestamptz `json:"tokens_revoked_before"`
+	TotpSecret              pgtype.Text        `json:"totp_secret"`
+	TotpEnabledAt           pgtype.Timestamptz `json:"totp_enabled_at"`
+	TotpLastUsedStep        pgtype.Int8        `json:"totp_last_used_step"`
+	EmailVerifiedAt         pgtype.Timestamptz `json:"email_verified_at"`
+	SuspendedAt             pgtype.Timestamptz `json:"suspended_at"`
+	SuspensionReason        pgtype.Text        `json:"suspension_reason"`
+	PasswordResetRequiredAt pgtype.Timestamptz `json:"password_reset_required_at"`
+}
+
+type UserIdentity struct {
+	ID          uuid.UUID          `json:"id"`
+	UserID      uuid.UUID          `json:"user_id"`
+	Provider    string             `json:"provider"`
+	Subject     string             `json:"subject"`
+	Email       string             `json:"email"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	LastLoginAt pgtype.Timestamptz `json:"last_login_at"`
+}
+
+type UserRole struct {
+	UserID    uuid.UUID          `json:"user_id"`
+	RoleID    uuid.UUID          `json:"role_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
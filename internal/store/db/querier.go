@@ -8,15 +8,124 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	AddPasswordHistory(ctx context.Context, arg AddPasswordHistoryParams) error
+	AssignUserRole(ctx context.Context, arg AssignUserRoleParams) error
+	CancelPendingEmailChangeRequests(ctx context.Context, userID uuid.UUID) error
+	ChangeUserEmail(ctx context.Context, arg ChangeUserEmailParams) (User, error)
+	ConsumeOIDCLoginState(ctx context.Context, stateHash string) (OidcLoginState, error)
+	CountRoleUsers(ctx context.Context, roleID uuid.UUID) (int64, error)
+	CountUsers(ctx context.Context, arg CountUsersParams) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) error
+	CreateClientCertificate(ctx context.Context, arg CreateClientCertificateParams) (ClientCertificate, error)
+	CreateEmailChangeRequest(ctx context.Context, arg CreateEmailChangeRequestParams) (EmailChangeRequest, error)
+	CreateMFARecoveryCode(ctx context.Context, arg CreateMFARecoveryCodeParams) error
+	CreateOAuthClient(ctx context.Context, arg CreateOAuthClientParams) (OauthClient, error)
+	CreateOIDCLoginState(ctx context.Context, arg CreateOIDCLoginStateParams) error
+	CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error)
+	CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) (RefreshToken, error)
+	CreateRole(ctx context.Context, arg CreateRoleParams) (Role, error)
+	CreateServiceAccount(ctx context.Context, arg CreateServiceAccountParams) (ServiceAccount, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	GetUserByAPIKey(ctx context.Context, apiKey string) (User, error)
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error)
+	DeleteClientCertificate(ctx context.Context, id uuid.UUID) (int64, error)
+	DeleteExpiredEmailChangeRequests(ctx context.Context) (int64, error)
+	DeleteExpiredOIDCLoginStates(ctx context.Context) (int64, error)
+	DeleteExpiredPasswordResetTokens(ctx context.Context) (int64, error)
+	DeleteExpiredRefreshTokens(ctx context.Context) (int64, error)
+	DeleteExpiredRevokedTokens(ctx context.Context) (int64, error)
+	DeleteExpiredSessions(ctx context.Context) (int64, error)
+	DeleteLoginAttempt(ctx context.Context, arg DeleteLoginAttemptParams) error
+	DeleteMFARecoveryCodes(ctx context.Context, userID uuid.UUID) error
+	DeleteRole(ctx context.Context, id uuid.UUID) (int64, error)
+	DeleteServiceAccount(ctx context.Context, id uuid.UUID) (int64, error)
+	DeleteSession(ctx context.Context, id uuid.UUID) error
+	DeleteStaleLoginAttempts(ctx context.Context) (int64, error)
+	DeleteUser(ctx context.Context, id uuid.UUID) (int64, error)
+	DeleteUserSession(ctx context.Context, arg DeleteUserSessionParams) (int64, error)
+	DeleteUserSessions(ctx context.Context, userID uuid.UUID) error
+	DisableUserTOTP(ctx context.Context, id uuid.UUID) error
+	EnableUserTOTP(ctx context.Context, arg EnableUserTOTPParams) (User, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error)
+	GetAPIKeyByKeyID(ctx context.Context, keyID pgtype.Text) (ApiKey, error)
+	GetAPIKeyForUser(ctx context.Context, arg GetAPIKeyForUserParams) (ApiKey, error)
+	GetClientCertificate(ctx context.Context, id uuid.UUID) (ClientCertificate, error)
+	// A registered fingerprint takes precedence over a registered subject.
+	GetClientCertificateByIdentity(ctx context.Context, arg GetClientCertificateByIdentityParams) (ClientCertificate, error)
+	GetEmailChangeRequestByCancelTokenHash(ctx context.Context, cancelTokenHash string) (EmailChangeRequest, error)
+	GetEmailChangeRequestByTokenHash(ctx context.Context, tokenHash string) (EmailChangeRequest, error)
+	GetLoginAttempt(ctx context.Context, arg GetLoginAttemptParams) (LoginAttempt, error)
+	GetOAuthClient(ctx context.Context, id uuid.UUID) (OauthClient, error)
+	GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (PasswordResetToken, error)
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (RefreshToken, error)
+	GetRoleByID(ctx context.Context, id uuid.UUID) (Role, error)
+	GetRoleByName(ctx context.Context, name string) (Role, error)
+	GetServiceAccount(ctx context.Context, id uuid.UUID) (ServiceAccount, error)
+	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (User, error)
 	GetUserByUsername(ctx context.Context, username string) (User, error)
-	UpdateUserAPIKey(ctx context.Context, arg UpdateUserAPIKeyParams) (User, error)
+	GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error)
+	InvalidateUserPasswordResetTokens(ctx context.Context, userID uuid.UUID) error
+	IsTokenRevoked(ctx context.Context, jti uuid.UUID) (bool, error)
+	ListAPIKeysForServiceAccount(ctx context.Context, serviceAccountID pgtype.UUID) ([]ApiKey, error)
+	ListAPIKeysForUser(ctx context.Context, userID pgtype.UUID) ([]ApiKey, error)
+	ListAllRolePermissions(ctx context.Context) ([]RolePermission, error)
+	ListClientCertificates(ctx context.Context) ([]ClientCertificate, error)
+	ListOAuthClients(ctx context.Context) ([]OauthClient, error)
+	ListPasswordHistory(ctx context.Context, arg ListPasswordHistoryParams) ([]PasswordHistory, error)
+	ListPermissions(ctx context.Context) ([]Permission, error)
+	ListRolePermissions(ctx context.Context, roleID uuid.UUID) ([]string, error)
+	ListRoles(ctx context.Context) ([]Role, error)
+	ListServiceAccounts(ctx context.Context) ([]ServiceAccount, error)
+	ListUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error)
+	ListUserRoles(ctx context.Context, userID uuid.UUID) ([]Role, error)
+	ListUserSessions(ctx context.Context, userID uuid.UUID) ([]Session, error)
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	LockLoginAttempt(ctx context.Context, arg LockLoginAttemptParams) error
+	MarkAPIKeyRotated(ctx context.Context, arg MarkAPIKeyRotatedParams) (ApiKey, error)
+	MarkEmailChangeRequestCancelled(ctx context.Context, id uuid.UUID) (EmailChangeRequest, error)
+	MarkEmailChangeRequestConfirmed(ctx context.Context, id uuid.UUID) (EmailChangeRequest, error)
+	MarkPasswordResetTokenUsed(ctx context.Context, id uuid.UUID) (PasswordResetToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, id uuid.UUID) (RefreshToken, error)
+	MarkUserEmailVerified(ctx context.Context, arg MarkUserEmailVerifiedParams) (int64, error)
+	PrunePasswordHistory(ctx context.Context, arg PrunePasswordHistoryParams) error
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginAttempt, error)
+	RecordUserTOTPStep(ctx context.Context, arg RecordUserTOTPStepParams) (int64, error)
+	RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) error
+	RequireUserPasswordReset(ctx context.Context, id uuid.UUID) error
+	RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (ApiKey, error)
+	RevokeClientToken(ctx context.Context, arg RevokeClientTokenParams) error
+	RevokeOAuthClient(ctx context.Context, id uuid.UUID) (OauthClient, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeServiceAccountAPIKey(ctx context.Context, arg RevokeServiceAccountAPIKeyParams) (ApiKey, error)
+	RevokeToken(ctx context.Context, arg RevokeTokenParams) error
+	RevokeUserAPIKeys(ctx context.Context, userID pgtype.UUID) error
+	RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error
+	RevokeUserTokens(ctx context.Context, id uuid.UUID) error
+	SetOAuthClientSecret(ctx context.Context, arg SetOAuthClientSecretParams) (OauthClient, error)
+	SetRolePermissions(ctx context.Context, arg SetRolePermissionsParams) error
+	SetUserTOTPSecret(ctx context.Context, arg SetUserTOTPSecretParams) (User, error)
+	SuspendUser(ctx context.Context, arg SuspendUserParams) (User, error)
+	TouchAPIKey(ctx context.Context, id uuid.UUID) error
+	TouchClientCertificate(ctx context.Context, id uuid.UUID) error
+	TouchOAuthClient(ctx context.Context, id uuid.UUID) error
+	TouchSession(ctx context.Context, id uuid.UUID) error
+	TouchUserIdentity(ctx context.Context, arg TouchUserIdentityParams) error
+	UnassignUserRole(ctx context.Context, arg UnassignUserRoleParams) (int64, error)
+	UnsuspendUser(ctx context.Context, id uuid.UUID) (User, error)
+	UpdateAPIKey(ctx context.Context, arg UpdateAPIKeyParams) (ApiKey, error)
+	UpdateOAuthClient(ctx context.Context, arg UpdateOAuthClientParams) (OauthClient, error)
+	UpdateRole(ctx context.Context, arg UpdateRoleParams) (Role, error)
+	UpdateServiceAccount(ctx context.Context, arg UpdateServiceAccountParams) (ServiceAccount, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
+	UpdateUserUsername(ctx context.Context, arg UpdateUserUsernameParams) (User, error)
+	UseMFARecoveryCode(ctx context.Context, arg UseMFARecoveryCodeParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
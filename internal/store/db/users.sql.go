@@ -9,33 +9,86 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const changeUserEmail = `-- name: ChangeUserEmail :one
+UPDATE users
+SET email = $1,
+    email_verified_at = NOW(),
+    updated_at = NOW()
+WHERE id = $2
+  AND email = $3
+RETURNING id, username, email, password_hash, created_at, updated_at, tokens_revoked_before, totp_secret, totp_enabled_at, totp_last_used_step, email_verified_at, suspended_at, suspension_reason, password_reset_required_at
+`
+
+type ChangeUserEmailParams struct {
+	NewEmail string    `json:"new_email"`
+	ID       uuid.UUID `json:"id"`
+	OldEmail string    `json:"old_email"`
+}
+
+func (q *Queries) ChangeUserEmail(ctx context.Context, arg ChangeUserEmailParams) (User, error) {
+	row := q.db.QueryRow(ctx, changeUserEmail, arg.NewEmail, arg.ID, arg.OldEmail)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TokensRevokedBefore,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastUsedStep,
+		&i.EmailVerifiedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.PasswordResetRequiredAt,
+	)
+	return i, err
+}
+
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+WHERE ($1::text = ''
+       OR username ILIKE $1::text
+       OR email ILIKE $1::text)
+  AND ($2::boolean IS NULL
+       OR (suspended_at IS NOT NULL) = $2::boolean)
+`
+
+type CountUsersParams struct {
+	Pattern   string      `json:"pattern"`
+	Suspended pgtype.Bool `json:"suspended"`
+}
+
+func (q *Queries) CountUsers(ctx context.Context, arg CountUsersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUsers, arg.Pattern, arg.Suspended)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
     username,
     email,
-    password_hash,
-    api_key
+    password_hash
 ) VALUES (
-    $1, $2, $3, $4
-) RETURNING id, username, email, password_hash, created_at, updated_at, api_key
+    $1, $2, $3
+) RETURNING id, username, email, password_hash, created_at, updated_at, tokens_revoked_before, totp_secret, totp_enabled_at, totp_last_used_step, email_verified_at, suspended_at, suspension_reason, password_reset_required_at
 `
 
 type CreateUserParams struct {
 	Username     string `json:"username"`
 	Email        string `json:"email"`
 	PasswordHash string `json:"password_hash"`
-	ApiKey       string `json:"api_key"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRow(ctx, createUser,
-		arg.Username,
-		arg.Email,
-		arg.PasswordHash,
-		arg.ApiKey,
-	)
+	row := q.db.QueryRow(ctx, createUser, arg.Username, arg.Email, arg.PasswordHash)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -44,18 +97,63 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.ApiKey,
+		&i.TokensRevokedBefore,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastUsedStep,
+		&i.EmailVerifiedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.PasswordResetRequiredAt,
 	)
 	return i, err
 }
 
-const getUserByAPIKey = `-- name: GetUserByAPIKey :one
-SELECT id, username, email, password_hash, created_at, updated_at, api_key FROM users
-WHERE api_key = $1
+const deleteUser = `-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const disableUserTOTP = `-- name: DisableUserTOTP :exec
+UPDATE users
+SET totp_secret = NULL,
+    totp_enabled_at = NULL,
+    totp_last_used_step = NULL,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) DisableUserTOTP(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, disableUserTOTP, id)
+	return err
+}
+
+const enableUserTOTP = `-- name: EnableUserTOTP :one
+UPDATE users
+SET totp_enabled_at = NOW(),
+    totp_last_used_step = $2,
+    updated_at = NOW()
+WHERE id = $1
+  AND totp_secret IS NOT NULL
+  AND totp_enabled_at IS NULL
+RETURNING id, username, email, password_hash, created_at, updated_at, tokens_revoked_before, totp_secret, totp_enabled_at, totp_last_used_step, email_verified_at, suspended_at, suspension_reason, password_reset_required_at
 `
 
-func (q *Queries) GetUserByAPIKey(ctx context.Context, apiKey string) (User, error) {
-	row := q.db.QueryRow(ctx, getUserByAPIKey, apiKey)
+type EnableUserTOTPParams struct {
+	ID               uuid.UUID   `json:"id"`
+	TotpLastUsedStep pgtype.Int8 `json:"totp_last_used_step"`
+}
+
+func (q *Queries) EnableUserTOTP(ctx context.Context, arg EnableUserTOTPParams) (User, error) {
+	row := q.db.QueryRow(ctx, enableUserTOTP, arg.ID, arg.TotpLastUsedStep)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -64,13 +162,20 @@ func (q *Queries) GetUserByAPIKey(ctx context.Context, apiKey string) (User, err
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.ApiKey,
+		&i.TokensRevokedBefore,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastUsedStep,
+		&i.EmailVerifiedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.PasswordResetRequiredAt,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, email, password_hash, created_at, updated_at, api_key FROM users
+SELECT id, username, email, password_hash, created_at, updated_at, tokens_revoked_before, totp_secret, totp_enabled_at, totp_last_used_step, email_verified_at, suspended_at, suspension_reason, password_reset_required_at FROM users
 WHERE email = $1
 `
 
@@ -84,13 +189,20 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.ApiKey,
+		&i.TokensRevokedBefore,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastUsedStep,
+		&i.EmailVerifiedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.PasswordResetRequiredAt,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, username, email, password_hash, created_at, updated_at, api_key FROM users
+SELECT id, username, email, password_hash, created_at, updated_at, tokens_revoked_before, totp_secret, totp_enabled_at, totp_last_used_step, email_verified_at, suspended_at, suspension_reason, password_reset_required_at FROM users
 WHERE id = $1
 `
 
@@ -104,13 +216,20 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.ApiKey,
+		&i.TokensRevokedBefore,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastUsedStep,
+		&i.EmailVerifiedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.PasswordResetRequiredAt,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, email, password_hash, created_at, updated_at, api_key FROM users
+SELECT id, username, email, password_hash, created_at, updated_at, tokens_revoked_before, totp_secret, totp_enabled_at, totp_last_used_step, email_verified_at, suspended_at, suspension_reason, password_reset_required_at FROM users
 WHERE username = $1
 `
 
@@ -124,26 +243,296 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.ApiKey,
+		&i.TokensRevokedBefore,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastUsedStep,
+		&i.EmailVerifiedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.PasswordResetRequiredAt,
 	)
 	return i, err
 }
 
-const updateUserAPIKey = `-- name: UpdateUserAPIKey :one
+const listUsers = `-- name: ListUsers :many
+SELECT id, username, email, password_hash, created_at, updated_at, tokens_revoked_before, totp_secret, totp_enabled_at, totp_last_used_step, email_verified_at, suspended_at, suspension_reason, password_reset_required_at FROM users
+WHERE ($1::text = ''
+       OR username ILIKE $1::text
+       OR email ILIKE $1::text)
+  AND ($2::boolean IS NULL
+       OR (suspended_at IS NOT NULL) = $2::boolean)
+ORDER BY created_at DESC, id
+LIMIT $3 OFFSET $4
+`
+
+type ListUsersParams struct {
+	Pattern   string      `json:"pattern"`
+	Suspended pgtype.Bool `json:"suspended"`
+	Limit     int32       `json:"limit"`
+	Offset    int32       `json:"offset"`
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, listUsers,
+		arg.Pattern,
+		arg.Suspended,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.PasswordHash,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TokensRevokedBefore,
+			&i.TotpSecret,
+			&i.TotpEnabledAt,
+			&i.TotpLastUsedStep,
+			&i.EmailVerifiedAt,
+			&i.SuspendedAt,
+			&i.SuspensionReason,
+			&i.PasswordResetRequiredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markUserEmailVerified = `-- name: MarkUserEmailVerified :execrows
 UPDATE users
-SET api_key = $1,
+SET email_verified_at = NOW(),
     updated_at = NOW()
+WHERE id = $1
+  AND email = $2
+  AND email_verified_at IS NULL
+`
+
+type MarkUserEmailVerifiedParams struct {
+	ID    uuid.UUID `json:"id"`
+	Email string    `json:"email"`
+}
+
+func (q *Queries) MarkUserEmailVerified(ctx context.Context, arg MarkUserEmailVerifiedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markUserEmailVerified, arg.ID, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const recordUserTOTPStep = `-- name: RecordUserTOTPStep :execrows
+UPDATE users
+SET totp_last_used_step = $1::bigint
+WHERE id = $2
+  AND (totp_last_used_step IS NULL OR totp_last_used_step < $1::bigint)
+`
+
+type RecordUserTOTPStepParams struct {
+	Step int64     `json:"step"`
+	ID   uuid.UUID `json:"id"`
+}
+
+func (q *Queries) RecordUserTOTPStep(ctx context.Context, arg RecordUserTOTPStepParams) (int64, error) {
+	result, err := q.db.Exec(ctx, recordUserTOTPStep, arg.Step, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const rehashUserPassword = `-- name: RehashUserPassword :exec
+UPDATE users
+SET password_hash = $1
 WHERE id = $2
-RETURNING id, username, email, password_hash, created_at, updated_at, api_key
+  AND password_hash = $3
+`
+
+type RehashUserPasswordParams struct {
+	NewHash string    `json:"new_hash"`
+	ID      uuid.UUID `json:"id"`
+	OldHash string    `json:"old_hash"`
+}
+
+func (q *Queries) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) error {
+	_, err := q.db.Exec(ctx, rehashUserPassword, arg.NewHash, arg.ID, arg.OldHash)
+	return err
+}
+
+const requireUserPasswordReset = `-- name: RequireUserPasswordReset :exec
+UPDATE users
+SET password_reset_required_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) RequireUserPasswordReset(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, requireUserPasswordReset, id)
+	return err
+}
+
+const revokeUserTokens = `-- name: RevokeUserTokens :exec
+UPDATE users
+SET tokens_revoked_before = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) RevokeUserTokens(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, revokeUserTokens, id)
+	return err
+}
+
+const setUserTOTPSecret = `-- name: SetUserTOTPSecret :one
+UPDATE users
+SET totp_secret = $2,
+    totp_last_used_step = NULL,
+    updated_at = NOW()
+WHERE id = $1
+  AND totp_enabled_at IS NULL
+RETURNING id, username, email, password_hash, created_at, updated_at, tokens_revoked_before, totp_secret, totp_enabled_at, totp_last_used_step, email_verified_at, suspended_at, suspension_reason, password_reset_required_at
+`
+
+type SetUserTOTPSecretParams struct {
+	ID         uuid.UUID   `json:"id"`
+	TotpSecret pgtype.Text `json:"totp_secret"`
+}
+
+func (q *Queries) SetUserTOTPSecret(ctx context.Context, arg SetUserTOTPSecretParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserTOTPSecret, arg.ID, arg.TotpSecret)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TokensRevokedBefore,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastUsedStep,
+		&i.EmailVerifiedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.PasswordResetRequiredAt,
+	)
+	return i, err
+}
+
+const suspendUser = `-- name: SuspendUser :one
+UPDATE users
+SET suspended_at = COALESCE(suspended_at, NOW()),
+    suspension_reason = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, username, email, password_hash, created_at, updated_at, tokens_revoked_before, totp_secret, totp_enabled_at, totp_last_used_step, email_verified_at, suspended_at, suspension_reason, password_reset_required_at
+`
+
+type SuspendUserParams struct {
+	ID               uuid.UUID   `json:"id"`
+	SuspensionReason pgtype.Text `json:"suspension_reason"`
+}
+
+func (q *Queries) SuspendUser(ctx context.Context, arg SuspendUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, suspendUser, arg.ID, arg.SuspensionReason)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TokensRevokedBefore,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastUsedStep,
+		&i.EmailVerifiedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.PasswordResetRequiredAt,
+	)
+	return i, err
+}
+
+const unsuspendUser = `-- name: UnsuspendUser :one
+UPDATE users
+SET suspended_at = NULL,
+    suspension_reason = NULL,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, username, email, password_hash, created_at, updated_at, tokens_revoked_before, totp_secret, totp_enabled_at, totp_last_used_step, email_verified_at, suspended_at, suspension_reason, password_reset_required_at
+`
+
+func (q *Queries) UnsuspendUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, unsuspendUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TokensRevokedBefore,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastUsedStep,
+		&i.EmailVerifiedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.PasswordResetRequiredAt,
+	)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE users
+SET password_hash = $2,
+    password_reset_required_at = NULL,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateUserPasswordParams struct {
+	ID           uuid.UUID `json:"id"`
+	PasswordHash string    `json:"password_hash"`
+}
+
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+	_, err := q.db.Exec(ctx, updateUserPassword, arg.ID, arg.PasswordHash)
+	return err
+}
+
+const updateUserUsername = `-- name: UpdateUserUsername :one
+UPDATE users
+SET username = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, username, email, password_hash, created_at, updated_at, tokens_revoked_before, totp_secret, totp_enabled_at, totp_last_used_step, email_verified_at, suspended_at, suspension_reason, password_reset_required_at
 `
 
-type UpdateUserAPIKeyParams struct {
-	ApiKey string    `json:"api_key"`
-	ID     uuid.UUID `json:"id"`
+type UpdateUserUsernameParams struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
 }
 
-func (q *Queries) UpdateUserAPIKey(ctx context.Context, arg UpdateUserAPIKeyParams) (User, error) {
-	row := q.db.QueryRow(ctx, updateUserAPIKey, arg.ApiKey, arg.ID)
+func (q *Queries) UpdateUserUsername(ctx context.Context, arg UpdateUserUsernameParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserUsername, arg.ID, arg.Username)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -152,7 +541,14 @@ func (q *Queries) UpdateUserAPIKey(ctx context.Context, arg UpdateUserAPIKeyPara
 		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.ApiKey,
+		&i.TokensRevokedBefore,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastUsedStep,
+		&i.EmailVerifiedAt,
+		&i.SuspendedAt,
+		&i.SuspensionReason,
+		&i.PasswordResetRequiredAt,
 	)
 	return i, err
 }
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"go-api-structure/internal/store/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// EmailChangeStore defines the interface for pending email address changes.
+// Only SHA-256 digests of the confirmation and cancellation tokens are stored.
+type EmailChangeStore interface {
+	// CreateEmailChangeRequest returns ErrConflict if the user already has a pending change.
+	CreateEmailChangeRequest(ctx context.Context, arg db.CreateEmailChangeRequestParams) (db.EmailChangeRequest, error)
+	GetEmailChangeRequestByTokenHash(ctx context.Context, tokenHash string) (db.EmailChangeRequest, error)
+	GetEmailChangeRequestByCancelTokenHash(ctx context.Context, cancelTokenHash string) (db.EmailChangeRequest, error)
+	// MarkEmailChangeRequestConfirmed atomically flags a pending request as confirmed.
+	// It returns ErrNotFound if the request was already confirmed or cancelled.
+	MarkEmailChangeRequestConfirmed(ctx context.Context, id uuid.UUID) (db.EmailChangeRequest, error)
+	// MarkEmailChangeRequestCancelled atomically flags a request as cancelled.
+	// It returns ErrNotFound if the request was already cancelled.
+	MarkEmailChangeRequestCancelled(ctx context.Context, id uuid.UUID) (db.EmailChangeRequest, error)
+	// CancelPendingEmailChangeRequests cancels the user's unconfirmed request, if any.
+	CancelPendingEmailChangeRequests(ctx context.Context, userID uuid.UUID) error
+	DeleteExpiredEmailChangeRequests(ctx context.Context) (int64, error)
+}
+
+// EmailChangeStore implementation
+func (s *SQLStore) CreateEmailChangeRequest(ctx context.Context, arg db.CreateEmailChangeRequestParams) (db.EmailChangeRequest, error) {
+	request, err := s.Queries.CreateEmailChangeRequest(ctx, arg)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.EmailChangeRequest{}, ErrConflict
+		}
+		return db.EmailChangeRequest{}, err
+	}
+	return request, nil
+}
+
+func (s *SQLStore) GetEmailChangeRequestByTokenHash(ctx context.Context, tokenHash string) (db.EmailChangeRequest, error) {
+	request, err := s.Queries.GetEmailChangeRequestByTokenHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.EmailChangeRequest{}, ErrNotFound
+		}
+		return db.EmailChangeRequest{}, err
+	}
+	return request, nil
+}
+
+func (s *SQLStore) GetEmailChangeRequestByCancelTokenHash(ctx context.Context, cancelTokenHash string) (db.EmailChangeRequest, error) {
+	request, err := s.Queries.GetEmailChangeRequestByCancelTokenHash(ctx, cancelTokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.EmailChangeRequest{}, ErrNotFound
+		}
+		return db.EmailChangeRequest{}, err
+	}
+	return request, nil
+}
+
+func (s *SQLStore) MarkEmailChangeRequestConfirmed(ctx context.Context, id uuid.UUID) (db.EmailChangeRequest, error) {
+	request, err := s.Queries.MarkEmailChangeRequestConfirmed(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.EmailChangeRequest{}, ErrNotFound
+		}
+		return db.EmailChangeRequest{}, err
+	}
+	return request, nil
+}
+
+func (s *SQLStore) MarkEmailChangeRequestCancelled(ctx context.Context, id uuid.UUID) (db.EmailChangeRequest, error) {
+	request, err := s.Queries.MarkEmailChangeRequestCancelled(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.EmailChangeRequest{}, ErrNotFound
+		}
+		return db.EmailChangeRequest{}, err
+	}
+	return request, nil
+}
+
+func (s *SQLStore) CancelPendingEmailChangeRequests(ctx context.Context, userID uuid.UUID) error {
+	return s.Queries.CancelPendingEmailChangeRequests(ctx, userID)
+}
+
+func (s *SQLStore) DeleteExpiredEmailChangeRequests(ctx context.Context) (int64, error) {
+	return s.Queries.DeleteExpiredEmailChangeRequests(ctx)
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"go-api-structure/internal/store/db"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Scopes of login attempt records. Each scope tracks failures per identifier.
+const (
+	LoginScopeAccount = "account" // identifier is the lowercased email address a login was attempted for
+	LoginScopeIP      = "ip"      // identifier is the client IP address
+	LoginScopeMFA     = "mfa"     // identifier is the ID of the user completing a second factor
+)
+
+// LoginAttemptStore defines the interface for tracking failed authentication attempts.
+type LoginAttemptStore interface {
+	GetLoginAttempt(ctx context.Context, arg db.GetLoginAttemptParams) (db.LoginAttempt, error)
+	// RecordLoginFailure counts a failed attempt and returns the updated record.
+	// The count starts over if the previous failure happened before ResetBefore.
+	RecordLoginFailure(ctx context.Context, arg db.RecordLoginFailureParams) (db.LoginAttempt, error)
+	LockLoginAttempt(ctx context.Context, arg db.LockLoginAttemptParams) error
+	// DeleteLoginAttempt forgets all failures of an identifier, lifting any delay or lock.
+	DeleteLoginAttempt(ctx context.Context, arg db.DeleteLoginAttemptParams) error
+	// UnlockAccount lifts the password and second factor lockouts of a user and resets their failure counts.
+	// Failures counted against client IPs are kept.
+	UnlockAccount(ctx context.Context, userID uuid.UUID) error
+	DeleteStaleLoginAttempts(ctx context.Context) (int64, error)
+}
+
+// LoginAttemptStore implementation
+func (s *SQLStore) GetLoginAttempt(ctx context.Context, arg db.GetLoginAttemptParams) (db.LoginAttempt, error) {
+	attempt, err := s.Queries.GetLoginAttempt(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.LoginAttempt{}, ErrNotFound
+		}
+		return db.LoginAttempt{}, err
+	}
+	return attempt, nil
+}
+
+func (s *SQLStore) RecordLoginFailure(ctx context.Context, arg db.RecordLoginFailureParams) (db.LoginAttempt, error) {
+	return s.Queries.RecordLoginFailure(ctx, arg)
+}
+
+func (s *SQLStore) LockLoginAttempt(ctx context.Context, arg db.LockLoginAttemptParams) error {
+	return s.Queries.LockLoginAttempt(ctx, arg)
+}
+
+func (s *SQLStore) DeleteLoginAttempt(ctx context.Context, arg db.DeleteLoginAttemptParams) error {
+	return s.Queries.DeleteLoginAttempt(ctx, arg)
+}
+
+func (s *SQLStore) UnlockAccount(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	err = s.Queries.DeleteLoginAttempt(ctx, db.DeleteLoginAttemptParams{
+		Scope:      LoginScopeAccount,
+		Identifier: strings.ToLower(user.Email),
+	})
+	if err != nil {
+		return err
+	}
+	return s.Queries.DeleteLoginAttempt(ctx, db.DeleteLoginAttemptParams{
+		Scope:      LoginScopeMFA,
+		Identifier: userID.String(),
+	})
+}
+
+func (s *SQLStore) DeleteStaleLoginAttempts(ctx context.Context) (int64, error) {
+	return s.Queries.DeleteStaleLoginAttempts(ctx)
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"go-api-structure/internal/store/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// MFAStore defines the interface for persisting second factors.
+// TOTP state lives on the users table; recovery codes are stored as SHA-256 digests.
+type MFAStore interface {
+	// SetUserTOTPSecret stores a pending TOTP secret. It returns ErrNotFound if TOTP is already enabled.
+	SetUserTOTPSecret(ctx context.Context, arg db.SetUserTOTPSecretParams) (db.User, error)
+	// EnableUserTOTP activates the pending secret. It returns ErrNotFound if there is none.
+	EnableUserTOTP(ctx context.Context, arg db.EnableUserTOTPParams) (db.User, error)
+	DisableUserTOTP(ctx context.Context, id uuid.UUID) error
+	// RecordUserTOTPStep stores the time step of an accepted code. It affects no rows
+	// if the step is not newer than the last accepted one, i.e. the code is being replayed.
+	RecordUserTOTPStep(ctx context.Context, arg db.RecordUserTOTPStepParams) (int64, error)
+	CreateMFARecoveryCode(ctx context.Context, arg db.CreateMFARecoveryCodeParams) error
+	// UseMFARecoveryCode marks an unused code as used and returns the number of codes affected.
+	UseMFARecoveryCode(ctx context.Context, arg db.UseMFARecoveryCodeParams) (int64, error)
+	DeleteMFARecoveryCodes(ctx context.Context, userID uuid.UUID) error
+}
+
+// MFAStore implementation
+func (s *SQLStore) SetUserTOTPSecret(ctx context.Context, arg db.SetUserTOTPSecretParams) (db.User, error) {
+	user, err := s.Queries.SetUserTOTPSecret(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.User{}, ErrNotFound
+		}
+		return db.User{}, err
+	}
+	return user, nil
+}
+
+func (s *SQLStore) EnableUserTOTP(ctx context.Context, arg db.EnableUserTOTPParams) (db.User, error) {
+	user, err := s.Queries.EnableUserTOTP(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.User{}, ErrNotFound
+		}
+		return db.User{}, err
+	}
+	return user, nil
+}
+
+func (s *SQLStore) DisableUserTOTP(ctx context.Context, id uuid.UUID) error {
+	return s.Queries.DisableUserTOTP(ctx, id)
+}
+
+func (s *SQLStore) RecordUserTOTPStep(ctx context.Context, arg db.RecordUserTOTPStepParams) (int64, error) {
+	return s.Queries.RecordUserTOTPStep(ctx, arg)
+}
+
+func (s *SQLStore) CreateMFARecoveryCode(ctx context.Context, arg db.CreateMFARecoveryCodeParams) error {
+	return s.Queries.CreateMFARecoveryCode(ctx, arg)
+}
+
+func (s *SQLStore) UseMFARecoveryCode(ctx context.Context, arg db.UseMFARecoveryCodeParams) (int64, error) {
+	return s.Queries.UseMFARecoveryCode(ctx, arg)
+}
+
+func (s *SQLStore) DeleteMFARecoveryCodes(ctx context.Context, userID uuid.UUID) error {
+	return s.Queries.DeleteMFARecoveryCodes(ctx, userID)
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"go-api-structure/internal/store/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// OAuthClientStore defines the interface for registered OAuth clients.
+type OAuthClientStore interface {
+	CreateOAuthClient(ctx context.Context, arg db.CreateOAuthClientParams) (db.OauthClient, error)
+	GetOAuthClient(ctx context.Context, id uuid.UUID) (db.OauthClient, error)
+	ListOAuthClients(ctx context.Context) ([]db.OauthClient, error)
+	// UpdateOAuthClient and SetOAuthClientSecret return ErrNotFound for revoked clients.
+	UpdateOAuthClient(ctx context.Context, arg db.UpdateOAuthClientParams) (db.OauthClient, error)
+	SetOAuthClientSecret(ctx context.Context, arg db.SetOAuthClientSecretParams) (db.OauthClient, error)
+	// RevokeOAuthClient keeps the time a client was first revoked if it is revoked again.
+	RevokeOAuthClient(ctx context.Context, id uuid.UUID) (db.OauthClient, error)
+	// TouchOAuthClient records that the client obtained a token. Writes are throttled to one per minute per client.
+	TouchOAuthClient(ctx context.Context, id uuid.UUID) error
+}
+
+// OAuthClientStore implementation
+func (s *SQLStore) CreateOAuthClient(ctx context.Context, arg db.CreateOAuthClientParams) (db.OauthClient, error) {
+	return s.Queries.CreateOAuthClient(ctx, arg)
+}
+
+func (s *SQLStore) GetOAuthClient(ctx context.Context, id uuid.UUID) (db.OauthClient, error) {
+	client, err := s.Queries.GetOAuthClient(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.OauthClient{}, ErrNotFound
+		}
+		return db.OauthClient{}, err
+	}
+	return client, nil
+}
+
+func (s *SQLStore) ListOAuthClients(ctx context.Context) ([]db.OauthClient, error) {
+	return s.Queries.ListOAuthClients(ctx)
+}
+
+func (s *SQLStore) UpdateOAuthClient(ctx context.Context, arg db.UpdateOAuthClientParams) (db.OauthClient, error) {
+	client, err := s.Queries.UpdateOAuthClient(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.OauthClient{}, ErrNotFound
+		}
+		return db.OauthClient{}, err
+	}
+	return client, nil
+}
+
+func (s *SQLStore) SetOAuthClientSecret(ctx context.Context, arg db.SetOAuthClientSecretParams) (db.OauthClient, error) {
+	client, err := s.Queries.SetOAuthClientSecret(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.OauthClient{}, ErrNotFound
+		}
+		return db.OauthClient{}, err
+	}
+	return client, nil
+}
+
+func (s *SQLStore) RevokeOAuthClient(ctx context.Context, id uuid.UUID) (db.OauthClient, error) {
+	client, err := s.Queries.RevokeOAuthClient(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.OauthClient{}, ErrNotFound
+		}
+		return db.OauthClient{}, err
+	}
+	return client, nil
+}
+
+func (s *SQLStore) TouchOAuthClient(ctx context.Context, id uuid.UUID) error {
+	return s.Queries.TouchOAuthClient(ctx, id)
+}
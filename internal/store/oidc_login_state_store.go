@@ -0,0 +1,39 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"go-api-structure/internal/store/db"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// OIDCLoginStateStore defines the interface for OpenID Connect logins in progress.
+// Only the SHA-256 digest of the state parameter is stored.
+type OIDCLoginStateStore interface {
+	CreateOIDCLoginState(ctx context.Context, arg db.CreateOIDCLoginStateParams) error
+	// ConsumeOIDCLoginState atomically deletes and returns the login with the given state,
+	// so each state can only be used once. It returns ErrNotFound if there is none.
+	ConsumeOIDCLoginState(ctx context.Context, stateHash string) (db.OidcLoginState, error)
+	DeleteExpiredOIDCLoginStates(ctx context.Context) (int64, error)
+}
+
+// OIDCLoginStateStore implementation
+func (s *SQLStore) CreateOIDCLoginState(ctx context.Context, arg db.CreateOIDCLoginStateParams) error {
+	return s.Queries.CreateOIDCLoginState(ctx, arg)
+}
+
+func (s *SQLStore) ConsumeOIDCLoginState(ctx context.Context, stateHash string) (db.OidcLoginState, error) {
+	state, err := s.Queries.ConsumeOIDCLoginState(ctx, stateHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.OidcLoginState{}, ErrNotFound
+		}
+		return db.OidcLoginState{}, err
+	}
+	return state, nil
+}
+
+func (s *SQLStore) DeleteExpiredOIDCLoginStates(ctx context.Context) (int64, error) {
+	return s.Queries.DeleteExpiredOIDCLoginStates(ctx)
+}
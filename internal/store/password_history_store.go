@@ -0,0 +1,29 @@
+package store
+
+import (
+	"context"
+	"go-api-structure/internal/store/db"
+)
+
+// PasswordHistoryStore defines the interface for remembering previous password hashes,
+// so that users can be kept from reusing them.
+type PasswordHistoryStore interface {
+	AddPasswordHistory(ctx context.Context, arg db.AddPasswordHistoryParams) error
+	// ListPasswordHistory returns the user's most recent previous hashes, newest first.
+	ListPasswordHistory(ctx context.Context, arg db.ListPasswordHistoryParams) ([]db.PasswordHistory, error)
+	// PrunePasswordHistory deletes all but the user's Keep most recent entries.
+	PrunePasswordHistory(ctx context.Context, arg db.PrunePasswordHistoryParams) error
+}
+
+// PasswordHistoryStore implementation
+func (s *SQLStore) AddPasswordHistory(ctx context.Context, arg db.AddPasswordHistoryParams) error {
+	return s.Queries.AddPasswordHistory(ctx, arg)
+}
+
+func (s *SQLStore) ListPasswordHistory(ctx context.Context, arg db.ListPasswordHistoryParams) ([]db.PasswordHistory, error) {
+	return s.Queries.ListPasswordHistory(ctx, arg)
+}
+
+func (s *SQLStore) PrunePasswordHistory(ctx context.Context, arg db.PrunePasswordHistoryParams) error {
+	return s.Queries.PrunePasswordHistory(ctx, arg)
+}
@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"go-api-structure/internal/store/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// PasswordResetTokenStore defines the interface for password reset token persistence.
+// Only SHA-256 digests of reset tokens are stored, never the tokens themselves.
+type PasswordResetTokenStore interface {
+	CreatePasswordResetToken(ctx context.Context, arg db.CreatePasswordResetTokenParams) (db.PasswordResetToken, error)
+	GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (db.PasswordResetToken, error)
+	// MarkPasswordResetTokenUsed atomically flags a token as used.
+	// It returns ErrNotFound if the token was already used.
+	MarkPasswordResetTokenUsed(ctx context.Context, id uuid.UUID) (db.PasswordResetToken, error)
+	// InvalidateUserPasswordResetTokens marks all of the user's outstanding tokens as used.
+	InvalidateUserPasswordResetTokens(ctx context.Context, userID uuid.UUID) error
+	DeleteExpiredPasswordResetTokens(ctx context.Context) (int64, error)
+}
+
+// PasswordResetTokenStore implementation
+func (s *SQLStore) CreatePasswordResetToken(ctx context.Context, arg db.CreatePasswordResetTokenParams) (db.PasswordResetToken, error) {
+	return s.Queries.CreatePasswordResetToken(ctx, arg)
+}
+
+func (s *SQLStore) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (db.PasswordResetToken, error) {
+	token, err := s.Queries.GetPasswordResetTokenByHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.PasswordResetToken{}, ErrNotFound
+		}
+		return db.PasswordResetToken{}, err
+	}
+	return token, nil
+}
+
+func (s *SQLStore) MarkPasswordResetTokenUsed(ctx context.Context, id uuid.UUID) (db.PasswordResetToken, error) {
+	token, err := s.Queries.MarkPasswordResetTokenUsed(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.PasswordResetToken{}, ErrNotFound
+		}
+		return db.PasswordResetToken{}, err
+	}
+	return token, nil
+}
+
+func (s *SQLStore) InvalidateUserPasswordResetTokens(ctx context.Context, userID uuid.UUID) error {
+	return s.Queries.InvalidateUserPasswordResetTokens(ctx, userID)
+}
+
+func (s *SQLStore) DeleteExpiredPasswordResetTokens(ctx context.Context) (int64, error) {
+	return s.Queries.DeleteExpiredPasswordResetTokens(ctx)
+}
@@ -0,0 +1,80 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (
+    user_id,
+    service_account_id,
+    name,
+    key_id,
+    key_hash,
+    scopes,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetAPIKeyByKeyID :one
+SELECT * FROM api_keys
+WHERE key_id = $1;
+
+-- name: GetAPIKeyByHash :one
+SELECT * FROM api_keys
+WHERE key_hash = $1;
+
+-- name: GetAPIKeyForUser :one
+SELECT * FROM api_keys
+WHERE id = $1 AND user_id = $2;
+
+-- name: ListAPIKeysForUser :many
+SELECT * FROM api_keys
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: UpdateAPIKey :one
+UPDATE api_keys
+SET name = COALESCE(sqlc.narg(name)::text, name),
+    scopes = COALESCE(sqlc.narg(scopes)::text[], scopes),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id) AND user_id = sqlc.arg(user_id)
+RETURNING *;
+
+-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked = TRUE,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = NOW()
+WHERE id = $1
+  AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute');
+
+-- name: MarkAPIKeyRotated :one
+UPDATE api_keys
+SET replaced_by = sqlc.arg(replaced_by),
+    expires_at = LEAST(expires_at, sqlc.arg(grace_ends_at)::timestamptz),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+  AND user_id = sqlc.arg(user_id)
+  AND replaced_by IS NULL
+  AND NOT revoked
+RETURNING *;
+
+-- name: RevokeUserAPIKeys :exec
+UPDATE api_keys
+SET revoked = TRUE,
+    updated_at = NOW()
+WHERE user_id = $1
+  AND NOT revoked;
+
+-- name: ListAPIKeysForServiceAccount :many
+SELECT * FROM api_keys
+WHERE service_account_id = $1
+ORDER BY created_at DESC;
+
+-- name: RevokeServiceAccountAPIKey :one
+UPDATE api_keys
+SET revoked = TRUE,
+    updated_at = NOW()
+WHERE id = $1 AND service_account_id = $2
+RETURNING *;
@@ -0,0 +1,11 @@
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (
+    actor_id,
+    actor_kind,
+    action,
+    target_id,
+    details,
+    client_ip
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+);
@@ -0,0 +1,37 @@
+-- name: CreateClientCertificate :one
+INSERT INTO client_certificates (
+    name,
+    user_id,
+    service_account_id,
+    spki_sha256,
+    subject,
+    scopes,
+    created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetClientCertificate :one
+SELECT * FROM client_certificates
+WHERE id = $1;
+
+-- name: GetClientCertificateByIdentity :one
+-- A registered fingerprint takes precedence over a registered subject.
+SELECT * FROM client_certificates
+WHERE spki_sha256 = sqlc.arg(spki_sha256) OR subject = sqlc.arg(subject)
+ORDER BY spki_sha256 IS NULL
+LIMIT 1;
+
+-- name: ListClientCertificates :many
+SELECT * FROM client_certificates
+ORDER BY created_at DESC;
+
+-- name: TouchClientCertificate :exec
+UPDATE client_certificates
+SET last_used_at = NOW()
+WHERE id = $1
+  AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute');
+
+-- name: DeleteClientCertificate :execrows
+DELETE FROM client_certificates
+WHERE id = $1;
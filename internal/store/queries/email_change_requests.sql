@@ -0,0 +1,45 @@
+-- name: CreateEmailChangeRequest :one
+INSERT INTO email_change_requests (
+    user_id,
+    old_email,
+    new_email,
+    token_hash,
+    cancel_token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetEmailChangeRequestByTokenHash :one
+SELECT * FROM email_change_requests
+WHERE token_hash = $1;
+
+-- name: GetEmailChangeRequestByCancelTokenHash :one
+SELECT * FROM email_change_requests
+WHERE cancel_token_hash = $1;
+
+-- name: MarkEmailChangeRequestConfirmed :one
+UPDATE email_change_requests
+SET confirmed_at = NOW()
+WHERE id = $1
+  AND confirmed_at IS NULL
+  AND cancelled_at IS NULL
+RETURNING *;
+
+-- name: MarkEmailChangeRequestCancelled :one
+UPDATE email_change_requests
+SET cancelled_at = NOW()
+WHERE id = $1
+  AND cancelled_at IS NULL
+RETURNING *;
+
+-- name: CancelPendingEmailChangeRequests :exec
+UPDATE email_change_requests
+SET cancelled_at = NOW()
+WHERE user_id = $1
+  AND confirmed_at IS NULL
+  AND cancelled_at IS NULL;
+
+-- name: DeleteExpiredEmailChangeRequests :execrows
+DELETE FROM email_change_requests
+WHERE expires_at < NOW();
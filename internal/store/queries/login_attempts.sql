@@ -0,0 +1,37 @@
+-- name: GetLoginAttempt :one
+SELECT * FROM login_attempts
+WHERE scope = $1
+  AND identifier = $2;
+
+-- name: RecordLoginFailure :one
+INSERT INTO login_attempts (
+    scope,
+    identifier,
+    failures,
+    last_failed_at
+) VALUES (
+    sqlc.arg(scope), sqlc.arg(identifier), 1, NOW()
+)
+ON CONFLICT (scope, identifier) DO UPDATE
+SET failures = CASE
+        WHEN login_attempts.last_failed_at < sqlc.arg(reset_before)::timestamptz THEN 1
+        ELSE login_attempts.failures + 1
+    END,
+    last_failed_at = NOW()
+RETURNING *;
+
+-- name: LockLoginAttempt :exec
+UPDATE login_attempts
+SET locked_until = $3
+WHERE scope = $1
+  AND identifier = $2;
+
+-- name: DeleteLoginAttempt :exec
+DELETE FROM login_attempts
+WHERE scope = $1
+  AND identifier = $2;
+
+-- name: DeleteStaleLoginAttempts :execrows
+DELETE FROM login_attempts
+WHERE last_failed_at < NOW() - INTERVAL '1 day'
+  AND (locked_until IS NULL OR locked_until < NOW());
@@ -0,0 +1,46 @@
+-- name: CreateOAuthClient :one
+INSERT INTO oauth_clients (
+    name,
+    secret_hash,
+    scopes
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetOAuthClient :one
+SELECT * FROM oauth_clients
+WHERE id = $1;
+
+-- name: ListOAuthClients :many
+SELECT * FROM oauth_clients
+ORDER BY created_at DESC;
+
+-- name: UpdateOAuthClient :one
+UPDATE oauth_clients
+SET name = COALESCE(sqlc.narg(name)::text, name),
+    scopes = COALESCE(sqlc.narg(scopes)::text[], scopes),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+  AND revoked_at IS NULL
+RETURNING *;
+
+-- name: SetOAuthClientSecret :one
+UPDATE oauth_clients
+SET secret_hash = $2,
+    updated_at = NOW()
+WHERE id = $1
+  AND revoked_at IS NULL
+RETURNING *;
+
+-- name: RevokeOAuthClient :one
+UPDATE oauth_clients
+SET revoked_at = COALESCE(revoked_at, NOW()),
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: TouchOAuthClient :exec
+UPDATE oauth_clients
+SET last_used_at = NOW()
+WHERE id = $1
+  AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute');
@@ -0,0 +1,19 @@
+-- name: CreateOIDCLoginState :exec
+INSERT INTO oidc_login_states (
+    provider,
+    state_hash,
+    nonce,
+    code_verifier,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+);
+
+-- name: ConsumeOIDCLoginState :one
+DELETE FROM oidc_login_states
+WHERE state_hash = $1
+RETURNING *;
+
+-- name: DeleteExpiredOIDCLoginStates :execrows
+DELETE FROM oidc_login_states
+WHERE expires_at < NOW();
@@ -0,0 +1,27 @@
+-- name: RevokeToken :exec
+INSERT INTO revoked_tokens (
+    jti,
+    user_id,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) ON CONFLICT (jti) DO NOTHING;
+
+-- name: IsTokenRevoked :one
+SELECT EXISTS (
+    SELECT 1 FROM revoked_tokens
+    WHERE jti = $1
+);
+
+-- name: DeleteExpiredRevokedTokens :execrows
+DELETE FROM revoked_tokens
+WHERE expires_at < NOW();
+
+-- name: RevokeClientToken :exec
+INSERT INTO revoked_tokens (
+    jti,
+    client_id,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) ON CONFLICT (jti) DO NOTHING;
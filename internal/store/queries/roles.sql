@@ -0,0 +1,84 @@
+-- name: ListPermissions :many
+SELECT * FROM permissions
+ORDER BY name;
+
+-- name: CreateRole :one
+INSERT INTO roles (
+    name,
+    description
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: GetRoleByID :one
+SELECT * FROM roles
+WHERE id = $1;
+
+-- name: GetRoleByName :one
+SELECT * FROM roles
+WHERE name = $1;
+
+-- name: ListRoles :many
+SELECT * FROM roles
+ORDER BY name;
+
+-- name: UpdateRole :one
+UPDATE roles
+SET name = COALESCE(sqlc.narg(name)::text, name),
+    description = COALESCE(sqlc.narg(description)::text, description),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteRole :execrows
+DELETE FROM roles
+WHERE id = $1
+  AND NOT builtin;
+
+-- name: ListRolePermissions :many
+SELECT permission FROM role_permissions
+WHERE role_id = $1
+ORDER BY permission;
+
+-- name: ListAllRolePermissions :many
+SELECT * FROM role_permissions
+ORDER BY role_id, permission;
+
+-- name: SetRolePermissions :exec
+WITH removed AS (
+    DELETE FROM role_permissions
+    WHERE role_id = sqlc.arg(role_id)
+      AND permission <> ALL(sqlc.arg(permissions)::text[])
+)
+INSERT INTO role_permissions (role_id, permission)
+SELECT sqlc.arg(role_id), unnest(sqlc.arg(permissions)::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: ListUserRoles :many
+SELECT r.* FROM roles r
+JOIN user_roles ur ON ur.role_id = r.id
+WHERE ur.user_id = $1
+ORDER BY r.name;
+
+-- name: ListUserPermissions :many
+SELECT DISTINCT rp.permission FROM user_roles ur
+JOIN role_permissions rp ON rp.role_id = ur.role_id
+WHERE ur.user_id = $1
+ORDER BY rp.permission;
+
+-- name: AssignUserRole :exec
+INSERT INTO user_roles (
+    user_id,
+    role_id
+) VALUES (
+    $1, $2
+) ON CONFLICT DO NOTHING;
+
+-- name: UnassignUserRole :execrows
+DELETE FROM user_roles
+WHERE user_id = $1
+  AND role_id = $2;
+
+-- name: CountRoleUsers :one
+SELECT COUNT(*) FROM user_roles
+WHERE role_id = $1;
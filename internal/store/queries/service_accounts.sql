@@ -0,0 +1,28 @@
+-- name: CreateServiceAccount :one
+INSERT INTO service_accounts (
+    name,
+    description,
+    created_by
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetServiceAccount :one
+SELECT * FROM service_accounts
+WHERE id = $1;
+
+-- name: ListServiceAccounts :many
+SELECT * FROM service_accounts
+ORDER BY name;
+
+-- name: UpdateServiceAccount :one
+UPDATE service_accounts
+SET name = COALESCE(sqlc.narg(name)::text, name),
+    description = COALESCE(sqlc.narg(description)::text, description),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeleteServiceAccount :execrows
+DELETE FROM service_accounts
+WHERE id = $1;
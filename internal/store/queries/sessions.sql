@@ -0,0 +1,43 @@
+-- name: CreateSession :one
+INSERT INTO sessions (
+    id,
+    user_id,
+    user_agent,
+    ip_address,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetSession :one
+SELECT * FROM sessions
+WHERE id = $1;
+
+-- name: ListUserSessions :many
+SELECT * FROM sessions
+WHERE user_id = $1
+  AND expires_at > NOW()
+ORDER BY last_seen_at DESC;
+
+-- name: TouchSession :exec
+UPDATE sessions
+SET last_seen_at = NOW()
+WHERE id = $1
+  AND last_seen_at < NOW() - INTERVAL '1 minute';
+
+-- name: DeleteSession :exec
+DELETE FROM sessions
+WHERE id = $1;
+
+-- name: DeleteUserSession :execrows
+DELETE FROM sessions
+WHERE id = $1
+  AND user_id = $2;
+
+-- name: DeleteUserSessions :exec
+DELETE FROM sessions
+WHERE user_id = $1;
+
+-- name: DeleteExpiredSessions :execrows
+DELETE FROM sessions
+WHERE expires_at < NOW();
@@ -2,10 +2,9 @@
 INSERT INTO users (
     username,
     email,
-    password_hash,
-    api_key
+    password_hash
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3
 ) RETURNING *;
 
 -- name: GetUserByID :one
@@ -20,13 +19,121 @@ WHERE email = $1;
 SELECT * FROM users
 WHERE username = $1;
 
--- name: UpdateUserAPIKey :one
+-- name: RevokeUserTokens :exec
 UPDATE users
-SET api_key = $1,
+SET tokens_revoked_before = NOW()
+WHERE id = $1;
+
+-- name: SetUserTOTPSecret :one
+UPDATE users
+SET totp_secret = $2,
+    totp_last_used_step = NULL,
+    updated_at = NOW()
+WHERE id = $1
+  AND totp_enabled_at IS NULL
+RETURNING *;
+
+-- name: EnableUserTOTP :one
+UPDATE users
+SET totp_enabled_at = NOW(),
+    totp_last_used_step = $2,
     updated_at = NOW()
-WHERE id = $2
+WHERE id = $1
+  AND totp_secret IS NOT NULL
+  AND totp_enabled_at IS NULL
 RETURNING *;
 
--- name: GetUserByAPIKey :one
+-- name: DisableUserTOTP :exec
+UPDATE users
+SET totp_secret = NULL,
+    totp_enabled_at = NULL,
+    totp_last_used_step = NULL,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: RecordUserTOTPStep :execrows
+UPDATE users
+SET totp_last_used_step = sqlc.arg(step)::bigint
+WHERE id = sqlc.arg(id)
+  AND (totp_last_used_step IS NULL OR totp_last_used_step < sqlc.arg(step)::bigint);
+
+-- name: MarkUserEmailVerified :execrows
+UPDATE users
+SET email_verified_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1
+  AND email = $2
+  AND email_verified_at IS NULL;
+
+-- name: UpdateUserPassword :exec
+UPDATE users
+SET password_hash = $2,
+    password_reset_required_at = NULL,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateUserUsername :one
+UPDATE users
+SET username = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: ChangeUserEmail :one
+UPDATE users
+SET email = sqlc.arg(new_email),
+    email_verified_at = NOW(),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+  AND email = sqlc.arg(old_email)
+RETURNING *;
+
+-- name: RehashUserPassword :exec
+UPDATE users
+SET password_hash = sqlc.arg(new_hash)
+WHERE id = sqlc.arg(id)
+  AND password_hash = sqlc.arg(old_hash);
+
+-- name: ListUsers :many
 SELECT * FROM users
-WHERE api_key = $1;
+WHERE (sqlc.arg(pattern)::text = ''
+       OR username ILIKE sqlc.arg(pattern)::text
+       OR email ILIKE sqlc.arg(pattern)::text)
+  AND (sqlc.narg(suspended)::boolean IS NULL
+       OR (suspended_at IS NOT NULL) = sqlc.narg(suspended)::boolean)
+ORDER BY created_at DESC, id
+LIMIT sqlc.arg(limit) OFFSET sqlc.arg(offset);
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+WHERE (sqlc.arg(pattern)::text = ''
+       OR username ILIKE sqlc.arg(pattern)::text
+       OR email ILIKE sqlc.arg(pattern)::text)
+  AND (sqlc.narg(suspended)::boolean IS NULL
+       OR (suspended_at IS NOT NULL) = sqlc.narg(suspended)::boolean);
+
+-- name: SuspendUser :one
+UPDATE users
+SET suspended_at = COALESCE(suspended_at, NOW()),
+    suspension_reason = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: UnsuspendUser :one
+UPDATE users
+SET suspended_at = NULL,
+    suspension_reason = NULL,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: RequireUserPasswordReset :exec
+UPDATE users
+SET password_reset_required_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1;
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"go-api-structure/internal/store/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// RefreshTokenStore defines the interface for refresh token persistence.
+// Only SHA-256 digests of refresh tokens are stored, never the tokens themselves.
+type RefreshTokenStore interface {
+	CreateRefreshToken(ctx context.Context, arg db.CreateRefreshTokenParams) (db.RefreshToken, error)
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (db.RefreshToken, error)
+	// MarkRefreshTokenUsed atomically flags a token as used. It returns ErrNotFound
+	// if the token was already used or revoked, which callers treat as reuse.
+	MarkRefreshTokenUsed(ctx context.Context, id uuid.UUID) (db.RefreshToken, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error
+	DeleteExpiredRefreshTokens(ctx context.Context) (int64, error)
+}
+
+// RefreshTokenStore implementation
+func (s *SQLStore) CreateRefreshToken(ctx context.Context, arg db.CreateRefreshTokenParams) (db.RefreshToken, error) {
+	return s.Queries.CreateRefreshToken(ctx, arg)
+}
+
+func (s *SQLStore) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (db.RefreshToken, error) {
+	token, err := s.Queries.GetRefreshTokenByHash(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.RefreshToken{}, ErrNotFound
+		}
+		return db.RefreshToken{}, err
+	}
+	return token, nil
+}
+
+func (s *SQLStore) MarkRefreshTokenUsed(ctx context.Context, id uuid.UUID) (db.RefreshToken, error) {
+	token, err := s.Queries.MarkRefreshTokenUsed(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.RefreshToken{}, ErrNotFound
+		}
+		return db.RefreshToken{}, err
+	}
+	return token, nil
+}
+
+func (s *SQLStore) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID) error {
+	return s.Queries.RevokeRefreshTokenFamily(ctx, familyID)
+}
+
+func (s *SQLStore) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	return s.Queries.RevokeUserRefreshTokens(ctx, userID)
+}
+
+func (s *SQLStore) DeleteExpiredRefreshTokens(ctx context.Context) (int64, error) {
+	return s.Queries.DeleteExpiredRefreshTokens(ctx)
+}
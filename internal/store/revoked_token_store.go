@@ -0,0 +1,35 @@
+package store
+
+import (
+	"context"
+	"go-api-structure/internal/store/db"
+
+	"github.com/google/uuid"
+)
+
+// RevokedTokenStore defines the interface for the access token revocation list.
+// Entries are keyed by the token's jti claim and only need to live until the token expires.
+type RevokedTokenStore interface {
+	RevokeToken(ctx context.Context, arg db.RevokeTokenParams) error
+	// RevokeClientToken revokes an access token issued to an OAuth client rather than a user.
+	RevokeClientToken(ctx context.Context, arg db.RevokeClientTokenParams) error
+	IsTokenRevoked(ctx context.Context, jti uuid.UUID) (bool, error)
+	DeleteExpiredRevokedTokens(ctx context.Context) (int64, error)
+}
+
+// RevokedTokenStore implementation
+func (s *SQLStore) RevokeToken(ctx context.Context, arg db.RevokeTokenParams) error {
+	return s.Queries.RevokeToken(ctx, arg)
+}
+
+func (s *SQLStore) RevokeClientToken(ctx context.Context, arg db.RevokeClientTokenParams) error {
+	return s.Queries.RevokeClientToken(ctx, arg)
+}
+
+func (s *SQLStore) IsTokenRevoked(ctx context.Context, jti uuid.UUID) (bool, error) {
+	return s.Queries.IsTokenRevoked(ctx, jti)
+}
+
+func (s *SQLStore) DeleteExpiredRevokedTokens(ctx context.Context) (int64, error) {
+	return s.Queries.DeleteExpiredRevokedTokens(ctx)
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"go-api-structure/internal/store/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// RoleStore defines the interface for roles, the permissions they grant and the users they are assigned to.
+type RoleStore interface {
+	ListPermissions(ctx context.Context) ([]db.Permission, error)
+	CreateRole(ctx context.Context, arg db.CreateRoleParams) (db.Role, error)
+	GetRoleByID(ctx context.Context, id uuid.UUID) (db.Role, error)
+	GetRoleByName(ctx context.Context, name string) (db.Role, error)
+	ListRoles(ctx context.Context) ([]db.Role, error)
+	UpdateRole(ctx context.Context, arg db.UpdateRoleParams) (db.Role, error)
+	// DeleteRole deletes a role and its assignments and returns the number of roles deleted.
+	// Built-in roles are never deleted.
+	DeleteRole(ctx context.Context, id uuid.UUID) (int64, error)
+	ListRolePermissions(ctx context.Context, roleID uuid.UUID) ([]string, error)
+	ListAllRolePermissions(ctx context.Context) ([]db.RolePermission, error)
+	// SetRolePermissions replaces the permissions a role grants.
+	SetRolePermissions(ctx context.Context, arg db.SetRolePermissionsParams) error
+	ListUserRoles(ctx context.Context, userID uuid.UUID) ([]db.Role, error)
+	// ListUserPermissions returns the permissions granted by all of a user's roles.
+	ListUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error)
+	// AssignUserRole gives a user a role; assigning a role the user already has is not an error.
+	AssignUserRole(ctx context.Context, arg db.AssignUserRoleParams) error
+	// UnassignUserRole takes a role away from a user and returns the number of assignments removed.
+	UnassignUserRole(ctx context.Context, arg db.UnassignUserRoleParams) (int64, error)
+	CountRoleUsers(ctx context.Context, roleID uuid.UUID) (int64, error)
+}
+
+// RoleStore implementation
+func (s *SQLStore) CreateRole(ctx context.Context, arg db.CreateRoleParams) (db.Role, error) {
+	role, err := s.Queries.CreateRole(ctx, arg)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.Role{}, ErrConflict
+		}
+		return db.Role{}, err
+	}
+	return role, nil
+}
+
+func (s *SQLStore) GetRoleByID(ctx context.Context, id uuid.UUID) (db.Role, error) {
+	role, err := s.Queries.GetRoleByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Role{}, ErrNotFound
+		}
+		return db.Role{}, err
+	}
+	return role, nil
+}
+
+func (s *SQLStore) GetRoleByName(ctx context.Context, name string) (db.Role, error) {
+	role, err := s.Queries.GetRoleByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Role{}, ErrNotFound
+		}
+		return db.Role{}, err
+	}
+	return role, nil
+}
+
+func (s *SQLStore) UpdateRole(ctx context.Context, arg db.UpdateRoleParams) (db.Role, error) {
+	role, err := s.Queries.UpdateRole(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Role{}, ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return db.Role{}, ErrConflict
+		}
+		return db.Role{}, err
+	}
+	return role, nil
+}
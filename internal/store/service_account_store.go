@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"go-api-structure/internal/store/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ServiceAccountStore defines the interface for service accounts, the non-human principals
+// that authenticate with API keys. Their keys are kept in the APIKeyStore.
+type ServiceAccountStore interface {
+	// CreateServiceAccount and UpdateServiceAccount return ErrConflict if the name is taken.
+	CreateServiceAccount(ctx context.Context, arg db.CreateServiceAccountParams) (db.ServiceAccount, error)
+	GetServiceAccount(ctx context.Context, id uuid.UUID) (db.ServiceAccount, error)
+	ListServiceAccounts(ctx context.Context) ([]db.ServiceAccount, error)
+	UpdateServiceAccount(ctx context.Context, arg db.UpdateServiceAccountParams) (db.ServiceAccount, error)
+	// DeleteServiceAccount deletes a service account with its API keys and returns the number of accounts deleted.
+	DeleteServiceAccount(ctx context.Context, id uuid.UUID) (int64, error)
+}
+
+// ServiceAccountStore implementation
+func (s *SQLStore) CreateServiceAccount(ctx context.Context, arg db.CreateServiceAccountParams) (db.ServiceAccount, error) {
+	account, err := s.Queries.CreateServiceAccount(ctx, arg)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.ServiceAccount{}, ErrConflict
+		}
+		return db.ServiceAccount{}, err
+	}
+	return account, nil
+}
+
+func (s *SQLStore) GetServiceAccount(ctx context.Context, id uuid.UUID) (db.ServiceAccount, error) {
+	account, err := s.Queries.GetServiceAccount(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ServiceAccount{}, ErrNotFound
+		}
+		return db.ServiceAccount{}, err
+	}
+	return account, nil
+}
+
+func (s *SQLStore) ListServiceAccounts(ctx context.Context) ([]db.ServiceAccount, error) {
+	return s.Queries.ListServiceAccounts(ctx)
+}
+
+func (s *SQLStore) UpdateServiceAccount(ctx context.Context, arg db.UpdateServiceAccountParams) (db.ServiceAccount, error) {
+	account, err := s.Queries.UpdateServiceAccount(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ServiceAccount{}, ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return db.ServiceAccount{}, ErrConflict
+		}
+		return db.ServiceAccount{}, err
+	}
+	return account, nil
+}
+
+func (s *SQLStore) DeleteServiceAccount(ctx context.Context, id uuid.UUID) (int64, error) {
+	return s.Queries.DeleteServiceAccount(ctx, id)
+}
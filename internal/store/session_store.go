@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"go-api-structure/internal/store/db"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SessionStore defines the interface for persisting the sessions users are logged in with.
+type SessionStore interface {
+	CreateSession(ctx context.Context, arg db.CreateSessionParams) (db.Session, error)
+	GetSession(ctx context.Context, id uuid.UUID) (db.Session, error)
+	// ListUserSessions returns the user's sessions that have not reached their absolute timeout,
+	// most recently used first.
+	ListUserSessions(ctx context.Context, userID uuid.UUID) ([]db.Session, error)
+	// TouchSession records that the session was used. It writes at most once a minute.
+	TouchSession(ctx context.Context, id uuid.UUID) error
+	DeleteSession(ctx context.Context, id uuid.UUID) error
+	// DeleteUserSession deletes one of the user's sessions and returns the number of rows deleted,
+	// zero if the session does not exist or belongs to someone else.
+	DeleteUserSession(ctx context.Context, arg db.DeleteUserSessionParams) (int64, error)
+	DeleteUserSessions(ctx context.Context, userID uuid.UUID) error
+	DeleteExpiredSessions(ctx context.Context) (int64, error)
+}
+
+// SessionStore implementation
+func (s *SQLStore) CreateSession(ctx context.Context, arg db.CreateSessionParams) (db.Session, error) {
+	return s.Queries.CreateSession(ctx, arg)
+}
+
+func (s *SQLStore) GetSession(ctx context.Context, id uuid.UUID) (db.Session, error) {
+	session, err := s.Queries.GetSession(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Session{}, ErrNotFound
+		}
+		return db.Session{}, err
+	}
+	return session, nil
+}
+
+func (s *SQLStore) ListUserSessions(ctx context.Context, userID uuid.UUID) ([]db.Session, error) {
+	return s.Queries.ListUserSessions(ctx, userID)
+}
+
+func (s *SQLStore) TouchSession(ctx context.Context, id uuid.UUID) error {
+	return s.Queries.TouchSession(ctx, id)
+}
+
+func (s *SQLStore) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	return s.Queries.DeleteSession(ctx, id)
+}
+
+func (s *SQLStore) DeleteUserSession(ctx context.Context, arg db.DeleteUserSessionParams) (int64, error) {
+	return s.Queries.DeleteUserSession(ctx, arg)
+}
+
+func (s *SQLStore) DeleteUserSessions(ctx context.Context, userID uuid.UUID) error {
+	return s.Queries.DeleteUserSessions(ctx, userID)
+}
+
+func (s *SQLStore) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	return s.Queries.DeleteExpiredSessions(ctx)
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 
 	"go-api-structure/internal/store/db"
+
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 // Store defines the interface for all data store operations.
@@ -17,13 +19,38 @@ import (
 var (
 	// ErrNotFound is returned when a specific resource is not found in the store.
 	ErrNotFound = errors.New("store: resource not found")
+	// ErrConflict is returned when a write violates a unique constraint.
+	ErrConflict = errors.New("store: resource already exists")
 )
 
 type Store interface {
 	db.Querier
+	UserStore
+	RefreshTokenStore
+	RevokedTokenStore
+	APIKeyStore
+	MFAStore
+	PasswordResetTokenStore
+	EmailChangeStore
+	LoginAttemptStore
+	PasswordHistoryStore
+	RoleStore
+	AuditEventStore
+	UserIdentityStore
+	OIDCLoginStateStore
+	OAuthClientStore
+	ServiceAccountStore
+	SessionStore
+	ClientCertificateStore
 	// We can add methods here that might combine multiple Querier calls
 	// or perform operations not directly mapped to a single SQL query.
 	// For now, embedding Querier is sufficient for basic CRUD, but this
 	// provides a place for more complex transaction scripts or business logic
 	// related to data access if needed in the future.
 }
+
+// isUniqueViolation reports whether err is a Postgres unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" // unique_violation
+}
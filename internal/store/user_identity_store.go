@@ -0,0 +1,46 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"go-api-structure/internal/store/db"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// UserIdentityStore defines the interface for accounts at external OpenID Connect providers
+// that are linked to users.
+type UserIdentityStore interface {
+	// CreateUserIdentity returns ErrConflict if the provider account is already linked to a user.
+	CreateUserIdentity(ctx context.Context, arg db.CreateUserIdentityParams) (db.UserIdentity, error)
+	GetUserIdentity(ctx context.Context, arg db.GetUserIdentityParams) (db.UserIdentity, error)
+	// TouchUserIdentity records a login through the identity and the email address the provider reported.
+	TouchUserIdentity(ctx context.Context, arg db.TouchUserIdentityParams) error
+}
+
+// UserIdentityStore implementation
+func (s *SQLStore) CreateUserIdentity(ctx context.Context, arg db.CreateUserIdentityParams) (db.UserIdentity, error) {
+	identity, err := s.Queries.CreateUserIdentity(ctx, arg)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.UserIdentity{}, ErrConflict
+		}
+		return db.UserIdentity{}, err
+	}
+	return identity, nil
+}
+
+func (s *SQLStore) GetUserIdentity(ctx context.Context, arg db.GetUserIdentityParams) (db.UserIdentity, error) {
+	identity, err := s.Queries.GetUserIdentity(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.UserIdentity{}, ErrNotFound
+		}
+		return db.UserIdentity{}, err
+	}
+	return identity, nil
+}
+
+func (s *SQLStore) TouchUserIdentity(ctx context.Context, arg db.TouchUserIdentityParams) error {
+	return s.Queries.TouchUserIdentity(ctx, arg)
+}
@@ -14,17 +14,50 @@ import (
 // For now, we'll list methods that correspond to our sqlc queries for users.
 // We'll also need to consider how parameters are passed (e.g., DTOs vs. direct model types).
 type UserStore interface {
+	// CreateUser returns ErrConflict if the username or email is taken.
 	CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (db.User, error)
 	GetUserByEmail(ctx context.Context, email string) (db.User, error)
 	GetUserByUsername(ctx context.Context, username string) (db.User, error)
-	GetUserByAPIKey(ctx context.Context, apiKey string) (db.User, error)
-	// TODO: Add UpdateUser, DeleteUser if needed later
+	// RevokeUserTokens invalidates every access token issued to the user up to now.
+	RevokeUserTokens(ctx context.Context, id uuid.UUID) error
+	// MarkUserEmailVerified flags the user's email as verified, provided it still matches
+	// the given address. It returns the number of users updated.
+	MarkUserEmailVerified(ctx context.Context, arg db.MarkUserEmailVerifiedParams) (int64, error)
+	UpdateUserPassword(ctx context.Context, arg db.UpdateUserPasswordParams) error
+	// RehashUserPassword replaces the stored hash of an unchanged password, provided it is still OldHash.
+	RehashUserPassword(ctx context.Context, arg db.RehashUserPasswordParams) error
+	// UpdateUserUsername returns ErrConflict if the username is taken.
+	UpdateUserUsername(ctx context.Context, arg db.UpdateUserUsernameParams) (db.User, error)
+	// ChangeUserEmail replaces the user's email, provided it still matches OldEmail, and marks it verified.
+	// It returns ErrNotFound if the address no longer matches and ErrConflict if the new one is taken.
+	ChangeUserEmail(ctx context.Context, arg db.ChangeUserEmailParams) (db.User, error)
+	// ListUsers returns a page of users, newest first, whose username or email matches Pattern (an ILIKE
+	// pattern; empty matches everyone), optionally only suspended or only active ones.
+	ListUsers(ctx context.Context, arg db.ListUsersParams) ([]db.User, error)
+	// CountUsers counts the users ListUsers would return without paging.
+	CountUsers(ctx context.Context, arg db.CountUsersParams) (int64, error)
+	// SuspendUser returns ErrNotFound if there is no such user. Suspending a suspended user only updates the reason.
+	SuspendUser(ctx context.Context, arg db.SuspendUserParams) (db.User, error)
+	// UnsuspendUser returns ErrNotFound if there is no such user.
+	UnsuspendUser(ctx context.Context, id uuid.UUID) (db.User, error)
+	// RequireUserPasswordReset makes the user set a new password before they can log in again.
+	RequireUserPasswordReset(ctx context.Context, id uuid.UUID) error
+	// DeleteUser deletes a user together with everything they own and returns the number of users deleted.
+	DeleteUser(ctx context.Context, id uuid.UUID) (int64, error)
+	// TODO: Add UpdateUser if needed later
 }
 
 // UserStore implementation
 func (s *SQLStore) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
-	return s.Queries.CreateUser(ctx, arg)
+	user, err := s.Queries.CreateUser(ctx, arg)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return db.User{}, ErrConflict
+		}
+		return db.User{}, err
+	}
+	return user, nil
 }
 
 func (s *SQLStore) GetUserByID(ctx context.Context, id uuid.UUID) (db.User, error) {
@@ -60,8 +93,63 @@ func (s *SQLStore) GetUserByUsername(ctx context.Context, username string) (db.U
 	return user, nil
 }
 
-func (s *SQLStore) GetUserByAPIKey(ctx context.Context, apiKey string) (db.User, error) {
-	user, err := s.Queries.GetUserByAPIKey(ctx, apiKey)
+func (s *SQLStore) RevokeUserTokens(ctx context.Context, id uuid.UUID) error {
+	return s.Queries.RevokeUserTokens(ctx, id)
+}
+
+func (s *SQLStore) MarkUserEmailVerified(ctx context.Context, arg db.MarkUserEmailVerifiedParams) (int64, error) {
+	return s.Queries.MarkUserEmailVerified(ctx, arg)
+}
+
+func (s *SQLStore) UpdateUserPassword(ctx context.Context, arg db.UpdateUserPasswordParams) error {
+	return s.Queries.UpdateUserPassword(ctx, arg)
+}
+
+func (s *SQLStore) RehashUserPassword(ctx context.Context, arg db.RehashUserPasswordParams) error {
+	return s.Queries.RehashUserPassword(ctx, arg)
+}
+
+func (s *SQLStore) UpdateUserUsername(ctx context.Context, arg db.UpdateUserUsernameParams) (db.User, error) {
+	user, err := s.Queries.UpdateUserUsername(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.User{}, ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return db.User{}, ErrConflict
+		}
+		return db.User{}, err
+	}
+	return user, nil
+}
+
+func (s *SQLStore) ChangeUserEmail(ctx context.Context, arg db.ChangeUserEmailParams) (db.User, error) {
+	user, err := s.Queries.ChangeUserEmail(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.User{}, ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return db.User{}, ErrConflict
+		}
+		return db.User{}, err
+	}
+	return user, nil
+}
+
+func (s *SQLStore) SuspendUser(ctx context.Context, arg db.SuspendUserParams) (db.User, error) {
+	user, err := s.Queries.SuspendUser(ctx, arg)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.User{}, ErrNotFound
+		}
+		return db.User{}, err
+	}
+	return user, nil
+}
+
+func (s *SQLStore) UnsuspendUser(ctx context.Context, id uuid.UUID) (db.User, error) {
+	user, err := s.Queries.UnsuspendUser(ctx, id)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return db.User{}, ErrNotFound
@@ -2,16 +2,20 @@ package user
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"go-api-structure/internal/store"
 	"go-api-structure/internal/store/db" // For db.User type
 
 	"github.com/google/uuid"
 )
 
+var ErrUsernameTaken = errors.New("username is already taken")
+
 // ServiceInterface defines the operations for the user service.
 type ServiceInterface interface {
 	GetUserByID(ctx context.Context, id uuid.UUID) (*db.User, error)
-	GetUserByAPIKey(ctx context.Context, apiKey string) (*db.User, error)
+	UpdateUsername(ctx context.Context, id uuid.UUID, username string) (*db.User, error)
 	// Add other user-specific business logic methods here if needed
 }
 
@@ -36,11 +40,14 @@ func (s *Service) GetUserByID(ctx context.Context, id uuid.UUID) (*db.User, erro
 	return &user, nil
 }
 
-// GetUserByAPIKey retrieves a user by their API key.
-func (s *Service) GetUserByAPIKey(ctx context.Context, apiKey string) (*db.User, error) {
-	user, err := s.userStore.GetUserByAPIKey(ctx, apiKey)
+// UpdateUsername changes the user's username. It returns ErrUsernameTaken if another user has it.
+func (s *Service) UpdateUsername(ctx context.Context, id uuid.UUID, username string) (*db.User, error) {
+	user, err := s.userStore.UpdateUserUsername(ctx, db.UpdateUserUsernameParams{ID: id, Username: username})
 	if err != nil {
-		return nil, err // Error handling (e.g., store.ErrNotFound) is done in the store layer
+		if errors.Is(err, store.ErrConflict) {
+			return nil, ErrUsernameTaken
+		}
+		return nil, fmt.Errorf("failed to update username: %w", err)
 	}
 	return &user, nil
 }
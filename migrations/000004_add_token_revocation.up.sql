@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Entries are purged once the token they describe has expired on its own.
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+
+-- Access tokens issued before this instant are rejected ("log out everywhere").
+ALTER TABLE users
+ADD COLUMN tokens_revoked_before TIMESTAMPTZ;
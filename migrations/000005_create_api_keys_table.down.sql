@@ -0,0 +1,14 @@
+ALTER TABLE users
+ADD COLUMN api_key TEXT UNIQUE;
+
+-- Restore each user's oldest key, or a fresh random one if they have none.
+UPDATE users
+SET api_key = COALESCE(
+    (SELECT key FROM api_keys WHERE api_keys.user_id = users.id ORDER BY created_at LIMIT 1),
+    gen_random_uuid()::text
+);
+
+ALTER TABLE users
+ALTER COLUMN api_key SET NOT NULL;
+
+DROP TABLE IF EXISTS api_keys;
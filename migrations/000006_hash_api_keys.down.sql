@@ -0,0 +1,12 @@
+-- Plaintext keys cannot be recovered from their digests, so every key is
+-- replaced with a new random value and has to be reissued.
+ALTER TABLE api_keys
+ADD COLUMN key TEXT UNIQUE;
+
+UPDATE api_keys
+SET key = gen_random_uuid()::text;
+
+ALTER TABLE api_keys
+ALTER COLUMN key SET NOT NULL,
+DROP COLUMN key_hash,
+DROP COLUMN key_id;
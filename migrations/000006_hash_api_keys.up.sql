@@ -0,0 +1,16 @@
+-- API keys are no longer stored in plaintext. New keys have the form
+-- gas_live_<key_id>_<secret>; key_id is public and used for lookups,
+-- key_hash is the hex SHA-256 digest of the complete key.
+ALTER TABLE api_keys
+ADD COLUMN key_id TEXT UNIQUE,
+ADD COLUMN key_hash TEXT;
+
+-- Existing keys keep working: they are hashed in place and, having no
+-- key_id, are looked up by their digest instead.
+UPDATE api_keys
+SET key_hash = encode(sha256(convert_to(key, 'UTF8')), 'hex');
+
+ALTER TABLE api_keys
+ALTER COLUMN key_hash SET NOT NULL,
+ADD CONSTRAINT api_keys_key_hash_key UNIQUE (key_hash),
+DROP COLUMN key;
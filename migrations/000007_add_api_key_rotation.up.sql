@@ -0,0 +1,4 @@
+-- Set when a key is rotated. The old key stays usable until its expires_at,
+-- which rotation moves to the end of the grace period.
+ALTER TABLE api_keys
+ADD COLUMN replaced_by UUID REFERENCES api_keys(id) ON DELETE SET NULL;
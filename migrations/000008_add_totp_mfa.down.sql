@@ -0,0 +1,9 @@
+ALTER TABLE refresh_tokens
+DROP COLUMN IF EXISTS auth_methods;
+
+DROP TABLE IF EXISTS mfa_recovery_codes;
+
+ALTER TABLE users
+DROP COLUMN IF EXISTS totp_last_used_step,
+DROP COLUMN IF EXISTS totp_enabled_at,
+DROP COLUMN IF EXISTS totp_secret;
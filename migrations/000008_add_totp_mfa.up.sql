@@ -0,0 +1,21 @@
+-- TOTP second factor. The secret is set at enrollment and only takes effect
+-- once totp_enabled_at is set by confirming a first code.
+-- totp_last_used_step stops a code from being accepted twice.
+ALTER TABLE users
+ADD COLUMN totp_secret TEXT,
+ADD COLUMN totp_enabled_at TIMESTAMPTZ,
+ADD COLUMN totp_last_used_step BIGINT;
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, code_hash)
+);
+
+-- Refresh tokens remember how the session was authenticated so rotated
+-- access tokens carry the same amr claim.
+ALTER TABLE refresh_tokens
+ADD COLUMN auth_methods TEXT[] NOT NULL DEFAULT '{pwd}';
@@ -0,0 +1,4 @@
+-- Set once the user proves they own their email address.
+-- Existing accounts start out unverified and can request a new verification email.
+ALTER TABLE users
+ADD COLUMN email_verified_at TIMESTAMPTZ;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS email_change_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    old_email VARCHAR(255) NOT NULL,
+    new_email VARCHAR(255) NOT NULL,
+    token_hash TEXT UNIQUE NOT NULL,
+    cancel_token_hash TEXT UNIQUE NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    confirmed_at TIMESTAMPTZ,
+    cancelled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_change_requests_user_id ON email_change_requests(user_id);
+
+-- At most one change can be pending per user.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_change_requests_pending
+    ON email_change_requests(user_id)
+    WHERE confirmed_at IS NULL AND cancelled_at IS NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE users
+DROP COLUMN IF EXISTS password_reset_required_at,
+DROP COLUMN IF EXISTS suspension_reason,
+DROP COLUMN IF EXISTS suspended_at;
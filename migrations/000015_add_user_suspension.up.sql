@@ -0,0 +1,7 @@
+-- Suspended users cannot log in, refresh tokens or use their API keys until unsuspended.
+-- password_reset_required_at is set by an administrator to make the user choose a new
+-- password before the next login; setting a password clears it.
+ALTER TABLE users
+ADD COLUMN suspended_at TIMESTAMPTZ,
+ADD COLUMN suspension_reason TEXT,
+ADD COLUMN password_reset_required_at TIMESTAMPTZ;
@@ -0,0 +1,14 @@
+-- Actions taken on behalf of other users, such as administrators suspending accounts.
+-- target_id has no foreign key so the trail of a deleted account is kept.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    actor_id UUID NOT NULL,
+    action VARCHAR(100) NOT NULL,
+    target_id UUID,
+    details JSONB NOT NULL DEFAULT '{}',
+    client_ip TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_id_created_at ON audit_events(actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_target_id_created_at ON audit_events(target_id, created_at DESC);
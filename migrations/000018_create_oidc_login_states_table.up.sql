@@ -0,0 +1,13 @@
+-- OpenID Connect logins that were started but not completed yet.
+-- Each row is deleted when the login completes; abandoned ones are purged once expires_at has passed.
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider VARCHAR(100) NOT NULL,
+    state_hash TEXT UNIQUE NOT NULL,
+    nonce TEXT NOT NULL,
+    code_verifier TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_oidc_login_states_expires_at ON oidc_login_states(expires_at);
@@ -0,0 +1,9 @@
+DELETE FROM permissions WHERE name IN ('clients:read', 'clients:write');
+
+DELETE FROM revoked_tokens WHERE user_id IS NULL;
+ALTER TABLE revoked_tokens
+DROP CONSTRAINT IF EXISTS revoked_tokens_owner_check,
+DROP COLUMN IF EXISTS client_id,
+ALTER COLUMN user_id SET NOT NULL;
+
+DROP TABLE IF EXISTS oauth_clients;
@@ -0,0 +1,28 @@
+-- Machine clients that obtain access tokens with the OAuth 2.0 client credentials grant.
+-- The id doubles as the client_id; only a digest of the secret is stored.
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    secret_hash TEXT NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Revoked access tokens belong either to a user or to a client.
+ALTER TABLE revoked_tokens
+ALTER COLUMN user_id DROP NOT NULL,
+ADD COLUMN client_id UUID REFERENCES oauth_clients(id) ON DELETE CASCADE,
+ADD CONSTRAINT revoked_tokens_owner_check CHECK ((user_id IS NULL) <> (client_id IS NULL));
+
+INSERT INTO permissions (name, description) VALUES
+    ('clients:read', 'View OAuth clients'),
+    ('clients:write', 'Register, change and revoke OAuth clients')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT roles.id, permissions.name FROM roles, permissions
+WHERE roles.name = 'admin' AND permissions.name IN ('clients:read', 'clients:write')
+ON CONFLICT DO NOTHING;
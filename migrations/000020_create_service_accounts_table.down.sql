@@ -0,0 +1,13 @@
+DELETE FROM permissions WHERE name IN ('service_accounts:read', 'service_accounts:write');
+
+ALTER TABLE audit_events
+DROP COLUMN IF EXISTS actor_kind;
+
+DELETE FROM api_keys WHERE user_id IS NULL;
+DROP INDEX IF EXISTS idx_api_keys_service_account_id;
+ALTER TABLE api_keys
+DROP CONSTRAINT IF EXISTS api_keys_owner_check,
+DROP COLUMN IF EXISTS service_account_id,
+ALTER COLUMN user_id SET NOT NULL;
+
+DROP TABLE IF EXISTS service_accounts;
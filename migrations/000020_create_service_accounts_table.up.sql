@@ -0,0 +1,31 @@
+-- Non-human principals. They have no email address or password and authenticate with API keys only.
+CREATE TABLE IF NOT EXISTS service_accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) UNIQUE NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- API keys belong either to a user or to a service account.
+ALTER TABLE api_keys
+ALTER COLUMN user_id DROP NOT NULL,
+ADD COLUMN service_account_id UUID REFERENCES service_accounts(id) ON DELETE CASCADE,
+ADD CONSTRAINT api_keys_owner_check CHECK ((user_id IS NULL) <> (service_account_id IS NULL));
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_service_account_id ON api_keys(service_account_id);
+
+-- Whether an audited action was taken by a person or by a service account.
+ALTER TABLE audit_events
+ADD COLUMN actor_kind VARCHAR(20) NOT NULL DEFAULT 'user';
+
+INSERT INTO permissions (name, description) VALUES
+    ('service_accounts:read', 'View service accounts and their API keys'),
+    ('service_accounts:write', 'Create, change and delete service accounts and issue their API keys')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT roles.id, permissions.name FROM roles, permissions
+WHERE roles.name = 'admin' AND permissions.name IN ('service_accounts:read', 'service_accounts:write')
+ON CONFLICT DO NOTHING;
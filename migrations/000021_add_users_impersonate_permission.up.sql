@@ -0,0 +1,8 @@
+INSERT INTO permissions (name, description) VALUES
+    ('users:impersonate', 'Act as another user with a short-lived token')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT roles.id, permissions.name FROM roles, permissions
+WHERE roles.name = 'admin' AND permissions.name = 'users:impersonate'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,22 @@
+-- Logins, one per device. A session's id is also the family_id of its refresh tokens
+-- and the sid claim of its access tokens. Rows are purged once expires_at has passed.
+CREATE TABLE IF NOT EXISTS sessions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip_address TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
+
+-- Keep existing logins: every refresh token family still in use becomes a session.
+INSERT INTO sessions (id, user_id, created_at, last_seen_at, expires_at)
+SELECT family_id, user_id, MIN(created_at), MAX(created_at), MAX(expires_at)
+FROM refresh_tokens
+WHERE revoked_at IS NULL AND expires_at > NOW()
+GROUP BY family_id, user_id
+ON CONFLICT (id) DO NOTHING;
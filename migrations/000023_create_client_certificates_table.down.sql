@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name IN ('client_certificates:read', 'client_certificates:write');
+
+DROP TABLE IF EXISTS client_certificates;
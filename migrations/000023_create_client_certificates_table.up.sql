@@ -0,0 +1,30 @@
+-- Client certificates accepted on the TLS listener. A certificate is matched by the SHA-256
+-- fingerprint of its public key (SPKI), or by its subject so that renewed certificates keep
+-- working, and authenticates as either a user or a service account.
+CREATE TABLE IF NOT EXISTS client_certificates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    service_account_id UUID REFERENCES service_accounts(id) ON DELETE CASCADE,
+    spki_sha256 TEXT UNIQUE,
+    subject TEXT UNIQUE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT client_certificates_owner_check CHECK ((user_id IS NULL) <> (service_account_id IS NULL)),
+    CONSTRAINT client_certificates_match_check CHECK ((spki_sha256 IS NULL) <> (subject IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_client_certificates_user_id ON client_certificates(user_id);
+CREATE INDEX IF NOT EXISTS idx_client_certificates_service_account_id ON client_certificates(service_account_id);
+
+INSERT INTO permissions (name, description) VALUES
+    ('client_certificates:read', 'View the client certificates registered for mutual TLS'),
+    ('client_certificates:write', 'Register and delete client certificates for mutual TLS')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT roles.id, permissions.name FROM roles, permissions
+WHERE roles.name = 'admin' AND permissions.name IN ('client_certificates:read', 'client_certificates:write')
+ON CONFLICT DO NOTHING;
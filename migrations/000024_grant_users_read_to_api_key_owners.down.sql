@@ -0,0 +1 @@
+DELETE FROM roles WHERE name = 'user-reader' AND NOT builtin;
@@ -0,0 +1,21 @@
+-- GET /api/v1/users/{id} requires the users:read permission on top of the scope.
+-- Keys and certificates that already had the scope keep working: their owners get
+-- a role granting just that permission, which administrators can take away again.
+INSERT INTO roles (name, description) VALUES
+    ('user-reader', 'View any user account; given to owners of API keys created before roles existed')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT roles.id, 'users:read' FROM roles
+WHERE roles.name = 'user-reader'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO user_roles (user_id, role_id)
+SELECT DISTINCT credentials.user_id, roles.id
+FROM (
+    SELECT user_id, scopes FROM api_keys WHERE user_id IS NOT NULL AND NOT revoked
+    UNION ALL
+    SELECT user_id, scopes FROM client_certificates WHERE user_id IS NOT NULL
+) AS credentials, roles
+WHERE roles.name = 'user-reader' AND 'users:read' = ANY(credentials.scopes)
+ON CONFLICT DO NOTHING;